@@ -184,6 +184,10 @@ type BlockChain struct {
 	notifications     []NotificationCallback
 
 	claimTrie *claimtrie.ClaimTrie
+
+	// claimLookupLock serializes the lookups of claims made under the
+	// chain read lock, as they adjust the cached nodes of the ClaimTrie.
+	claimLookupLock sync.Mutex
 }
 
 // HaveBlock returns whether or not the chain instance has the block represented
@@ -1837,19 +1841,23 @@ func New(config *Config) (*BlockChain, error) {
 
 func rebuildMissingClaimTrieData(b *BlockChain, done <-chan struct{}) error {
 	target := b.bestChain.Height()
+	if b.claimTrie.Height() > target {
+		if err := b.claimTrie.ResetHeight(target); err != nil {
+			return err
+		}
+	}
+	if err := b.verifyClaimTrieRoot(); err != nil {
+		return err
+	}
+	if err := b.syncChannelIndex(done); err != nil {
+		return err
+	}
 	if b.claimTrie.Height() == target {
 		return nil
 	}
-	if b.claimTrie.Height() > target {
-		return b.claimTrie.ResetHeight(target)
-	}
 
 	start := time.Now().Add(-6 * time.Second)
-	// TODO: move this view inside the loop (or recreate it every 5 sec.)
-	// as accumulating all inputs has potential to use a huge amount of RAM
-	// but we need to get the spent inputs working for that to be possible
-	view := NewUtxoViewpoint()
-	for h := int32(0); h < target; h++ {
+	for h := b.claimTrie.Height(); h < target; h++ {
 		select {
 		case <-done:
 			return fmt.Errorf("rebuild unfinished at height %d", b.claimTrie.Height())
@@ -1859,31 +1867,32 @@ func rebuildMissingClaimTrieData(b *BlockChain, done <-chan struct{}) error {
 		n := b.bestChain.NodeByHeight(h + 1)
 
 		var block *btcutil.Block
+		var stxos []SpentTxOut
 		err := b.db.View(func(dbTx database.Tx) error {
 			var err error
 			block, err = dbFetchBlockByNode(dbTx, n)
+			if err != nil {
+				return err
+			}
+			stxos, err = dbFetchSpendJournalEntry(dbTx, block)
 			return err
 		})
 		if err != nil {
 			return err
 		}
 
-		err = view.fetchInputUtxos(b.db, block)
+		// The spend journal holds the outputs spent by the block, so the
+		// blocks above the ClaimTrie, which may come from a snapshot, are
+		// replayed without rebuilding the UTXO set from the genesis.
+		view, err := newSpentOutputsView(block, stxos)
 		if err != nil {
 			return err
 		}
 
-		err = view.connectTransactions(block, nil)
+		err = b.ParseClaimScripts(block, n, view, true)
 		if err != nil {
 			return err
 		}
-
-		if h >= b.claimTrie.Height() {
-			err = b.ParseClaimScripts(block, n, view, true)
-			if err != nil {
-				return err
-			}
-		}
 		if time.Since(start).Seconds() > 5.0 {
 			start = time.Now()
 			log.Infof("Rebuilding claim trie data to %d. At: %d", target, h)
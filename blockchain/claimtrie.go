@@ -3,15 +3,21 @@ package blockchain
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/pkg/errors"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 
 	"github.com/btcsuite/btcd/claimtrie"
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
 	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/snapshot"
 )
 
 // Hack: print which block mismatches happened, but keep recording.
@@ -20,14 +26,8 @@ var mismatchedPrinted bool
 func (b *BlockChain) ParseClaimScripts(block *btcutil.Block, node *blockNode, view *UtxoViewpoint, failOnHashMiss bool) error {
 	ht := block.Height()
 
-	for _, tx := range block.Transactions() {
-		h := handler{ht, tx, view, map[string][]byte{}}
-		if err := h.handleTxIns(b.claimTrie); err != nil {
-			return err
-		}
-		if err := h.handleTxOuts(b.claimTrie); err != nil {
-			return err
-		}
+	if err := applyClaimScripts(b.claimTrie, block, ht, view); err != nil {
+		return err
 	}
 
 	// Hack: let the claimtrie know the expected Hash.
@@ -51,6 +51,286 @@ func (b *BlockChain) ParseClaimScripts(block *btcutil.Block, node *blockNode, vi
 	return nil
 }
 
+// ClaimTrieRootForBlock returns the root hash the ClaimTrie would have after
+// connecting the block, which must extend the current chain tip.  The claim
+// operations of the block are applied to a scratch copy of the ClaimTrie, which
+// is left unchanged.
+//
+// This is intended for building block templates.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ClaimTrieRootForBlock(block *btcutil.Block) (*chainhash.Hash, error) {
+	// The chain lock keeps the ClaimTrie from being updated while the scratch
+	// copy shares its merkle trie.
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if b.claimTrie == nil {
+		return nil, AssertError("ClaimTrieRootForBlock called " +
+			"without a ClaimTrie")
+	}
+
+	tip := b.bestChain.Tip()
+	if tip.hash != block.MsgBlock().Header.PrevBlock {
+		str := fmt.Sprintf("previous block must be the current chain tip %v, "+
+			"instead got %v", tip.hash, block.MsgBlock().Header.PrevBlock)
+		return nil, ruleError(ErrPrevBlockNotBest, str)
+	}
+
+	ct := b.claimTrie
+	if ct.Height() != tip.height {
+		return nil, AssertError(fmt.Sprintf("ClaimTrie height %d does not "+
+			"match the chain tip height %d", ct.Height(), tip.height))
+	}
+
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&tip.hash)
+	if err := view.fetchInputUtxos(b.db, block); err != nil {
+		return nil, err
+	}
+
+	scratch, err := ct.Scratch()
+	if err != nil {
+		return nil, err
+	}
+	defer scratch.Close()
+
+	if err := applyClaimScripts(scratch, block, tip.height+1, view); err != nil {
+		return nil, err
+	}
+	if err := scratch.AppendBlock(); err != nil {
+		return nil, err
+	}
+
+	return scratch.MerkleHash(), nil
+}
+
+// ClaimTrieView returns a read-only view of the ClaimTrie at the current chain
+// tip, which the caller must close.  The view stays consistent while blocks are
+// connected and disconnected, so it can be queried without holding the chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ClaimTrieView() (*claimtrie.View, error) {
+	view, _, err := b.claimTrieView("ClaimTrieView")
+	return view, err
+}
+
+// ClaimExists returns whether the claim with the passed ID exists in the
+// ClaimTrie at the current chain tip under the passed name, which is normalized
+// if necessary.  The claim is looked up in the cached node of the name, so the
+// history of the name is only replayed when it isn't cached.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ClaimExists(name []byte, claimID node.ClaimID) (bool, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if b.claimTrie == nil {
+		return false, AssertError("ClaimExists called without a ClaimTrie")
+	}
+
+	b.claimLookupLock.Lock()
+	defer b.claimLookupLock.Unlock()
+
+	name = node.NormalizeIfNecessary(name, b.claimTrie.Height())
+	n, err := b.claimTrie.Node(name)
+	if err != nil || n == nil {
+		return false, err
+	}
+
+	id := claimID.String()
+	for _, c := range n.Claims {
+		if c.ClaimID == id {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// claimTrieView returns a view of the ClaimTrie at the current chain tip, along
+// with the hash of the tip.  The caller is named in the assertions.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) claimTrieView(caller string) (*claimtrie.View, *chainhash.Hash, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	if b.claimTrie == nil {
+		return nil, nil, AssertError(caller + " called without a ClaimTrie")
+	}
+
+	view, err := b.claimTrie.View()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tip := b.bestChain.Tip()
+	if view.Height() != tip.height {
+		view.Close()
+		return nil, nil, AssertError(fmt.Sprintf("ClaimTrie height %d "+
+			"does not match the chain tip height %d", view.Height(),
+			tip.height))
+	}
+
+	return view, &tip.hash, nil
+}
+
+// ExportClaimTrie writes a snapshot of the ClaimTrie at the current chain tip
+// to w, and returns its header along with the hash of the tip.  The snapshot is
+// written from a view of the ClaimTrie, so blocks keep being connected meanwhile.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ExportClaimTrie(w io.Writer) (*snapshot.Header, *chainhash.Hash, error) {
+	view, hash, err := b.claimTrieView("ExportClaimTrie")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer view.Close()
+
+	hdr, err := view.ExportSnapshot(w)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return hdr, hash, nil
+}
+
+// verifyClaimTrieRoot ensures the root of the ClaimTrie matches the ClaimTrie
+// hash committed to by the main chain block at its height, which rejects an
+// imported snapshot, or a data dir, that doesn't belong to the chain.
+func (b *BlockChain) verifyClaimTrieRoot() error {
+	height := b.claimTrie.Height()
+	if height == 0 {
+		return nil
+	}
+
+	n := b.bestChain.NodeByHeight(height)
+	root := b.claimTrie.MerkleHash()
+	if n == nil || *root != n.claimTrie {
+		return fmt.Errorf("ClaimTrie root %v at height %d does not "+
+			"match the block of the main chain", root, height)
+	}
+
+	return nil
+}
+
+// newSpentOutputsView returns a view which contains the outputs spent by the
+// block, as recorded in its spend journal entry.  It is enough to apply the
+// claim scripts of the block.
+func newSpentOutputsView(block *btcutil.Block, stxos []SpentTxOut) (*UtxoViewpoint, error) {
+	if len(stxos) != countSpentOutputs(block) {
+		return nil, AssertError(fmt.Sprintf("spend journal of block %v "+
+			"has %d entries instead of %d", block.Hash(), len(stxos),
+			countSpentOutputs(block)))
+	}
+
+	view := NewUtxoViewpoint()
+	stxoIdx := 0
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			stxo := &stxos[stxoIdx]
+			stxoIdx++
+
+			var packedFlags txoFlags
+			if stxo.IsCoinBase {
+				packedFlags |= tfCoinBase
+			}
+			view.entries[txIn.PreviousOutPoint] = &UtxoEntry{
+				amount:      stxo.Amount,
+				pkScript:    stxo.PkScript,
+				blockHeight: stxo.Height,
+				packedFlags: packedFlags,
+			}
+		}
+	}
+	view.SetBestHash(block.Hash())
+
+	return view, nil
+}
+
+// applyClaimScripts forwards the claim operations of the transactions in the
+// block at height ht to the ClaimTrie.  The view must contain the outputs
+// spent by the block.
+func applyClaimScripts(ct *claimtrie.ClaimTrie, block *btcutil.Block, ht int32, view *UtxoViewpoint) error {
+	for _, tx := range block.Transactions() {
+		h := handler{ht, tx, view, map[string][]byte{}}
+		if err := h.handleTxIns(ct); err != nil {
+			return err
+		}
+		if err := h.handleTxOuts(ct); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckClaimUpdates ensures every OP_UPDATECLAIM output of the transaction
+// updates a claim of the same name which is spent by the transaction, and that
+// no claim is updated twice.  The ClaimTrie silently ignores any other update,
+// which leaves the claim spent.
+//
+// The view must contain the outputs spent by the transaction, and height is
+// the height of the block the transaction is, or would be, included in.
+func CheckClaimUpdates(tx *btcutil.Tx, view *UtxoViewpoint, height int32) error {
+	// Names are normalized at the height of the ClaimTrie while the block
+	// is being connected, which is that of its parent.
+	normHeight := height - 1
+
+	spent := map[node.ClaimID][]byte{}
+	if !IsCoinBase(tx) {
+		for _, txIn := range tx.MsgTx().TxIn {
+			op := txIn.PreviousOutPoint
+			entry := view.LookupEntry(op)
+			if entry == nil {
+				continue
+			}
+			cs, err := txscript.DecodeClaimScript(entry.PkScript())
+			if err != nil {
+				continue
+			}
+
+			var id node.ClaimID
+			switch cs.Opcode() {
+			case txscript.OP_CLAIMNAME:
+				id = node.NewClaimID(op)
+			case txscript.OP_UPDATECLAIM:
+				copy(id[:], cs.ClaimID())
+			default:
+				continue
+			}
+			spent[id] = node.NormalizeIfNecessary(cs.Name(), normHeight)
+		}
+	}
+
+	for i, txOut := range tx.MsgTx().TxOut {
+		cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+		if err != nil || cs.Opcode() != txscript.OP_UPDATECLAIM {
+			continue
+		}
+
+		var id node.ClaimID
+		copy(id[:], cs.ClaimID())
+		name, ok := spent[id]
+		if !ok {
+			str := fmt.Sprintf("output %d of transaction %v updates "+
+				"claim %v which the transaction does not spend, "+
+				"or updates it twice", i, tx.Hash(), id)
+			return ruleError(ErrInvalidClaimUpdate, str)
+		}
+		normName := node.NormalizeIfNecessary(cs.Name(), normHeight)
+		if !bytes.Equal(name, normName) {
+			str := fmt.Sprintf("output %d of transaction %v updates "+
+				"claim %v of name %q under name %q", i, tx.Hash(),
+				id, name, normName)
+			return ruleError(ErrInvalidClaimUpdate, str)
+		}
+		delete(spent, id)
+	}
+
+	return nil
+}
+
 type handler struct {
 	ht    int32
 	tx    *btcutil.Tx
@@ -58,6 +338,12 @@ type handler struct {
 	spent map[string][]byte
 }
 
+// firstInput returns the outpoint spent by the first input of the transaction,
+// which is covered by the channel signatures of its claims.
+func (h *handler) firstInput() wire.OutPoint {
+	return h.tx.MsgTx().TxIn[0].PreviousOutPoint
+}
+
 func (h *handler) handleTxIns(ct *claimtrie.ClaimTrie) error {
 	if IsCoinBase(h.tx) {
 		return nil
@@ -119,22 +405,25 @@ func (h *handler) handleTxOuts(ct *claimtrie.ClaimTrie) error {
 		case txscript.OP_CLAIMNAME:
 			id = node.NewClaimID(*op)
 			err = ct.AddClaim(name, *op, id, amt, value)
+			ct.IndexChannelClaim(h.ht, name, *op, id, value, h.firstInput())
 		case txscript.OP_SUPPORTCLAIM:
 			copy(id[:], cs.ClaimID())
 			err = ct.AddSupport(name, value, *op, amt, id)
 		case txscript.OP_UPDATECLAIM:
 			// old code wouldn't run the update if name or claimID didn't match existing data
-			// that was a safety feature, but it should have rejected the transaction instead
-			// TODO: reject transactions with invalid update commands
+			// that was a safety feature, but it should have rejected the transaction instead.
+			// Such transactions are rejected by the mempool, and by CheckClaimUpdates once
+			// the DeploymentValidClaimUpdates soft-fork is active.
 			copy(id[:], cs.ClaimID())
 			normName := node.NormalizeIfNecessary(name, ct.Height())
 			if !bytes.Equal(h.spent[id.String()], normName) {
-				fmt.Printf("Invalid update operation: name or ID mismatch for %s, %s\n", normName, id.String())
+				ct.RecordAnomaly(anomaly.Anomaly{Type: anomaly.InvalidUpdate, Name: normName, ClaimID: id.String(), OutPoint: op.String()})
 				continue
 			}
 
 			delete(h.spent, id.String())
 			err = ct.UpdateClaim(name, *op, amt, id, value)
+			ct.IndexChannelClaim(h.ht, name, *op, id, value, h.firstInput())
 		}
 		if err != nil {
 			return errors.Wrapf(err, "handleTxOuts")
@@ -142,3 +431,80 @@ func (h *handler) handleTxOuts(ct *claimtrie.ClaimTrie) error {
 	}
 	return nil
 }
+
+// indexChannelClaims records the claims added or updated by the block at
+// height ht in the channel index of the ClaimTrie.
+func indexChannelClaims(ct *claimtrie.ClaimTrie, block *btcutil.Block, ht int32) {
+	for _, tx := range block.Transactions() {
+		h := handler{ht: ht, tx: tx}
+		for i, txOut := range tx.MsgTx().TxOut {
+			cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+			if err != nil {
+				continue
+			}
+
+			op := wire.NewOutPoint(tx.Hash(), uint32(i))
+			var id node.ClaimID
+			switch cs.Opcode() {
+			case txscript.OP_CLAIMNAME:
+				id = node.NewClaimID(*op)
+			case txscript.OP_UPDATECLAIM:
+				copy(id[:], cs.ClaimID())
+			default:
+				continue
+			}
+			ct.IndexChannelClaim(ht, cs.Name(), *op, id, cs.Value(), h.firstInput())
+		}
+	}
+}
+
+// syncChannelIndex catches the channel index up to the height of the ClaimTrie
+// from the blocks of the main chain, as it records the first input of the claim
+// transactions, which the ClaimTrie doesn't keep.  The updates which weren't
+// applied are indexed as well, which is harmless as the queries only consider
+// the claims held by the ClaimTrie.
+func (b *BlockChain) syncChannelIndex(done <-chan struct{}) error {
+	ct := b.claimTrie
+	indexHeight, err := ct.ChannelIndexHeight()
+	if err == claimtrie.ErrChannelIndexDisabled {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	target := ct.Height()
+	if indexHeight >= target {
+		return nil
+	}
+
+	log.Infof("Catching up channel index from %d to %d", indexHeight, target)
+	start := time.Now()
+	for h := indexHeight + 1; h <= target; h++ {
+		select {
+		case <-done:
+			return fmt.Errorf("channel index catch up unfinished at height %d", h-1)
+		default:
+		}
+
+		var block *btcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, b.bestChain.NodeByHeight(h))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		indexChannelClaims(ct, block, h)
+		if err := ct.CommitChannelIndex(h); err != nil {
+			return err
+		}
+		if time.Since(start).Seconds() > 5.0 {
+			start = time.Now()
+			log.Infof("Catching up channel index to %d. At: %d", target, h)
+		}
+	}
+	log.Infof("Completed catching up channel index to %d", target)
+	return nil
+}
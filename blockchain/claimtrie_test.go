@@ -0,0 +1,318 @@
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie"
+	"github.com/btcsuite/btcd/claimtrie/config"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/param"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// TestClaimTrieRootForBlock ensures the ClaimTrie root of a block template
+// reflects its claims while leaving the ClaimTrie itself untouched.
+func TestClaimTrieRootForBlock(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	chain, teardown, err := chainSetup("claimtrieroot", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardown()
+
+	param.SetNetwork(params.Net)
+	cfg := config.DefaultConfig
+	cfg.DataDir = t.TempDir()
+	ct, err := claimtrie.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create ClaimTrie: %v", err)
+	}
+	defer ct.Close()
+	chain.claimTrie = ct
+
+	script, err := txscript.ClaimNameScript("test", "value")
+	if err != nil {
+		t.Fatalf("Failed to build claim script: %v", err)
+	}
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&zeroHash,
+		wire.MaxPrevOutIndex), []byte{0x51, 0x51}, nil))
+	coinbase.AddTxOut(wire.NewTxOut(1, script))
+
+	var msgBlock wire.MsgBlock
+	msgBlock.Header.PrevBlock = *params.GenesisHash
+	msgBlock.AddTransaction(coinbase)
+	block := btcutil.NewBlock(&msgBlock)
+
+	before := *ct.MerkleHash()
+	root, err := chain.ClaimTrieRootForBlock(block)
+	if err != nil {
+		t.Fatalf("ClaimTrieRootForBlock: %v", err)
+	}
+	if *root == before {
+		t.Fatalf("ClaimTrie root %v does not reflect the claim", root)
+	}
+	if ct.Height() != 0 {
+		t.Fatalf("ClaimTrie height: got %d, want 0", ct.Height())
+	}
+	if got := *ct.MerkleHash(); got != before {
+		t.Fatalf("ClaimTrie root was not restored: got %v, want %v",
+			got, before)
+	}
+	n, err := ct.Node([]byte("test"))
+	if err != nil {
+		t.Fatalf("Failed to load node: %v", err)
+	}
+	if n != nil && len(n.Claims) > 0 {
+		t.Fatalf("Claim was not rolled back")
+	}
+
+	// The same root must be computed again.
+	again, err := chain.ClaimTrieRootForBlock(block)
+	if err != nil {
+		t.Fatalf("ClaimTrieRootForBlock: %v", err)
+	}
+	if *again != *root {
+		t.Fatalf("ClaimTrie root mismatch: got %v, want %v", again, root)
+	}
+
+	// Blocks not extending the tip are rejected.
+	msgBlock.Header.PrevBlock = zeroHash
+	_, err = chain.ClaimTrieRootForBlock(btcutil.NewBlock(&msgBlock))
+	if rerr, ok := err.(RuleError); !ok || rerr.ErrorCode != ErrPrevBlockNotBest {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// A view of the tip is left as it was by the speculative blocks.
+	view, err := chain.ClaimTrieView()
+	if err != nil {
+		t.Fatalf("ClaimTrieView: %v", err)
+	}
+	defer view.Close()
+	msgBlock.Header.PrevBlock = *params.GenesisHash
+	if _, err := chain.ClaimTrieRootForBlock(block); err != nil {
+		t.Fatalf("ClaimTrieRootForBlock: %v", err)
+	}
+	if view.Height() != 0 {
+		t.Fatalf("ClaimTrie view height: got %d, want 0", view.Height())
+	}
+	hash, err := view.MerkleHash()
+	if err != nil {
+		t.Fatalf("ClaimTrie view root: %v", err)
+	}
+	if *hash != before {
+		t.Fatalf("ClaimTrie view root: got %v, want %v", hash, before)
+	}
+}
+
+// TestCheckClaimUpdates ensures claim updates are only accepted when they
+// update a claim of the same name spent by the same transaction.
+func TestCheckClaimUpdates(t *testing.T) {
+	param.SetNetwork(chaincfg.RegressionNetParams.Net)
+
+	claimScript, err := txscript.ClaimNameScript("test", "value")
+	if err != nil {
+		t.Fatalf("Failed to build claim script: %v", err)
+	}
+	prevTx := wire.NewMsgTx(1)
+	prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{}, nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(1, claimScript))
+	prev := btcutil.NewTx(prevTx)
+	claimOp := wire.OutPoint{Hash: *prev.Hash(), Index: 0}
+	id := node.NewClaimID(claimOp)
+
+	view := NewUtxoViewpoint()
+	view.AddTxOuts(prev, 1)
+
+	updateScript := func(name string, id node.ClaimID) []byte {
+		script, err := txscript.UpdateClaimScript(name, id[:], "value")
+		if err != nil {
+			t.Fatalf("Failed to build update script: %v", err)
+		}
+		return script
+	}
+	spendTx := func(op wire.OutPoint, scripts ...[]byte) *btcutil.Tx {
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(&op, nil, nil))
+		for _, script := range scripts {
+			tx.AddTxOut(wire.NewTxOut(1, script))
+		}
+		return btcutil.NewTx(tx)
+	}
+
+	tests := []struct {
+		name  string
+		tx    *btcutil.Tx
+		valid bool
+	}{
+		{
+			name:  "update of spent claim",
+			tx:    spendTx(claimOp, updateScript("test", id)),
+			valid: true,
+		},
+		{
+			name:  "spend without update",
+			tx:    spendTx(claimOp, []byte{txscript.OP_TRUE}),
+			valid: true,
+		},
+		{
+			name:  "update of unspent claim",
+			tx:    spendTx(wire.OutPoint{Index: 1}, updateScript("test", id)),
+			valid: false,
+		},
+		{
+			name:  "update under another name",
+			tx:    spendTx(claimOp, updateScript("other", id)),
+			valid: false,
+		},
+		{
+			name: "update of another claim",
+			tx: spendTx(claimOp, updateScript("test",
+				node.ClaimID{1})),
+			valid: false,
+		},
+		{
+			name: "double update",
+			tx: spendTx(claimOp, updateScript("test", id),
+				updateScript("test", id)),
+			valid: false,
+		},
+	}
+
+	for _, test := range tests {
+		err := CheckClaimUpdates(test.tx, view, 2)
+		if test.valid {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		rerr, ok := err.(RuleError)
+		if !ok || rerr.ErrorCode != ErrInvalidClaimUpdate {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+	}
+
+	// Updates of updated claims spend the previous update.
+	update := tests[0].tx
+	view.AddTxOuts(update, 2)
+	updateOp := wire.OutPoint{Hash: *update.Hash(), Index: 0}
+	err = CheckClaimUpdates(spendTx(updateOp, updateScript("test", id)), view, 3)
+	if err != nil {
+		t.Errorf("update of updated claim: unexpected error: %v", err)
+	}
+}
+
+// TestNewSpentOutputsView ensures the view built from a spend journal entry
+// holds the outputs spent by the block.
+func TestNewSpentOutputsView(t *testing.T) {
+	claimScript, err := txscript.ClaimNameScript("test", "value")
+	if err != nil {
+		t.Fatalf("Failed to build claim script: %v", err)
+	}
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&zeroHash,
+		wire.MaxPrevOutIndex), []byte{0x51, 0x51}, nil))
+	coinbase.AddTxOut(wire.NewTxOut(1, nil))
+
+	ops := []wire.OutPoint{{Hash: chainhash.Hash{1}}, {Hash: chainhash.Hash{2}, Index: 3}}
+	spend := wire.NewMsgTx(1)
+	for i := range ops {
+		spend.AddTxIn(wire.NewTxIn(&ops[i], nil, nil))
+	}
+	spend.AddTxOut(wire.NewTxOut(1, nil))
+
+	var msgBlock wire.MsgBlock
+	msgBlock.AddTransaction(coinbase)
+	msgBlock.AddTransaction(spend)
+	block := btcutil.NewBlock(&msgBlock)
+
+	stxos := []SpentTxOut{
+		{Amount: 5, PkScript: claimScript, Height: 7},
+		{Amount: 6, PkScript: []byte{txscript.OP_TRUE}, Height: 8, IsCoinBase: true},
+	}
+	view, err := newSpentOutputsView(block, stxos)
+	if err != nil {
+		t.Fatalf("newSpentOutputsView: %v", err)
+	}
+	for i, op := range ops {
+		entry := view.LookupEntry(op)
+		if entry == nil {
+			t.Fatalf("Missing entry for %v", op)
+		}
+		stxo := stxos[i]
+		if entry.Amount() != stxo.Amount || entry.BlockHeight() != stxo.Height ||
+			entry.IsCoinBase() != stxo.IsCoinBase ||
+			!bytes.Equal(entry.PkScript(), stxo.PkScript) {
+			t.Fatalf("Entry for %v does not match the spent output", op)
+		}
+	}
+
+	// The number of entries must match the number of inputs.
+	if _, err := newSpentOutputsView(block, stxos[:1]); err == nil {
+		t.Fatalf("newSpentOutputsView accepted a short spend journal")
+	}
+}
+
+// TestClaimExists ensures the claims of the ClaimTrie are found under their
+// names only.
+func TestClaimExists(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	chain, teardown, err := chainSetup("claimexists", params)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardown()
+
+	if _, err := chain.ClaimExists([]byte("test"), node.ClaimID{}); err == nil {
+		t.Fatalf("ClaimExists without a ClaimTrie did not fail")
+	}
+
+	param.SetNetwork(params.Net)
+	cfg := config.DefaultConfig
+	cfg.DataDir = t.TempDir()
+	ct, err := claimtrie.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create ClaimTrie: %v", err)
+	}
+	defer ct.Close()
+	chain.claimTrie = ct
+
+	op := wire.OutPoint{Hash: chainhash.Hash{1}, Index: 0}
+	id := node.NewClaimID(op)
+	if err := ct.AddClaim([]byte("test"), op, id, 10, nil); err != nil {
+		t.Fatalf("AddClaim: %v", err)
+	}
+	if err := ct.AppendBlock(); err != nil {
+		t.Fatalf("AppendBlock: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		id     node.ClaimID
+		exists bool
+	}{
+		{name: "test", id: id, exists: true},
+		{name: "test", id: node.ClaimID{1}, exists: false},
+		{name: "other", id: id, exists: false},
+	}
+	for _, test := range tests {
+		// The second lookup is served by the cache.
+		for i := 0; i < 2; i++ {
+			exists, err := chain.ClaimExists([]byte(test.name), test.id)
+			if err != nil {
+				t.Fatalf("ClaimExists(%s, %v): %v", test.name, test.id, err)
+			}
+			if exists != test.exists {
+				t.Errorf("ClaimExists(%s, %v): got %v, want %v",
+					test.name, test.id, exists, test.exists)
+			}
+		}
+	}
+}
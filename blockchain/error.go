@@ -224,6 +224,17 @@ const (
 	// ErrBadClaimTrie indicates the calculated ClaimTrie root does not match
 	// the expected value.
 	ErrBadClaimTrie
+
+	// ErrInvalidClaimUpdate indicates a transaction has an OP_UPDATECLAIM
+	// output which does not update a claim of the same name spent by the
+	// transaction.
+	ErrInvalidClaimUpdate
+
+	// ErrUnknownClaimSupport indicates a transaction has an OP_SUPPORTCLAIM
+	// output which supports a claim that neither exists in the ClaimTrie nor
+	// is created by a transaction in the memory pool.  This is not a block
+	// validation rule, but a policy of the memory pool.
+	ErrUnknownClaimSupport
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
@@ -272,6 +283,8 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrInvalidAncestorBlock:      "ErrInvalidAncestorBlock",
 	ErrPrevBlockNotBest:          "ErrPrevBlockNotBest",
 	ErrBadClaimTrie:              "ErrBadClaimTrie",
+	ErrInvalidClaimUpdate:        "ErrInvalidClaimUpdate",
+	ErrUnknownClaimSupport:       "ErrUnknownClaimSupport",
 }
 
 // String returns the ErrorCode as a human-readable name.
@@ -9,7 +9,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 )
 
 // addrIndexBucket provides a mock address index database bucket by implementing
@@ -274,3 +277,37 @@ nextTest:
 		}
 	}
 }
+
+// TestIndexClaimPkScript ensures the outputs of claims, supports and updates
+// are indexed by the address they pay to.
+func TestIndexClaimPkScript(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.MainNetParams
+	addr, err := btcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("NewAddressPubKeyHash: unexpected error: %v", err)
+	}
+	payee, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("PayToAddrScript: unexpected error: %v", err)
+	}
+	prefix, err := txscript.NewScriptBuilder().AddOp(txscript.OP_CLAIMNAME).
+		AddData([]byte("name")).AddData([]byte("value")).
+		AddOp(txscript.OP_2DROP).AddOp(txscript.OP_DROP).Script()
+	if err != nil {
+		t.Fatalf("Script: unexpected error: %v", err)
+	}
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		t.Fatalf("addrToKey: unexpected error: %v", err)
+	}
+
+	idx := &AddrIndex{chainParams: params}
+	data := make(writeIndexData)
+	idx.indexPkScript(data, append(prefix, payee...), 1)
+	if txIdxs := data[addrKey]; len(txIdxs) != 1 || txIdxs[0] != 1 {
+		t.Fatalf("indexPkScript: unexpected index data for %s: %v",
+			addr, txIdxs)
+	}
+}
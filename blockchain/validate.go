@@ -1248,6 +1248,22 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *btcutil.Block, vi
 		}
 	}
 
+	// Enforce that claim updates only update claims spent by the same
+	// transaction once the soft-fork deployment is fully active.
+	claimUpdatesState, err := b.deploymentState(node.parent,
+		chaincfg.DeploymentValidClaimUpdates)
+	if err != nil {
+		return err
+	}
+	if claimUpdatesState == ThresholdActive {
+		for _, tx := range block.Transactions() {
+			err := CheckClaimUpdates(tx, view, node.height)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	// Enforce the segwit soft-fork package once the soft-fork has shifted
 	// into the "active" version bits state.
 	if enforceSegWit {
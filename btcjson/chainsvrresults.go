@@ -280,6 +280,9 @@ type GetBlockTemplateResult struct {
 	// Witness commitment defined in BIP 0141.
 	DefaultWitnessCommitment string `json:"default_witness_commitment,omitempty"`
 
+	// Root hash of the ClaimTrie the block must commit to (LBRY).
+	ClaimTrie string `json:"claimtrie,omitempty"`
+
 	// Optional long polling from BIP 0022.
 	LongPollID  string `json:"longpollid,omitempty"`
 	LongPollURI string `json:"longpolluri,omitempty"`
@@ -519,12 +522,13 @@ type ScriptSig struct {
 // getrawtransaction, decoderawtransaction, and searchrawtransaction use the
 // same structure.
 type Vin struct {
-	Coinbase  string     `json:"coinbase"`
-	Txid      string     `json:"txid"`
-	Vout      uint32     `json:"vout"`
-	ScriptSig *ScriptSig `json:"scriptSig"`
-	Sequence  uint32     `json:"sequence"`
-	Witness   []string   `json:"txinwitness"`
+	Coinbase  string             `json:"coinbase"`
+	Txid      string             `json:"txid"`
+	Vout      uint32             `json:"vout"`
+	ScriptSig *ScriptSig         `json:"scriptSig"`
+	Sequence  uint32             `json:"sequence"`
+	Witness   []string           `json:"txinwitness"`
+	Claim     *ClaimScriptResult `json:"claim,omitempty"`
 }
 
 // IsCoinBase returns a bool to show if a Vin is a Coinbase one or not.
@@ -555,31 +559,35 @@ func (v *Vin) MarshalJSON() ([]byte, error) {
 
 	if v.HasWitness() {
 		txStruct := struct {
-			Txid      string     `json:"txid"`
-			Vout      uint32     `json:"vout"`
-			ScriptSig *ScriptSig `json:"scriptSig"`
-			Witness   []string   `json:"txinwitness"`
-			Sequence  uint32     `json:"sequence"`
+			Txid      string             `json:"txid"`
+			Vout      uint32             `json:"vout"`
+			ScriptSig *ScriptSig         `json:"scriptSig"`
+			Witness   []string           `json:"txinwitness"`
+			Sequence  uint32             `json:"sequence"`
+			Claim     *ClaimScriptResult `json:"claim,omitempty"`
 		}{
 			Txid:      v.Txid,
 			Vout:      v.Vout,
 			ScriptSig: v.ScriptSig,
 			Witness:   v.Witness,
 			Sequence:  v.Sequence,
+			Claim:     v.Claim,
 		}
 		return json.Marshal(txStruct)
 	}
 
 	txStruct := struct {
-		Txid      string     `json:"txid"`
-		Vout      uint32     `json:"vout"`
-		ScriptSig *ScriptSig `json:"scriptSig"`
-		Sequence  uint32     `json:"sequence"`
+		Txid      string             `json:"txid"`
+		Vout      uint32             `json:"vout"`
+		ScriptSig *ScriptSig         `json:"scriptSig"`
+		Sequence  uint32             `json:"sequence"`
+		Claim     *ClaimScriptResult `json:"claim,omitempty"`
 	}{
 		Txid:      v.Txid,
 		Vout:      v.Vout,
 		ScriptSig: v.ScriptSig,
 		Sequence:  v.Sequence,
+		Claim:     v.Claim,
 	}
 	return json.Marshal(txStruct)
 }
@@ -666,6 +674,7 @@ type Vout struct {
 	Value        float64            `json:"value"`
 	N            uint32             `json:"n"`
 	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+	Claim        *ClaimScriptResult `json:"claim,omitempty"`
 }
 
 // GetMiningInfoResult models the data from the getmininginfo command.
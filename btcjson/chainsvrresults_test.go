@@ -47,6 +47,25 @@ func TestChainSvrCustomResults(t *testing.T) {
 			},
 			expected: `{"txid":"123","vout":1,"scriptSig":{"asm":"0","hex":"00"},"sequence":4294967295}`,
 		},
+		{
+			name: "custom vin marshal spending a claim",
+			result: &btcjson.Vin{
+				Txid: "123",
+				Vout: 1,
+				ScriptSig: &btcjson.ScriptSig{
+					Asm: "0",
+					Hex: "00",
+				},
+				Sequence: 4294967295,
+				Claim: &btcjson.ClaimScriptResult{
+					Operation:      "supportclaim",
+					Name:           "Name",
+					NormalizedName: "name",
+					ClaimID:        "456",
+				},
+			},
+			expected: `{"txid":"123","vout":1,"scriptSig":{"asm":"0","hex":"00"},"sequence":4294967295,"claim":{"operation":"supportclaim","name":"Name","normalizedname":"name","claimid":"456"}}`,
+		},
 		{
 			name: "custom vinprevout marshal with coinbase",
 			result: &btcjson.VinPrevOut{
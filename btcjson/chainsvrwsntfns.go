@@ -75,6 +75,13 @@ const (
 	// from the chain server that inform a client that a transaction that
 	// matches the loaded filter was accepted by the mempool.
 	RelevantTxAcceptedNtfnMethod = "relevanttxaccepted"
+
+	// NameTakeoverNtfnMethod is the method used for notifications from the
+	// chain server that the claim controlling a name has changed in a
+	// connected block.
+	//
+	// NOTE: This is a LBRY extension.
+	NameTakeoverNtfnMethod = "nametakeover"
 )
 
 // BlockConnectedNtfn defines the blockconnected JSON-RPC notification.
@@ -285,6 +292,23 @@ func NewRelevantTxAcceptedNtfn(txHex string) *RelevantTxAcceptedNtfn {
 	return &RelevantTxAcceptedNtfn{Transaction: txHex}
 }
 
+// NameTakeoverNtfn defines the nametakeover JSON-RPC notification.
+//
+// NOTE: This is a LBRY extension.
+type NameTakeoverNtfn struct {
+	BlockHash string
+	Takeover  NameTakeoverResult
+}
+
+// NewNameTakeoverNtfn returns a new instance which can be used to issue a
+// nametakeover JSON-RPC notification.
+func NewNameTakeoverNtfn(blockHash string, takeover NameTakeoverResult) *NameTakeoverNtfn {
+	return &NameTakeoverNtfn{
+		BlockHash: blockHash,
+		Takeover:  takeover,
+	}
+}
+
 func init() {
 	// The commands in this file are only usable by websockets and are
 	// notifications.
@@ -301,4 +325,5 @@ func init() {
 	MustRegisterCmd(TxAcceptedNtfnMethod, (*TxAcceptedNtfn)(nil), flags)
 	MustRegisterCmd(TxAcceptedVerboseNtfnMethod, (*TxAcceptedVerboseNtfn)(nil), flags)
 	MustRegisterCmd(RelevantTxAcceptedNtfnMethod, (*RelevantTxAcceptedNtfn)(nil), flags)
+	MustRegisterCmd(NameTakeoverNtfnMethod, (*NameTakeoverNtfn)(nil), flags)
 }
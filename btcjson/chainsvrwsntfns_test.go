@@ -225,6 +225,35 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Transaction: "001122",
 			},
 		},
+		{
+			name: "nametakeover",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("nametakeover", "123", `{"height":67,"name":"one","oldclaimid":"01","newclaimid":"02","oldeffectiveamount":10,"neweffectiveamount":20}`)
+			},
+			staticNtfn: func() interface{} {
+				takeover := btcjson.NameTakeoverResult{
+					Height:             67,
+					Name:               "one",
+					OldClaimID:         "01",
+					NewClaimID:         "02",
+					OldEffectiveAmount: 10,
+					NewEffectiveAmount: 20,
+				}
+				return btcjson.NewNameTakeoverNtfn("123", takeover)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"nametakeover","params":["123",{"height":67,"name":"one","oldclaimid":"01","newclaimid":"02","oldeffectiveamount":10,"neweffectiveamount":20}],"id":null}`,
+			unmarshalled: &btcjson.NameTakeoverNtfn{
+				BlockHash: "123",
+				Takeover: btcjson.NameTakeoverResult{
+					Height:             67,
+					Name:               "one",
+					OldClaimID:         "01",
+					NewClaimID:         "02",
+					OldEffectiveAmount: 10,
+					NewEffectiveAmount: 20,
+				},
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
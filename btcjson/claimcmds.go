@@ -0,0 +1,236 @@
+// Copyright (c) 2021 The LBRY developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// NOTE: This file is intended to house the RPC commands that are supported by
+// a chain server with LBRY ClaimTrie extensions.
+
+package btcjson
+
+// GetClaimsForNameCmd defines the getclaimsforname JSON-RPC command.
+type GetClaimsForNameCmd struct {
+	Name      string
+	BlockHash *string
+	Height    *int32
+}
+
+// NewGetClaimsForNameCmd returns a new instance which can be used to issue a
+// getclaimsforname JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetClaimsForNameCmd(name string, blockHash *string, height *int32) *GetClaimsForNameCmd {
+	return &GetClaimsForNameCmd{
+		Name:      name,
+		BlockHash: blockHash,
+		Height:    height,
+	}
+}
+
+// GetNameProofCmd defines the getnameproof JSON-RPC command.
+type GetNameProofCmd struct {
+	Name      string
+	BlockHash *string
+	Height    *int32
+}
+
+// NewGetNameProofCmd returns a new instance which can be used to issue a
+// getnameproof JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetNameProofCmd(name string, blockHash *string, height *int32) *GetNameProofCmd {
+	return &GetNameProofCmd{
+		Name:      name,
+		BlockHash: blockHash,
+		Height:    height,
+	}
+}
+
+// GetClaimByIDCmd defines the getclaimbyid JSON-RPC command.
+type GetClaimByIDCmd struct {
+	ClaimID   string
+	BlockHash *string
+	Height    *int32
+}
+
+// NewGetClaimByIDCmd returns a new instance which can be used to issue a
+// getclaimbyid JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetClaimByIDCmd(claimID string, blockHash *string, height *int32) *GetClaimByIDCmd {
+	return &GetClaimByIDCmd{
+		ClaimID:   claimID,
+		BlockHash: blockHash,
+		Height:    height,
+	}
+}
+
+// GetClaimHistoryCmd defines the getclaimhistory JSON-RPC command.
+type GetClaimHistoryCmd struct {
+	ClaimID string
+}
+
+// NewGetClaimHistoryCmd returns a new instance which can be used to issue a
+// getclaimhistory JSON-RPC command.
+func NewGetClaimHistoryCmd(claimID string) *GetClaimHistoryCmd {
+	return &GetClaimHistoryCmd{
+		ClaimID: claimID,
+	}
+}
+
+// GetNameHistoryCmd defines the getnamehistory JSON-RPC command.
+type GetNameHistoryCmd struct {
+	Name string
+}
+
+// NewGetNameHistoryCmd returns a new instance which can be used to issue a
+// getnamehistory JSON-RPC command.
+func NewGetNameHistoryCmd(name string) *GetNameHistoryCmd {
+	return &GetNameHistoryCmd{
+		Name: name,
+	}
+}
+
+// GetClaimsInChannelCmd defines the getclaimsinchannel JSON-RPC command.
+type GetClaimsInChannelCmd struct {
+	ChannelID string
+	BlockHash *string
+	Height    *int32
+}
+
+// NewGetClaimsInChannelCmd returns a new instance which can be used to issue a
+// getclaimsinchannel JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetClaimsInChannelCmd(channelID string, blockHash *string, height *int32) *GetClaimsInChannelCmd {
+	return &GetClaimsInChannelCmd{
+		ChannelID: channelID,
+		BlockHash: blockHash,
+		Height:    height,
+	}
+}
+
+// GetClaimAnomaliesCmd defines the getclaimanomalies JSON-RPC command.
+type GetClaimAnomaliesCmd struct {
+	FromHeight *int32
+	ToHeight   *int32
+}
+
+// NewGetClaimAnomaliesCmd returns a new instance which can be used to issue a
+// getclaimanomalies JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetClaimAnomaliesCmd(fromHeight, toHeight *int32) *GetClaimAnomaliesCmd {
+	return &GetClaimAnomaliesCmd{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+	}
+}
+
+// DumpClaimTrieCmd defines the dumpclaimtrie JSON-RPC command.
+type DumpClaimTrieCmd struct {
+	Filename string
+}
+
+// NewDumpClaimTrieCmd returns a new instance which can be used to issue a
+// dumpclaimtrie JSON-RPC command.
+func NewDumpClaimTrieCmd(filename string) *DumpClaimTrieCmd {
+	return &DumpClaimTrieCmd{
+		Filename: filename,
+	}
+}
+
+// ListNamesCmd defines the listnames JSON-RPC command.
+type ListNamesCmd struct {
+	Prefix string
+	Limit  *int `jsonrpcdefault:"100"`
+	Cursor *string
+}
+
+// NewListNamesCmd returns a new instance which can be used to issue a
+// listnames JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewListNamesCmd(prefix string, limit *int, cursor *string) *ListNamesCmd {
+	return &ListNamesCmd{
+		Prefix: prefix,
+		Limit:  limit,
+		Cursor: cursor,
+	}
+}
+
+// GetClaimsExpiringCmd defines the getclaimsexpiring JSON-RPC command.
+type GetClaimsExpiringCmd struct {
+	FromHeight int32
+	ToHeight   int32
+}
+
+// NewGetClaimsExpiringCmd returns a new instance which can be used to issue a
+// getclaimsexpiring JSON-RPC command.
+func NewGetClaimsExpiringCmd(fromHeight, toHeight int32) *GetClaimsExpiringCmd {
+	return &GetClaimsExpiringCmd{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+	}
+}
+
+// GetPendingActivationsCmd defines the getpendingactivations JSON-RPC command.
+type GetPendingActivationsCmd struct {
+	FromHeight *int32
+	ToHeight   *int32
+}
+
+// NewGetPendingActivationsCmd returns a new instance which can be used to
+// issue a getpendingactivations JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetPendingActivationsCmd(fromHeight, toHeight *int32) *GetPendingActivationsCmd {
+	return &GetPendingActivationsCmd{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+	}
+}
+
+// SimulateTakeoverCmd defines the simulatetakeover JSON-RPC command.
+type SimulateTakeoverCmd struct {
+	Name    string
+	Amount  int64
+	ClaimID *string
+}
+
+// NewSimulateTakeoverCmd returns a new instance which can be used to issue a
+// simulatetakeover JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSimulateTakeoverCmd(name string, amount int64, claimID *string) *SimulateTakeoverCmd {
+	return &SimulateTakeoverCmd{
+		Name:    name,
+		Amount:  amount,
+		ClaimID: claimID,
+	}
+}
+
+func init() {
+	// No special flags for commands in this file.
+	flags := UsageFlag(0)
+
+	MustRegisterCmd("dumpclaimtrie", (*DumpClaimTrieCmd)(nil), flags)
+	MustRegisterCmd("getclaimanomalies", (*GetClaimAnomaliesCmd)(nil), flags)
+	MustRegisterCmd("getclaimbyid", (*GetClaimByIDCmd)(nil), flags)
+	MustRegisterCmd("getclaimhistory", (*GetClaimHistoryCmd)(nil), flags)
+	MustRegisterCmd("getclaimsforname", (*GetClaimsForNameCmd)(nil), flags)
+	MustRegisterCmd("getclaimsexpiring", (*GetClaimsExpiringCmd)(nil), flags)
+	MustRegisterCmd("getclaimsinchannel", (*GetClaimsInChannelCmd)(nil), flags)
+	MustRegisterCmd("getnamehistory", (*GetNameHistoryCmd)(nil), flags)
+	MustRegisterCmd("getnameproof", (*GetNameProofCmd)(nil), flags)
+	MustRegisterCmd("getpendingactivations", (*GetPendingActivationsCmd)(nil), flags)
+	MustRegisterCmd("listnames", (*ListNamesCmd)(nil), flags)
+	MustRegisterCmd("simulatetakeover", (*SimulateTakeoverCmd)(nil), flags)
+}
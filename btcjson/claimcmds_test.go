@@ -0,0 +1,382 @@
+// Copyright (c) 2021 The LBRY developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package btcjson_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcjson"
+)
+
+// TestClaimCmds tests all of the claim commands marshal and unmarshal into
+// valid results include handling of optional fields being omitted in the
+// marshalled command, while optional fields with defaults have the default
+// assigned on unmarshalled commands.
+func TestClaimCmds(t *testing.T) {
+	t.Parallel()
+
+	testID := int(1)
+	tests := []struct {
+		name         string
+		newCmd       func() (interface{}, error)
+		staticCmd    func() interface{}
+		marshalled   string
+		unmarshalled interface{}
+	}{
+		{
+			name: "dumpclaimtrie",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("dumpclaimtrie", "claimtrie.snapshot")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDumpClaimTrieCmd("claimtrie.snapshot")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"dumpclaimtrie","params":["claimtrie.snapshot"],"id":1}`,
+			unmarshalled: &btcjson.DumpClaimTrieCmd{
+				Filename: "claimtrie.snapshot",
+			},
+		},
+		{
+			name: "getclaimbyid",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimbyid", "0123456789abcdef0123456789abcdef01234567")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimByIDCmd("0123456789abcdef0123456789abcdef01234567", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimbyid","params":["0123456789abcdef0123456789abcdef01234567"],"id":1}`,
+			unmarshalled: &btcjson.GetClaimByIDCmd{
+				ClaimID: "0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+		{
+			name: "getclaimbyid optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimbyid", "0123456789abcdef0123456789abcdef01234567", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimByIDCmd("0123456789abcdef0123456789abcdef01234567", btcjson.String("123"), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimbyid","params":["0123456789abcdef0123456789abcdef01234567","123"],"id":1}`,
+			unmarshalled: &btcjson.GetClaimByIDCmd{
+				ClaimID:   "0123456789abcdef0123456789abcdef01234567",
+				BlockHash: btcjson.String("123"),
+			},
+		},
+		{
+			name: "getclaimanomalies",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimanomalies")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimAnomaliesCmd(nil, nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getclaimanomalies","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetClaimAnomaliesCmd{},
+		},
+		{
+			name: "getclaimanomalies optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimanomalies", 100, 200)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimAnomaliesCmd(btcjson.Int32(100), btcjson.Int32(200))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimanomalies","params":[100,200],"id":1}`,
+			unmarshalled: &btcjson.GetClaimAnomaliesCmd{
+				FromHeight: btcjson.Int32(100),
+				ToHeight:   btcjson.Int32(200),
+			},
+		},
+		{
+			name: "getclaimhistory",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimhistory", "0123456789abcdef0123456789abcdef01234567")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimHistoryCmd("0123456789abcdef0123456789abcdef01234567")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimhistory","params":["0123456789abcdef0123456789abcdef01234567"],"id":1}`,
+			unmarshalled: &btcjson.GetClaimHistoryCmd{
+				ClaimID: "0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+		{
+			name: "getclaimsforname",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimsforname", "one")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimsForNameCmd("one", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimsforname","params":["one"],"id":1}`,
+			unmarshalled: &btcjson.GetClaimsForNameCmd{
+				Name: "one",
+			},
+		},
+		{
+			name: "getclaimsforname optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimsforname", "one", (*string)(nil), 100)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimsForNameCmd("one", nil, btcjson.Int32(100))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimsforname","params":["one",null,100],"id":1}`,
+			unmarshalled: &btcjson.GetClaimsForNameCmd{
+				Name:   "one",
+				Height: btcjson.Int32(100),
+			},
+		},
+		{
+			name: "getclaimsinchannel",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimsinchannel", "0123456789abcdef0123456789abcdef01234567")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimsInChannelCmd("0123456789abcdef0123456789abcdef01234567", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimsinchannel","params":["0123456789abcdef0123456789abcdef01234567"],"id":1}`,
+			unmarshalled: &btcjson.GetClaimsInChannelCmd{
+				ChannelID: "0123456789abcdef0123456789abcdef01234567",
+			},
+		},
+		{
+			name: "getclaimsinchannel optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimsinchannel", "0123456789abcdef0123456789abcdef01234567", (*string)(nil), 100)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimsInChannelCmd("0123456789abcdef0123456789abcdef01234567", nil, btcjson.Int32(100))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimsinchannel","params":["0123456789abcdef0123456789abcdef01234567",null,100],"id":1}`,
+			unmarshalled: &btcjson.GetClaimsInChannelCmd{
+				ChannelID: "0123456789abcdef0123456789abcdef01234567",
+				Height:    btcjson.Int32(100),
+			},
+		},
+		{
+			name: "getclaimsexpiring",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getclaimsexpiring", 100, 200)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetClaimsExpiringCmd(100, 200)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getclaimsexpiring","params":[100,200],"id":1}`,
+			unmarshalled: &btcjson.GetClaimsExpiringCmd{
+				FromHeight: 100,
+				ToHeight:   200,
+			},
+		},
+		{
+			name: "getpendingactivations",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpendingactivations")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPendingActivationsCmd(nil, nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getpendingactivations","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetPendingActivationsCmd{},
+		},
+		{
+			name: "getpendingactivations optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getpendingactivations", 100, 200)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetPendingActivationsCmd(btcjson.Int32(100), btcjson.Int32(200))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getpendingactivations","params":[100,200],"id":1}`,
+			unmarshalled: &btcjson.GetPendingActivationsCmd{
+				FromHeight: btcjson.Int32(100),
+				ToHeight:   btcjson.Int32(200),
+			},
+		},
+		{
+			name: "listnames",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listnames", "on")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListNamesCmd("on", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"listnames","params":["on"],"id":1}`,
+			unmarshalled: &btcjson.ListNamesCmd{
+				Prefix: "on",
+				Limit:  btcjson.Int(100),
+			},
+		},
+		{
+			name: "listnames optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listnames", "on", 10, "one")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListNamesCmd("on", btcjson.Int(10), btcjson.String("one"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"listnames","params":["on",10,"one"],"id":1}`,
+			unmarshalled: &btcjson.ListNamesCmd{
+				Prefix: "on",
+				Limit:  btcjson.Int(10),
+				Cursor: btcjson.String("one"),
+			},
+		},
+		{
+			name: "simulatetakeover",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("simulatetakeover", "one", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSimulateTakeoverCmd("one", 1000, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"simulatetakeover","params":["one",1000],"id":1}`,
+			unmarshalled: &btcjson.SimulateTakeoverCmd{
+				Name:   "one",
+				Amount: 1000,
+			},
+		},
+		{
+			name: "simulatetakeover optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("simulatetakeover", "one", 1000, "0123456789abcdef0123456789abcdef01234567")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSimulateTakeoverCmd("one", 1000, btcjson.String("0123456789abcdef0123456789abcdef01234567"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"simulatetakeover","params":["one",1000,"0123456789abcdef0123456789abcdef01234567"],"id":1}`,
+			unmarshalled: &btcjson.SimulateTakeoverCmd{
+				Name:    "one",
+				Amount:  1000,
+				ClaimID: btcjson.String("0123456789abcdef0123456789abcdef01234567"),
+			},
+		},
+		{
+			name: "getnamehistory",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnamehistory", "one")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNameHistoryCmd("one")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnamehistory","params":["one"],"id":1}`,
+			unmarshalled: &btcjson.GetNameHistoryCmd{
+				Name: "one",
+			},
+		},
+		{
+			name: "getnameproof",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnameproof", "one")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNameProofCmd("one", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnameproof","params":["one"],"id":1}`,
+			unmarshalled: &btcjson.GetNameProofCmd{
+				Name: "one",
+			},
+		},
+		{
+			name: "getnameproof optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnameproof", "one", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNameProofCmd("one", btcjson.String("123"), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnameproof","params":["one","123"],"id":1}`,
+			unmarshalled: &btcjson.GetNameProofCmd{
+				Name:      "one",
+				BlockHash: btcjson.String("123"),
+			},
+		},
+		{
+			name: "getnameproof height",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnameproof", "one", (*string)(nil), 100)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNameProofCmd("one", nil, btcjson.Int32(100))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnameproof","params":["one",null,100],"id":1}`,
+			unmarshalled: &btcjson.GetNameProofCmd{
+				Name:   "one",
+				Height: btcjson.Int32(100),
+			},
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Marshal the command as created by the new static command
+		// creation function.
+		marshalled, err := btcjson.MarshalCmd(btcjson.RpcVersion1, testID, test.staticCmd())
+		if err != nil {
+			t.Errorf("MarshalCmd #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+
+		if !bytes.Equal(marshalled, []byte(test.marshalled)) {
+			t.Errorf("Test #%d (%s) unexpected marshalled data - "+
+				"got %s, want %s", i, test.name, marshalled,
+				test.marshalled)
+			continue
+		}
+
+		// Ensure the command is created without error via the generic
+		// new command creation function.
+		cmd, err := test.newCmd()
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected NewCmd error: %v ",
+				i, test.name, err)
+		}
+
+		// Marshal the command as created by the generic new command
+		// creation function.
+		marshalled, err = btcjson.MarshalCmd(btcjson.RpcVersion1, testID, cmd)
+		if err != nil {
+			t.Errorf("MarshalCmd #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+
+		if !bytes.Equal(marshalled, []byte(test.marshalled)) {
+			t.Errorf("Test #%d (%s) unexpected marshalled data - "+
+				"got %s, want %s", i, test.name, marshalled,
+				test.marshalled)
+			continue
+		}
+
+		var request btcjson.Request
+		if err := json.Unmarshal(marshalled, &request); err != nil {
+			t.Errorf("Test #%d (%s) unexpected error while "+
+				"unmarshalling JSON-RPC request: %v", i,
+				test.name, err)
+			continue
+		}
+
+		cmd, err = btcjson.UnmarshalCmd(&request)
+		if err != nil {
+			t.Errorf("UnmarshalCmd #%d (%s) unexpected error: %v", i,
+				test.name, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(cmd, test.unmarshalled) {
+			t.Errorf("Test #%d (%s) unexpected unmarshalled command "+
+				"- got %s, want %s", i, test.name,
+				fmt.Sprintf("(%T) %+[1]v", cmd),
+				fmt.Sprintf("(%T) %+[1]v\n", test.unmarshalled))
+			continue
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2021 The LBRY developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package btcjson
+
+// SupportResult models the data of a single support of a claim.
+type SupportResult struct {
+	TxID             string `json:"txid"`
+	N                uint32 `json:"n"`
+	ClaimID          string `json:"claimid"`
+	Amount           int64  `json:"amount"`
+	AcceptedHeight   int32  `json:"acceptedheight"`
+	ActiveHeight     int32  `json:"activeheight"`
+	ExpirationHeight int32  `json:"expirationheight"`
+	Status           string `json:"status"`
+	Value            string `json:"value,omitempty"`
+}
+
+// ClaimResult models the data of a single claim, including the supports
+// that currently target it.
+type ClaimResult struct {
+	ClaimID          string           `json:"claimid"`
+	TxID             string           `json:"txid"`
+	N                uint32           `json:"n"`
+	Amount           int64            `json:"amount"`
+	EffectiveAmount  int64            `json:"effectiveamount"`
+	AcceptedHeight   int32            `json:"acceptedheight"`
+	ActiveHeight     int32            `json:"activeheight"`
+	ExpirationHeight int32            `json:"expirationheight"`
+	Status           string           `json:"status"`
+	Value            string           `json:"value"`
+	SigningChannelID string           `json:"signingchannelid,omitempty"`
+	Signature        string           `json:"signature,omitempty"`
+	SignatureValid   *bool            `json:"signaturevalid,omitempty"`
+	Meta             *ClaimMetaResult `json:"meta,omitempty"`
+	Supports         []SupportResult  `json:"supports"`
+}
+
+// ChannelClaimResult models a claim signed by a channel.
+type ChannelClaimResult struct {
+	Name  string      `json:"name"`
+	Claim ClaimResult `json:"claim"`
+}
+
+// GetClaimsInChannelResult models the data from the getclaimsinchannel command.
+type GetClaimsInChannelResult struct {
+	ChannelID string               `json:"channelid"`
+	Height    int32                `json:"height"`
+	Claims    []ChannelClaimResult `json:"claims"`
+}
+
+// ScheduledClaimResult models a claim or support which expires or activates in
+// the range of heights of a getclaimsexpiring or getpendingactivations command.
+type ScheduledClaimResult struct {
+	Name    string        `json:"name"`
+	Support bool          `json:"support"`
+	Claim   SupportResult `json:"claim"`
+}
+
+// ClaimScheduleResult models the data from the getclaimsexpiring and
+// getpendingactivations commands.
+type ClaimScheduleResult struct {
+	Height     int32                  `json:"height"`
+	FromHeight int32                  `json:"fromheight"`
+	ToHeight   int32                  `json:"toheight"`
+	Claims     []ScheduledClaimResult `json:"claims"`
+}
+
+// NameResult models a name along with its best claim.
+type NameResult struct {
+	Name               string `json:"name"`
+	BestClaimID        string `json:"bestclaimid"`
+	EffectiveAmount    int64  `json:"effectiveamount"`
+	LastTakeoverHeight int32  `json:"lasttakeoverheight"`
+}
+
+// ListNamesResult models the data from the listnames command.
+type ListNamesResult struct {
+	Height     int32        `json:"height"`
+	Names      []NameResult `json:"names"`
+	NextCursor string       `json:"nextcursor,omitempty"`
+}
+
+// SimulationStepResult models the state of a name at a height it is updated
+// during a simulation.
+type SimulationStepResult struct {
+	Height          int32  `json:"height"`
+	BestClaimID     string `json:"bestclaimid,omitempty"`
+	BestAmount      int64  `json:"bestamount"`
+	EffectiveAmount int64  `json:"effectiveamount"`
+}
+
+// SimulateTakeoverResult models the data from the simulatetakeover command.
+type SimulateTakeoverResult struct {
+	Name             string                 `json:"name"`
+	NormalizedName   string                 `json:"normalizedname"`
+	Height           int32                  `json:"height"`
+	ClaimID          string                 `json:"claimid"`
+	ActivationHeight int32                  `json:"activationheight"`
+	TakeoverHeight   int32                  `json:"takeoverheight,omitempty"`
+	Steps            []SimulationStepResult `json:"steps"`
+}
+
+// ClaimMetaResult models the metadata decoded from the value of a claim.  The
+// fields which don't apply to its type are omitted.
+type ClaimMetaResult struct {
+	Type         string   `json:"type"`
+	Legacy       bool     `json:"legacy,omitempty"`
+	Title        string   `json:"title,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	ThumbnailURL string   `json:"thumbnailurl,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Author       string   `json:"author,omitempty"`
+	License      string   `json:"license,omitempty"`
+	LicenseURL   string   `json:"licenseurl,omitempty"`
+	ReleaseTime  int64    `json:"releasetime,omitempty"`
+	StreamType   string   `json:"streamtype,omitempty"`
+	SourceName   string   `json:"sourcename,omitempty"`
+	SourceSize   uint64   `json:"sourcesize,omitempty"`
+	MediaType    string   `json:"mediatype,omitempty"`
+	SourceHash   string   `json:"sourcehash,omitempty"`
+	SDHash       string   `json:"sdhash,omitempty"`
+	FeeCurrency  string   `json:"feecurrency,omitempty"`
+	FeeAmount    uint64   `json:"feeamount,omitempty"`
+	FeeAddress   string   `json:"feeaddress,omitempty"`
+	PublicKey    string   `json:"publickey,omitempty"`
+	Email        string   `json:"email,omitempty"`
+	WebsiteURL   string   `json:"websiteurl,omitempty"`
+	CoverURL     string   `json:"coverurl,omitempty"`
+	ClaimIDs     []string `json:"claimids,omitempty"`
+}
+
+// GetClaimsForNameResult models the data from the getclaimsforname command.
+type GetClaimsForNameResult struct {
+	Name                 string          `json:"name"`
+	NormalizedName       string          `json:"normalizedname"`
+	Height               int32           `json:"height"`
+	LastTakeoverHeight   int32           `json:"lasttakeoverheight"`
+	BestClaimID          string          `json:"bestclaimid,omitempty"`
+	Claims               []ClaimResult   `json:"claims"`
+	SupportsWithoutClaim []SupportResult `json:"supportswithoutclaim"`
+}
+
+// NameProofChild models a child link of a vertex in a name proof.
+type NameProofChild struct {
+	Char uint8  `json:"char"`
+	Hash string `json:"hash"`
+}
+
+// NameProofNode models a vertex on the path of the name in a name proof.
+type NameProofNode struct {
+	Children  []NameProofChild `json:"children"`
+	ValueHash string           `json:"valuehash,omitempty"`
+}
+
+// NameProofPair models a sibling on the merkle path from the best claim hash
+// to the value hash of the name.
+type NameProofPair struct {
+	Right bool   `json:"right"`
+	Hash  string `json:"hash"`
+}
+
+// NameProofClaimResult models the best claim proven by a name proof.
+type NameProofClaimResult struct {
+	ClaimID            string `json:"claimid"`
+	TxID               string `json:"txid"`
+	N                  uint32 `json:"n"`
+	LastTakeoverHeight int32  `json:"lasttakeoverheight"`
+}
+
+// GetNameProofResult models the data from the getnameproof command.
+type GetNameProofResult struct {
+	Name              string                `json:"name"`
+	NormalizedName    string                `json:"normalizedname"`
+	BlockHash         string                `json:"blockhash"`
+	Height            int32                 `json:"height"`
+	ClaimTrie         string                `json:"claimtrie"`
+	AllClaimsInMerkle bool                  `json:"allclaimsinmerkle"`
+	Nodes             []NameProofNode       `json:"nodes"`
+	Pairs             []NameProofPair       `json:"pairs,omitempty"`
+	BestClaim         *NameProofClaimResult `json:"bestclaim,omitempty"`
+}
+
+// GetClaimByIDResult models the data from the getclaimbyid command.
+type GetClaimByIDResult struct {
+	ClaimID          string       `json:"claimid"`
+	Name             string       `json:"name"`
+	NormalizedName   string       `json:"normalizedname"`
+	TxID             string       `json:"txid"`
+	N                uint32       `json:"n"`
+	LastChangeHeight int32        `json:"lastchangeheight"`
+	Spent            bool         `json:"spent"`
+	IsBest           bool         `json:"isbest"`
+	Claim            *ClaimResult `json:"claim,omitempty"`
+}
+
+// ClaimHistoryResult models a change made to a claim.
+type ClaimHistoryResult struct {
+	Height    int32  `json:"height"`
+	Operation string `json:"operation"`
+	Name      string `json:"name"`
+	TxID      string `json:"txid"`
+	N         uint32 `json:"n"`
+	Amount    int64  `json:"amount"`
+	Value     string `json:"value,omitempty"`
+}
+
+// NameTakeoverResult models a change of the claim controlling a name.  The
+// claim IDs are omitted when the name had, or is left with, no controlling
+// claim.
+type NameTakeoverResult struct {
+	Height             int32  `json:"height"`
+	Name               string `json:"name"`
+	OldClaimID         string `json:"oldclaimid,omitempty"`
+	NewClaimID         string `json:"newclaimid,omitempty"`
+	OldEffectiveAmount int64  `json:"oldeffectiveamount"`
+	NewEffectiveAmount int64  `json:"neweffectiveamount"`
+}
+
+// ClaimAnomalyResult models an anomaly found while applying the claim
+// operations of a block.
+type ClaimAnomalyResult struct {
+	Type    string `json:"type"`
+	Height  int32  `json:"height"`
+	Name    string `json:"name"`
+	ClaimID string `json:"claimid"`
+	TxID    string `json:"txid"`
+	N       uint32 `json:"n"`
+}
+
+// DumpClaimTrieResult models the data from the dumpclaimtrie command.
+type DumpClaimTrieResult struct {
+	Filename  string `json:"filename"`
+	Height    int32  `json:"height"`
+	BlockHash string `json:"blockhash"`
+	ClaimTrie string `json:"claimtrie"`
+}
+
+// ClaimScriptResult models the claim, support or update made by a transaction
+// output, as found in the verbose results of transactions.  It's also used for
+// the output spent by a transaction input.
+type ClaimScriptResult struct {
+	Operation      string `json:"operation"`
+	Name           string `json:"name"`
+	NormalizedName string `json:"normalizedname"`
+	ClaimID        string `json:"claimid"`
+	Value          string `json:"value,omitempty"`
+}
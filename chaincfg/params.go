@@ -127,6 +127,11 @@ const (
 	// the deployment of BIPS 340, 341 and 342.
 	DeploymentTaproot
 
+	// DeploymentValidClaimUpdates defines the rule change deployment ID for
+	// rejecting blocks with OP_UPDATECLAIM outputs which do not update a
+	// claim of the same name spent by the same transaction.
+	DeploymentValidClaimUpdates
+
 	// NOTE: DefinedDeployments must always come last since it is used to
 	// determine how many defined deployments there currently are.
 
@@ -310,6 +315,11 @@ var MainNetParams = Params{
 			StartTime:  math.MaxInt64, // Not in the roadmap
 			ExpireTime: math.MaxInt64, // Not in the roadmap
 		},
+		DeploymentValidClaimUpdates: {
+			BitNumber:  2,
+			StartTime:  math.MaxInt64, // Not in the roadmap
+			ExpireTime: math.MaxInt64, // Not in the roadmap
+		},
 	},
 
 	// Mempool parameters
@@ -386,6 +396,11 @@ var RegressionNetParams = Params{
 			StartTime:  math.MaxInt64, // Not in the roadmap
 			ExpireTime: math.MaxInt64, // Not in the roadmap
 		},
+		DeploymentValidClaimUpdates: {
+			BitNumber:  2,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires
+		},
 	},
 
 	// Mempool parameters
@@ -465,6 +480,11 @@ var TestNet3Params = Params{
 			StartTime:  math.MaxInt64, // Not in the roadmap
 			ExpireTime: math.MaxInt64, // Not in the roadmap
 		},
+		DeploymentValidClaimUpdates: {
+			BitNumber:  2,
+			StartTime:  math.MaxInt64, // Not in the roadmap
+			ExpireTime: math.MaxInt64, // Not in the roadmap
+		},
 	},
 
 	// Mempool parameters
@@ -545,6 +565,11 @@ var SimNetParams = Params{
 			StartTime:  math.MaxInt64, // Not in the roadmap
 			ExpireTime: math.MaxInt64, // Not in the roadmap
 		},
+		DeploymentValidClaimUpdates: {
+			BitNumber:  2,
+			StartTime:  0,             // Always available for vote
+			ExpireTime: math.MaxInt64, // Never expires
+		},
 	},
 
 	// Mempool parameters
@@ -645,6 +670,11 @@ func CustomSignetParams(challenge []byte, dnsSeeds []DNSSeed) Params {
 				StartTime:  0,             // Always available for vote
 				ExpireTime: math.MaxInt64, // Never expires.
 			},
+			DeploymentValidClaimUpdates: {
+				BitNumber:  29,
+				StartTime:  0,             // Always available for vote
+				ExpireTime: math.MaxInt64, // Never expires.
+			},
 		},
 
 		// Mempool parameters
@@ -0,0 +1,55 @@
+// Package anomaly describes the inconsistencies found while applying the claim
+// operations of blocks, such as spending a claim which doesn't exist. They are
+// tolerated for compatibility with lbrycrd, and journaled for auditing.
+package anomaly
+
+import "fmt"
+
+// Type identifies the kind of an Anomaly.
+type Type uint8
+
+const (
+	// ConflictingOutPoint is a claim added with the outpoint of an existing claim of the name.
+	ConflictingOutPoint Type = iota + 1
+
+	// MissingSpentClaim is a spend of a claim which the name doesn't hold.
+	MissingSpentClaim
+
+	// MissingUpdatedClaim is an update of a claim which the name doesn't hold, or which wasn't spent.
+	MissingUpdatedClaim
+
+	// MissingSpentSupport is a spend of a support which the name doesn't hold.
+	MissingSpentSupport
+
+	// InvalidUpdate is an update whose name or claim ID doesn't match the claim spent by the transaction.
+	InvalidUpdate
+)
+
+var typeNames = map[Type]string{
+	ConflictingOutPoint: "conflictingoutpoint",
+	MissingSpentClaim:   "missingspentclaim",
+	MissingUpdatedClaim: "missingupdatedclaim",
+	MissingSpentSupport: "missingspentsupport",
+	InvalidUpdate:       "invalidupdate",
+}
+
+func (t Type) String() string {
+	if s, ok := typeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(t))
+}
+
+// Anomaly is an inconsistency found at a height.
+type Anomaly struct {
+	Type     Type
+	Height   int32
+	Name     []byte
+	ClaimID  string
+	OutPoint string
+}
+
+func (a Anomaly) String() string {
+	return fmt.Sprintf("%s at height %d, name: %q, claim ID: %s, outpoint: %s",
+		a.Type, a.Height, a.Name, a.ClaimID, a.OutPoint)
+}
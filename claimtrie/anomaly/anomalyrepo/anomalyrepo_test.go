@@ -0,0 +1,69 @@
+package anomalyrepo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPebble(t *testing.T) {
+
+	r := require.New(t)
+
+	repo, err := NewPebble(t.TempDir())
+	r.NoError(err)
+	defer func() {
+		err := repo.Close()
+		r.NoError(err)
+	}()
+
+	testAnomalyRepo(t, repo)
+}
+
+func TestMemory(t *testing.T) {
+	testAnomalyRepo(t, NewMemory())
+}
+
+func testAnomalyRepo(t *testing.T, repo anomaly.Repo) {
+
+	r := require.New(t)
+
+	a1 := anomaly.Anomaly{Type: anomaly.MissingSpentClaim, Height: 1, Name: []byte("a"), ClaimID: "01", OutPoint: "00:1"}
+	a2 := anomaly.Anomaly{Type: anomaly.InvalidUpdate, Height: 2, Name: []byte("b"), ClaimID: "02", OutPoint: "00:2"}
+	a3 := anomaly.Anomaly{Type: anomaly.MissingSpentSupport, Height: 300, Name: []byte("c"), ClaimID: "03", OutPoint: "00:3"}
+
+	r.NoError(repo.Save([]anomaly.Anomaly{a3, a1}))
+	r.NoError(repo.Save([]anomaly.Anomaly{a2, a1})) // a1 is saved only once
+
+	anomalies, err := repo.Load(0, math.MaxInt32)
+	r.NoError(err)
+	r.Equal([]anomaly.Anomaly{a1, a2, a3}, anomalies)
+
+	anomalies, err = repo.Load(2, 299)
+	r.NoError(err)
+	r.Equal([]anomaly.Anomaly{a2}, anomalies)
+
+	anomalies, err = repo.Load(3, 2)
+	r.NoError(err)
+	r.Empty(anomalies)
+
+	r.NoError(repo.Drop(1))
+	anomalies, err = repo.Load(0, math.MaxInt32)
+	r.NoError(err)
+	r.Equal([]anomaly.Anomaly{a1}, anomalies)
+
+	// A snapshot keeps the anomalies as they were, and can't be written to.
+	snap, err := repo.Snapshot()
+	r.NoError(err)
+	r.NoError(repo.Save([]anomaly.Anomaly{a2}))
+	r.Error(snap.Save([]anomaly.Anomaly{a3}))
+	r.Error(snap.Drop(0))
+
+	anomalies, err = snap.Load(0, math.MaxInt32)
+	r.NoError(err)
+	r.Equal([]anomaly.Anomaly{a1}, anomalies)
+	r.NoError(snap.Close())
+}
@@ -0,0 +1,86 @@
+package anomalyrepo
+
+import (
+	"sort"
+
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
+)
+
+type Memory struct {
+	anomalies map[string]anomaly.Anomaly
+
+	// readOnly is set on the snapshots of the repo.
+	readOnly bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		anomalies: map[string]anomaly.Anomaly{},
+	}
+}
+
+func (repo *Memory) Save(anomalies []anomaly.Anomaly) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for _, a := range anomalies {
+		key, err := anomalyKey(a)
+		if err != nil {
+			return err
+		}
+		a.Name = append([]byte(nil), a.Name...)
+		repo.anomalies[string(key)] = a
+	}
+
+	return nil
+}
+
+func (repo *Memory) Load(fromHeight, toHeight int32) ([]anomaly.Anomaly, error) {
+
+	var keys []string
+	for key, a := range repo.anomalies {
+		if a.Height >= fromHeight && a.Height <= toHeight {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys) // same order as the Pebble repo
+
+	var anomalies []anomaly.Anomaly
+	for _, key := range keys {
+		anomalies = append(anomalies, repo.anomalies[key])
+	}
+
+	return anomalies, nil
+}
+
+func (repo *Memory) Drop(finalHeight int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for key, a := range repo.anomalies {
+		if a.Height > finalHeight {
+			delete(repo.anomalies, key)
+		}
+	}
+
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now.
+func (repo *Memory) Snapshot() (anomaly.Repo, error) {
+
+	snap := &Memory{anomalies: make(map[string]anomaly.Anomaly, len(repo.anomalies)), readOnly: true}
+	for key, a := range repo.anomalies {
+		snap.anomalies[key] = a
+	}
+
+	return snap, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
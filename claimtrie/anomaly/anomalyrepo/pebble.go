@@ -0,0 +1,164 @@
+package anomalyrepo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// errReadOnly is returned by the writes to a snapshot of the repo.
+var errReadOnly = errors.New("anomaly repo snapshot is read-only")
+
+// The anomalies are kept in the keys, so saving one again overwrites it:
+//
+//	height(4B) + msgpack(anomaly) -> nil
+type Pebble struct {
+	db *pebble.DB
+
+	// snap is set on the snapshots of the repo, which read from it instead of db.
+	snap *pebble.Snapshot
+}
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(16 << 20)})
+	if err != nil {
+		return nil, fmt.Errorf("pebble open %s, %w", path, err)
+	}
+
+	repo := &Pebble{db: db}
+
+	return repo, nil
+}
+
+func anomalyKey(a anomaly.Anomaly) ([]byte, error) {
+
+	value, err := msgpack.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(heightKey(uint32(a.Height)), value...), nil
+}
+
+func heightKey(height uint32) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, height)
+	return key
+}
+
+func (repo *Pebble) Save(anomalies []anomaly.Anomaly) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	for _, a := range anomalies {
+		key, err := anomalyKey(a)
+		if err != nil {
+			return fmt.Errorf("msgpack marshal key: %w", err)
+		}
+		err = batch.Set(key, nil, pebble.NoSync)
+		if err != nil {
+			return fmt.Errorf("pebble set: %w", err)
+		}
+	}
+
+	err := batch.Commit(pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble save commit: %w", err)
+	}
+	return nil
+}
+
+func (repo *Pebble) Load(fromHeight, toHeight int32) ([]anomaly.Anomaly, error) {
+
+	if fromHeight < 0 {
+		fromHeight = 0
+	}
+	if toHeight < fromHeight {
+		return nil, nil
+	}
+
+	iter := repo.reader().NewIter(&pebble.IterOptions{
+		LowerBound: heightKey(uint32(fromHeight)),
+		UpperBound: heightKey(uint32(toHeight) + 1),
+	})
+
+	var anomalies []anomaly.Anomaly
+	for iter.First(); iter.Valid(); iter.Next() {
+		var a anomaly.Anomaly
+		err := msgpack.Unmarshal(iter.Key()[4:], &a)
+		if err != nil {
+			iter.Close()
+			return nil, fmt.Errorf("msgpack unmarshal: %w", err)
+		}
+		anomalies = append(anomalies, a)
+	}
+
+	err := iter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("pebble get: %w", err)
+	}
+
+	return anomalies, nil
+}
+
+func (repo *Pebble) Drop(finalHeight int32) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	err := repo.db.DeleteRange(heightKey(uint32(finalHeight)+1), []byte{0xff, 0xff, 0xff, 0xff, 0xff}, pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble delete range: %w", err)
+	}
+
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now, which must be closed.
+func (repo *Pebble) Snapshot() (anomaly.Repo, error) {
+
+	if repo.snap != nil {
+		return nil, errReadOnly
+	}
+
+	return &Pebble{snap: repo.db.NewSnapshot()}, nil
+}
+
+// reader returns the snapshot of a snapshot repo, or the DB.
+func (repo *Pebble) reader() pebble.Reader {
+	if repo.snap != nil {
+		return repo.snap
+	}
+	return repo.db
+}
+
+func (repo *Pebble) Close() error {
+
+	if repo.snap != nil {
+		return repo.snap.Close()
+	}
+
+	err := repo.db.Flush()
+	if err != nil {
+		return fmt.Errorf("pebble flush: %w", err)
+	}
+
+	err = repo.db.Close()
+	if err != nil {
+		return fmt.Errorf("pebble close: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package anomaly
+
+// Repo defines APIs for the anomaly journal to access persistence layer.
+type Repo interface {
+	// Save saves the anomalies into the repo. Saving an anomaly again has no effect.
+	Save(anomalies []Anomaly) error
+
+	// Load loads the anomalies found from fromHeight to toHeight inclusively, ordered by height.
+	Load(fromHeight, toHeight int32) ([]Anomaly, error)
+
+	// Drop removes the anomalies found above finalHeight.
+	Drop(finalHeight int32) error
+
+	// Snapshot returns a read-only copy of the repo as it is now, whose writes
+	// fail. It must be closed, and can be read concurrently with the repo.
+	Snapshot() (Repo, error)
+
+	// Close closes the repo.
+	Close() error
+}
@@ -0,0 +1,103 @@
+package blockrepo
+
+import (
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/block"
+
+	"github.com/cockroachdb/pebble"
+)
+
+type Memory struct {
+	hashes     map[int32]chainhash.Hash
+	baseHeight int32
+
+	// readOnly is set on the snapshots of the repo.
+	readOnly bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		hashes: map[int32]chainhash.Hash{},
+	}
+}
+
+func (repo *Memory) Load() (int32, error) {
+
+	var height int32
+	for h := range repo.hashes {
+		if h > height {
+			height = h
+		}
+	}
+
+	return height, nil
+}
+
+func (repo *Memory) Get(height int32) (*chainhash.Hash, error) {
+
+	hash, ok := repo.hashes[height]
+	if !ok {
+		return nil, pebble.ErrNotFound // the same error as the Pebble repo
+	}
+
+	return &hash, nil
+}
+
+func (repo *Memory) Set(height int32, hash *chainhash.Hash) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	repo.hashes[height] = *hash
+	return nil
+}
+
+// Truncate removes the hashes above the height.
+func (repo *Memory) Truncate(height int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for h := range repo.hashes {
+		if h > height {
+			delete(repo.hashes, h)
+		}
+	}
+
+	return nil
+}
+
+func (repo *Memory) BaseHeight() (int32, error) {
+	return repo.baseHeight, nil
+}
+
+func (repo *Memory) SetBaseHeight(height int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	repo.baseHeight = height
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now.
+func (repo *Memory) Snapshot() (block.Repo, error) {
+
+	snap := &Memory{
+		hashes:     make(map[int32]chainhash.Hash, len(repo.hashes)),
+		baseHeight: repo.baseHeight,
+		readOnly:   true,
+	}
+	for h, hash := range repo.hashes {
+		snap.hashes[h] = hash
+	}
+
+	return snap, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
@@ -2,15 +2,26 @@ package blockrepo
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/block"
 
 	"github.com/cockroachdb/pebble"
 )
 
+// errReadOnly is returned by the writes to a snapshot of the repo.
+var errReadOnly = errors.New("block repo snapshot is read-only")
+
+// baseHeightKey sorts before the keys of the hashes, which are 4 bytes long.
+var baseHeightKey = []byte{0}
+
 type Pebble struct {
 	db *pebble.DB
+
+	// snap is set on the snapshots of the repo, which read from it instead of db.
+	snap *pebble.Snapshot
 }
 
 func NewPebble(path string) (*Pebble, error) {
@@ -27,7 +38,7 @@ func NewPebble(path string) (*Pebble, error) {
 
 func (repo *Pebble) Load() (int32, error) {
 
-	iter := repo.db.NewIter(nil)
+	iter := repo.reader().NewIter(&pebble.IterOptions{LowerBound: []byte{0, 0, 0, 0}})
 	if !iter.Last() {
 		if err := iter.Close(); err != nil {
 			return 0, fmt.Errorf("close iter: %w", err)
@@ -48,7 +59,7 @@ func (repo *Pebble) Get(height int32) (*chainhash.Hash, error) {
 	key := make([]byte, 4)
 	binary.BigEndian.PutUint32(key, uint32(height))
 
-	b, closer, err := repo.db.Get(key)
+	b, closer, err := repo.reader().Get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -61,14 +72,79 @@ func (repo *Pebble) Get(height int32) (*chainhash.Hash, error) {
 
 func (repo *Pebble) Set(height int32, hash *chainhash.Hash) error {
 
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
 	key := make([]byte, 4)
 	binary.BigEndian.PutUint32(key, uint32(height))
 
 	return repo.db.Set(key, hash[:], pebble.NoSync)
 }
 
+// Truncate removes the hashes above the height.
+func (repo *Pebble) Truncate(height int32) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	start := make([]byte, 4)
+	binary.BigEndian.PutUint32(start, uint32(height+1))
+
+	return repo.db.DeleteRange(start, []byte{0xff, 0xff, 0xff, 0xff, 0xff}, pebble.NoSync)
+}
+
+func (repo *Pebble) BaseHeight() (int32, error) {
+
+	b, closer, err := repo.reader().Get(baseHeightKey)
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer closer.Close()
+
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (repo *Pebble) SetBaseHeight(height int32) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, uint32(height))
+
+	return repo.db.Set(baseHeightKey, value, pebble.NoSync)
+}
+
+// Snapshot returns a read-only copy of the repo as it is now, which must be closed.
+func (repo *Pebble) Snapshot() (block.Repo, error) {
+
+	if repo.snap != nil {
+		return nil, errReadOnly
+	}
+
+	return &Pebble{snap: repo.db.NewSnapshot()}, nil
+}
+
+// reader returns the snapshot of a snapshot repo, or the DB.
+func (repo *Pebble) reader() pebble.Reader {
+	if repo.snap != nil {
+		return repo.snap
+	}
+	return repo.db
+}
+
 func (repo *Pebble) Close() error {
 
+	if repo.snap != nil {
+		return repo.snap.Close()
+	}
+
 	err := repo.db.Flush()
 	if err != nil {
 		return fmt.Errorf("pebble fludh: %w", err)
@@ -9,5 +9,16 @@ type Repo interface {
 	Load() (int32, error)
 	Set(height int32, hash *chainhash.Hash) error
 	Get(height int32) (*chainhash.Hash, error)
+	Truncate(height int32) error
+
+	// BaseHeight returns the height of the snapshot the repo was bootstrapped
+	// from, below which the hashes aren't backed by a Merkle Trie, or 0.
+	BaseHeight() (int32, error)
+	SetBaseHeight(height int32) error
+
+	// Snapshot returns a read-only copy of the repo as it is now, whose writes
+	// fail. It must be closed, and can be read concurrently with the repo.
+	Snapshot() (Repo, error)
+
 	Close() error
 }
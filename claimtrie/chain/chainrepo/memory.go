@@ -0,0 +1,42 @@
+package chainrepo
+
+import (
+	"github.com/btcsuite/btcd/claimtrie/change"
+
+	"github.com/cockroachdb/pebble"
+)
+
+type Memory struct {
+	changes map[int32][]change.Change
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		changes: map[int32][]change.Change{},
+	}
+}
+
+func (repo *Memory) Save(height int32, changes []change.Change) error {
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	repo.changes[height] = append([]change.Change(nil), changes...)
+
+	return nil
+}
+
+func (repo *Memory) Load(height int32) ([]change.Change, error) {
+
+	changes, ok := repo.changes[height]
+	if !ok {
+		return nil, pebble.ErrNotFound // the same error as the Pebble repo
+	}
+
+	return append([]change.Change(nil), changes...), nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
@@ -8,6 +8,10 @@ const (
 	UpdateClaim
 	AddSupport
 	SpendSupport
+
+	// Checkpoint stands for the changes of a node up to its height, which have
+	// been pruned. Its Value holds the state of the node they built.
+	Checkpoint
 )
 
 type Change struct {
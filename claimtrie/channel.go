@@ -0,0 +1,290 @@
+package claimtrie
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/claimtrie/channel"
+	"github.com/btcsuite/btcd/claimtrie/metadata"
+	"github.com/btcsuite/btcd/claimtrie/node"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ErrChannelIndexDisabled is returned when a query requires the channel index,
+// which is not enabled.
+var ErrChannelIndexDisabled = errors.New("channel index is disabled")
+
+// ChannelClaim is a claim signed by a channel, as held by its node.
+type ChannelClaim struct {
+	Name  []byte // as stored in the ClaimTrie
+	Node  *node.Node
+	Claim *node.Claim
+
+	// SignatureValid tells whether the signature verifies against the public
+	// key of the channel at the height of the query.
+	SignatureValid bool
+}
+
+// IndexChannelClaim records the claim added or updated at the height in the
+// channel index, if it is a channel or signed by one. The first input of the
+// transaction holding the claim is covered by its signature. The entries are
+// saved by CommitChannelIndex, which AppendBlock calls.
+func (ct *ClaimTrie) IndexChannelClaim(height int32, name []byte, op wire.OutPoint, id node.ClaimID, value []byte, firstInput wire.OutPoint) {
+
+	if ct.channelRepo == nil {
+		return
+	}
+
+	v, err := metadata.Decode(value)
+	if err != nil {
+		return
+	}
+
+	entry := channel.Entry{
+		Height:     height,
+		ClaimID:    id.String(),
+		Name:       name,
+		OutPoint:   op.String(),
+		FirstInput: firstInput.String(),
+	}
+	if v.Meta.Type == metadata.Channel {
+		entry.ChannelID = entry.ClaimID
+		ct.channelEntries = append(ct.channelEntries, entry)
+	}
+	if v.Signed {
+		entry.ChannelID = v.SigningChannelID.String()
+		ct.channelEntries = append(ct.channelEntries, entry)
+	}
+}
+
+// CommitChannelIndex saves the entries recorded by IndexChannelClaim, and the
+// height up to which the channel index is complete.
+func (ct *ClaimTrie) CommitChannelIndex(height int32) error {
+
+	if ct.channelRepo == nil {
+		return ErrChannelIndexDisabled
+	}
+
+	if len(ct.channelEntries) > 0 {
+		err := ct.channelRepo.AppendEntries(ct.channelEntries)
+		if err != nil {
+			return fmt.Errorf("channel repo append: %w", err)
+		}
+		ct.channelEntries = ct.channelEntries[:0]
+	}
+
+	return ct.channelRepo.SetHeight(height)
+}
+
+// ChannelIndexHeight returns the height up to which the channel index is
+// complete. The chain catches it up from there to the height of the ClaimTrie.
+func (ct *ClaimTrie) ChannelIndexHeight() (int32, error) {
+
+	if ct.channelRepo == nil {
+		return 0, ErrChannelIndexDisabled
+	}
+
+	return ct.channelRepo.Height()
+}
+
+// dropChannelEntries removes the entries of the channel index above the height.
+func (ct *ClaimTrie) dropChannelEntries(height int32) error {
+
+	ct.channelEntries = ct.channelEntries[:0]
+	err := ct.channelRepo.DropEntries(height)
+	if err != nil {
+		return err
+	}
+
+	return ct.channelRepo.SetHeight(height)
+}
+
+// ClaimsInChannel returns the claims signed by the channel at the height,
+// ordered by the height they were first signed at. They are returned whether
+// or not their signature is valid. It requires the channel index, which is
+// enabled by config.ChannelIndex.
+func (ct *ClaimTrie) ClaimsInChannel(channelID node.ClaimID, height int32) ([]ChannelClaim, error) {
+
+	entries, err := ct.channelEntriesAt(channelID, height)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := ct.channelPublicKey(channelID, entries, height)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the latest entry of each claim signed by the channel.
+	id := channelID.String()
+	var ids []string
+	latest := map[string]channel.Entry{}
+	for _, entry := range entries {
+		if entry.ClaimID == id {
+			continue
+		}
+		if _, ok := latest[entry.ClaimID]; !ok {
+			ids = append(ids, entry.ClaimID)
+		}
+		latest[entry.ClaimID] = entry
+	}
+
+	nodes := map[string]*node.Node{}
+	claims := make([]ChannelClaim, 0, len(ids))
+	for _, claimID := range ids {
+		entry := latest[claimID]
+		name := string(node.NormalizeIfNecessary(entry.Name, height))
+		n, ok := nodes[name]
+		if !ok {
+			n, err = ct.NodeAt(height, []byte(name))
+			if err != nil {
+				return nil, fmt.Errorf("node at: %w", err)
+			}
+			nodes[name] = n
+		}
+
+		// The claim is no longer signed by the channel once spent, expired,
+		// or updated with another signature.
+		c := findClaim(n, entry)
+		if c == nil {
+			continue
+		}
+
+		claims = append(claims, ChannelClaim{
+			Name:           []byte(name),
+			Node:           n,
+			Claim:          c,
+			SignatureValid: verifyEntry(c, entry, publicKey),
+		})
+	}
+
+	return claims, nil
+}
+
+// ClaimSignatureValid tells whether the claim, as held at the height, is validly
+// signed by the channel it claims to be signed by. It requires the channel
+// index, which is enabled by config.ChannelIndex.
+func (ct *ClaimTrie) ClaimSignatureValid(c *node.Claim, height int32) (bool, error) {
+
+	if ct.channelRepo == nil {
+		return false, ErrChannelIndexDisabled
+	}
+
+	v, err := metadata.Decode(c.Value)
+	if err != nil || !v.Signed {
+		return false, nil
+	}
+
+	entries, err := ct.channelEntriesAt(v.SigningChannelID, height)
+	if err != nil {
+		return false, err
+	}
+
+	var entry *channel.Entry
+	for i := range entries {
+		if entries[i].ClaimID == c.ClaimID && entries[i].OutPoint == c.OutPoint.String() {
+			entry = &entries[i]
+		}
+	}
+	if entry == nil {
+		return false, nil
+	}
+
+	publicKey, err := ct.channelPublicKey(v.SigningChannelID, entries, height)
+	if err != nil {
+		return false, err
+	}
+
+	return verifyEntry(c, *entry, publicKey), nil
+}
+
+// channelEntriesAt returns the entries of the channel recorded up to the height.
+func (ct *ClaimTrie) channelEntriesAt(channelID node.ClaimID, height int32) ([]channel.Entry, error) {
+
+	if ct.channelRepo == nil {
+		return nil, ErrChannelIndexDisabled
+	}
+	if height < 0 || height > ct.height {
+		return nil, fmt.Errorf("height %d is out of the range of the ClaimTrie [0, %d]", height, ct.height)
+	}
+
+	entries, err := ct.channelRepo.LoadEntries(channelID.String())
+	if err != nil {
+		return nil, fmt.Errorf("channel repo load: %w", err)
+	}
+	for i := range entries {
+		if entries[i].Height > height {
+			return entries[:i], nil
+		}
+	}
+
+	return entries, nil
+}
+
+// channelPublicKey returns the public key held by the channel claim at the
+// height, or nil if the channel doesn't exist at that height.
+func (ct *ClaimTrie) channelPublicKey(channelID node.ClaimID, entries []channel.Entry, height int32) ([]byte, error) {
+
+	id := channelID.String()
+	var self *channel.Entry
+	for i := range entries {
+		if entries[i].ClaimID == id {
+			self = &entries[i]
+		}
+	}
+	if self == nil {
+		return nil, nil
+	}
+
+	n, err := ct.NodeAt(height, node.NormalizeIfNecessary(self.Name, height))
+	if err != nil {
+		return nil, fmt.Errorf("node at: %w", err)
+	}
+	c := findClaim(n, *self)
+	if c == nil {
+		return nil, nil
+	}
+
+	v, err := metadata.Decode(c.Value)
+	if err != nil || v.Meta.Type != metadata.Channel {
+		return nil, nil
+	}
+
+	return v.Meta.PublicKey, nil
+}
+
+// findClaim returns the claim of the node recorded by the entry, if the node
+// still holds it.
+func findClaim(n *node.Node, entry channel.Entry) *node.Claim {
+
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Claims {
+		if c.ClaimID == entry.ClaimID && c.OutPoint.String() == entry.OutPoint {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// verifyEntry verifies the signature of the claim recorded by the entry.
+func verifyEntry(c *node.Claim, entry channel.Entry, publicKey []byte) bool {
+
+	if publicKey == nil {
+		return false
+	}
+
+	v, err := metadata.Decode(c.Value)
+	if err != nil {
+		return false
+	}
+	firstInput := node.NewOutPointFromString(entry.FirstInput)
+	if firstInput == nil {
+		return false
+	}
+
+	return v.VerifySignature(*firstInput, publicKey)
+}
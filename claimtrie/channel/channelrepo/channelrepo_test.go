@@ -0,0 +1,95 @@
+package channelrepo
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/claimtrie/channel"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPebble(t *testing.T) {
+
+	r := require.New(t)
+
+	repo, err := NewPebble(t.TempDir())
+	r.NoError(err)
+	defer func() {
+		err := repo.Close()
+		r.NoError(err)
+	}()
+
+	testChannelRepo(t, repo)
+}
+
+func TestMemory(t *testing.T) {
+	testChannelRepo(t, NewMemory())
+}
+
+func testChannelRepo(t *testing.T, repo channel.Repo) {
+
+	r := require.New(t)
+
+	ch1 := "0000000000000000000000000000000000000001"
+	ch2 := "0000000000000000000000000000000000000002"
+	id := "0000000000000000000000000000000000000003"
+
+	self := channel.Entry{ChannelID: ch1, Height: 1, ClaimID: ch1, Name: []byte("@a"), OutPoint: "a:0"}
+	signed := channel.Entry{ChannelID: ch1, Height: 1, ClaimID: id, Name: []byte("b"), OutPoint: "b:0", FirstInput: "c:1"}
+	other := channel.Entry{ChannelID: ch2, Height: 1, ClaimID: ch2, Name: []byte("@c"), OutPoint: "d:0"}
+
+	err := repo.AppendEntries([]channel.Entry{self, signed, other, {Height: 1}}) // no channel ID; skipped
+	r.NoError(err)
+	r.NoError(repo.SetHeight(1))
+
+	updated := signed
+	updated.Height, updated.OutPoint = 3, "e:0"
+	err = repo.AppendEntries([]channel.Entry{updated})
+	r.NoError(err)
+	r.NoError(repo.SetHeight(3))
+
+	height, err := repo.Height()
+	r.NoError(err)
+	r.Equal(int32(3), height)
+
+	entries, err := repo.LoadEntries(ch1)
+	r.NoError(err)
+	r.Equal([]channel.Entry{self, signed, updated}, entries)
+
+	entries, err = repo.LoadEntries(ch2)
+	r.NoError(err)
+	r.Equal([]channel.Entry{other}, entries)
+
+	err = repo.DropEntries(2)
+	r.NoError(err)
+	entries, err = repo.LoadEntries(ch1)
+	r.NoError(err)
+	r.Equal([]channel.Entry{self, signed}, entries)
+
+	err = repo.DropEntries(0)
+	r.NoError(err)
+	entries, err = repo.LoadEntries(ch1)
+	r.NoError(err)
+	r.Nil(entries)
+	entries, err = repo.LoadEntries(ch2)
+	r.NoError(err)
+	r.Nil(entries)
+
+	// A snapshot keeps the entries as they were, and can't be written to.
+	r.NoError(repo.AppendEntries([]channel.Entry{self}))
+	snap, err := repo.Snapshot()
+	r.NoError(err)
+	r.NoError(repo.AppendEntries([]channel.Entry{updated}))
+	r.NoError(repo.SetHeight(4))
+	r.Error(snap.AppendEntries([]channel.Entry{updated}))
+	r.Error(snap.DropEntries(0))
+	r.Error(snap.SetHeight(4))
+
+	entries, err = snap.LoadEntries(ch1)
+	r.NoError(err)
+	r.Equal([]channel.Entry{self}, entries)
+	height, err = snap.Height()
+	r.NoError(err)
+	r.Equal(int32(3), height)
+	r.NoError(snap.Close())
+}
@@ -0,0 +1,98 @@
+package channelrepo
+
+import (
+	"github.com/btcsuite/btcd/claimtrie/channel"
+)
+
+type Memory struct {
+	entries map[string][]channel.Entry
+	height  int32
+
+	// readOnly is set on the snapshots of the repo.
+	readOnly bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		entries: map[string][]channel.Entry{},
+	}
+}
+
+func (repo *Memory) AppendEntries(entries []channel.Entry) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for _, entry := range entries {
+		if entry.ChannelID == "" {
+			continue
+		}
+		repo.entries[entry.ChannelID] = append(repo.entries[entry.ChannelID], entry)
+	}
+
+	return nil
+}
+
+func (repo *Memory) LoadEntries(channelID string) ([]channel.Entry, error) {
+
+	entries := repo.entries[channelID]
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	return append([]channel.Entry(nil), entries...), nil
+}
+
+func (repo *Memory) DropEntries(finalHeight int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for channelID, entries := range repo.entries {
+		i := 0
+		for ; i < len(entries); i++ {
+			if entries[i].Height > finalHeight {
+				break
+			}
+		}
+		if i == 0 {
+			delete(repo.entries, channelID)
+		} else {
+			repo.entries[channelID] = entries[:i:i]
+		}
+	}
+
+	return nil
+}
+
+func (repo *Memory) Height() (int32, error) {
+	return repo.height, nil
+}
+
+func (repo *Memory) SetHeight(height int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	repo.height = height
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now.
+func (repo *Memory) Snapshot() (channel.Repo, error) {
+
+	// The entries are only ever appended, so their slices are shared up to their length.
+	snap := &Memory{entries: make(map[string][]channel.Entry, len(repo.entries)), height: repo.height, readOnly: true}
+	for channelID, entries := range repo.entries {
+		snap.entries[channelID] = entries[:len(entries):len(entries)]
+	}
+
+	return snap, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
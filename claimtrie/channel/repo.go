@@ -0,0 +1,43 @@
+package channel
+
+// Entry records the addition or update of a claim of a channel, which is either
+// the channel claim itself or a claim signed by the channel.
+type Entry struct {
+	ChannelID string
+	Height    int32
+
+	ClaimID  string
+	Name     []byte
+	OutPoint string
+
+	// FirstInput is the first input of the transaction holding the claim,
+	// which is covered by the signature.
+	FirstInput string
+}
+
+// Repo defines APIs for the channel index to access persistence layer.
+type Repo interface {
+	// AppendEntries saves entries into the repo.
+	// The chronological order must be preserved for the same channel.
+	AppendEntries(entries []Entry) error
+
+	// LoadEntries loads the entries of a channel, ordered by height.
+	// If no entries found, both returned slice and error will be nil.
+	LoadEntries(channelID string) ([]Entry, error)
+
+	// DropEntries removes the entries recorded above finalHeight.
+	DropEntries(finalHeight int32) error
+
+	// Height returns the height up to which the entries have been saved.
+	Height() (int32, error)
+
+	// SetHeight records the height up to which the entries have been saved.
+	SetHeight(height int32) error
+
+	// Snapshot returns a read-only copy of the repo as it is now, whose writes
+	// fail. It must be closed, and can be read concurrently with the repo.
+	Snapshot() (Repo, error)
+
+	// Close closes the repo.
+	Close() error
+}
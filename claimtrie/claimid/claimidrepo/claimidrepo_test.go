@@ -0,0 +1,100 @@
+package claimidrepo
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/claimid"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPebble(t *testing.T) {
+
+	r := require.New(t)
+
+	repo, err := NewPebble(t.TempDir())
+	r.NoError(err)
+	defer func() {
+		err := repo.Close()
+		r.NoError(err)
+	}()
+
+	testClaimIDRepo(t, repo)
+}
+
+func TestMemory(t *testing.T) {
+	testClaimIDRepo(t, NewMemory())
+}
+
+func testClaimIDRepo(t *testing.T, repo claimid.Repo) {
+
+	r := require.New(t)
+
+	id1 := "0000000000000000000000000000000000000001"
+	id2 := "0000000000000000000000000000000000000002"
+
+	add := change.New(change.AddClaim).SetName([]byte("a")).SetClaimID(id1)
+	spend := change.New(change.SpendClaim).SetName([]byte("a")).SetClaimID(id1)
+	update := change.New(change.UpdateClaim).SetName([]byte("a")).SetClaimID(id1)
+
+	err := repo.AppendChanges([]change.Change{
+		add.SetHeight(1),
+		add.SetHeight(1).SetClaimID(id2),
+		change.New(change.SpendClaim).SetHeight(1), // no claim ID; skipped
+	})
+	r.NoError(err)
+	r.NoError(repo.SetHeight(1))
+
+	err = repo.AppendChanges([]change.Change{spend.SetHeight(3), update.SetHeight(3)})
+	r.NoError(err)
+	err = repo.AppendChanges([]change.Change{spend.SetHeight(5)})
+	r.NoError(err)
+	r.NoError(repo.SetHeight(5))
+
+	height, err := repo.Height()
+	r.NoError(err)
+	r.Equal(int32(5), height)
+
+	changes, err := repo.LoadChanges(id1)
+	r.NoError(err)
+	r.Equal([]change.Change{add.SetHeight(1), spend.SetHeight(3), update.SetHeight(3), spend.SetHeight(5)}, changes)
+
+	changes, err = repo.LoadChanges(id2)
+	r.NoError(err)
+	r.Equal([]change.Change{add.SetHeight(1).SetClaimID(id2)}, changes)
+
+	err = repo.DropChanges(3)
+	r.NoError(err)
+	changes, err = repo.LoadChanges(id1)
+	r.NoError(err)
+	r.Equal([]change.Change{add.SetHeight(1), spend.SetHeight(3), update.SetHeight(3)}, changes)
+
+	err = repo.DropChanges(0)
+	r.NoError(err)
+	changes, err = repo.LoadChanges(id1)
+	r.NoError(err)
+	r.Nil(changes)
+	changes, err = repo.LoadChanges(id2)
+	r.NoError(err)
+	r.Nil(changes)
+
+	// A snapshot keeps the changes as they were, and can't be written to.
+	r.NoError(repo.AppendChanges([]change.Change{add.SetHeight(6)}))
+	r.NoError(repo.SetHeight(6))
+	snap, err := repo.Snapshot()
+	r.NoError(err)
+	r.NoError(repo.AppendChanges([]change.Change{spend.SetHeight(7)}))
+	r.NoError(repo.SetHeight(7))
+	r.Error(snap.AppendChanges([]change.Change{spend.SetHeight(7)}))
+	r.Error(snap.DropChanges(0))
+	r.Error(snap.SetHeight(7))
+
+	changes, err = snap.LoadChanges(id1)
+	r.NoError(err)
+	r.Equal([]change.Change{add.SetHeight(6)}, changes)
+	height, err = snap.Height()
+	r.NoError(err)
+	r.Equal(int32(6), height)
+	r.NoError(snap.Close())
+}
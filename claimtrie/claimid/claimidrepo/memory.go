@@ -0,0 +1,99 @@
+package claimidrepo
+
+import (
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/claimid"
+)
+
+type Memory struct {
+	changes map[string][]change.Change
+	height  int32
+
+	// readOnly is set on the snapshots of the repo.
+	readOnly bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		changes: map[string][]change.Change{},
+	}
+}
+
+func (repo *Memory) AppendChanges(changes []change.Change) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for _, chg := range changes {
+		if chg.ClaimID == "" {
+			continue
+		}
+		repo.changes[chg.ClaimID] = append(repo.changes[chg.ClaimID], chg)
+	}
+
+	return nil
+}
+
+func (repo *Memory) LoadChanges(claimID string) ([]change.Change, error) {
+
+	changes := repo.changes[claimID]
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	return append([]change.Change(nil), changes...), nil
+}
+
+func (repo *Memory) DropChanges(finalHeight int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for claimID, changes := range repo.changes {
+		i := 0
+		for ; i < len(changes); i++ {
+			if changes[i].Height > finalHeight {
+				break
+			}
+		}
+		if i == 0 {
+			delete(repo.changes, claimID)
+		} else {
+			repo.changes[claimID] = changes[:i:i]
+		}
+	}
+
+	return nil
+}
+
+func (repo *Memory) Height() (int32, error) {
+	return repo.height, nil
+}
+
+func (repo *Memory) SetHeight(height int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	repo.height = height
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now.
+func (repo *Memory) Snapshot() (claimid.Repo, error) {
+
+	// The changes are only ever appended, so their slices are shared up to their length.
+	snap := &Memory{changes: make(map[string][]change.Change, len(repo.changes)), height: repo.height, readOnly: true}
+	for claimID, changes := range repo.changes {
+		snap.changes[claimID] = changes[:len(changes):len(changes)]
+	}
+
+	return snap, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,232 @@
+package claimidrepo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/claimid"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// errReadOnly is returned by the writes to a snapshot of the repo.
+var errReadOnly = errors.New("claim ID repo snapshot is read-only")
+
+// Key prefixes of the repo:
+//
+//	c + claimID + height(4B) + seq(4B) -> change
+//	h + height(4B) + claimID           -> nil, for dropping changes above a height
+//	t                                  -> height(4B)
+const (
+	prefixChange byte = 'c'
+	prefixHeight byte = 'h'
+	prefixTip    byte = 't'
+)
+
+type Pebble struct {
+	db *pebble.DB
+
+	// snap is set on the snapshots of the repo, which read from it instead of db.
+	snap *pebble.Snapshot
+}
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(64 << 20)})
+	if err != nil {
+		return nil, fmt.Errorf("pebble open %s, %w", path, err)
+	}
+
+	repo := &Pebble{db: db}
+
+	return repo, nil
+}
+
+func changeKey(claimID string, height int32, seq uint32) []byte {
+	key := make([]byte, 0, 1+len(claimID)+8)
+	key = append(key, prefixChange)
+	key = append(key, claimID...)
+	key = append(key, 0, 0, 0, 0, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(key[len(key)-8:], uint32(height))
+	binary.BigEndian.PutUint32(key[len(key)-4:], seq)
+	return key
+}
+
+func heightKey(height int32, claimID string) []byte {
+	key := make([]byte, 5, 5+len(claimID))
+	key[0] = prefixHeight
+	binary.BigEndian.PutUint32(key[1:], uint32(height))
+	return append(key, claimID...)
+}
+
+func (repo *Pebble) AppendChanges(changes []change.Change) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	for i, chg := range changes {
+		if chg.ClaimID == "" {
+			continue
+		}
+
+		value, err := msgpack.Marshal(chg)
+		if err != nil {
+			return fmt.Errorf("msgpack marshal value: %w", err)
+		}
+
+		err = batch.Set(changeKey(chg.ClaimID, chg.Height, uint32(i)), value, pebble.NoSync)
+		if err != nil {
+			return fmt.Errorf("pebble set: %w", err)
+		}
+
+		err = batch.Set(heightKey(chg.Height, chg.ClaimID), nil, pebble.NoSync)
+		if err != nil {
+			return fmt.Errorf("pebble set: %w", err)
+		}
+	}
+
+	err := batch.Commit(pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble save commit: %w", err)
+	}
+	return nil
+}
+
+func (repo *Pebble) LoadChanges(claimID string) ([]change.Change, error) {
+
+	iter := repo.reader().NewIter(&pebble.IterOptions{
+		LowerBound: changeKey(claimID, 0, 0),
+		UpperBound: changeKey(claimID, math.MaxInt32, math.MaxUint32),
+	})
+
+	var changes []change.Change
+	for iter.First(); iter.Valid(); iter.Next() {
+		var chg change.Change
+		err := msgpack.Unmarshal(iter.Value(), &chg)
+		if err != nil {
+			iter.Close()
+			return nil, fmt.Errorf("msgpack unmarshal: %w", err)
+		}
+		changes = append(changes, chg)
+	}
+
+	err := iter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("pebble get: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (repo *Pebble) DropChanges(finalHeight int32) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	iter := repo.reader().NewIter(&pebble.IterOptions{
+		LowerBound: heightKey(finalHeight+1, ""),
+		UpperBound: []byte{prefixHeight + 1},
+	})
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		height := int32(binary.BigEndian.Uint32(iter.Key()[1:5]))
+		claimID := string(iter.Key()[5:])
+
+		err := batch.DeleteRange(changeKey(claimID, height, 0), changeKey(claimID, height+1, 0), pebble.NoSync)
+		if err != nil {
+			iter.Close()
+			return fmt.Errorf("pebble delete range: %w", err)
+		}
+		err = batch.Delete(iter.Key(), pebble.NoSync)
+		if err != nil {
+			iter.Close()
+			return fmt.Errorf("pebble delete: %w", err)
+		}
+	}
+
+	err := iter.Close()
+	if err != nil {
+		return fmt.Errorf("pebble iter: %w", err)
+	}
+
+	err = batch.Commit(pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble drop commit: %w", err)
+	}
+	return nil
+}
+
+func (repo *Pebble) Height() (int32, error) {
+
+	b, closer, err := repo.reader().Get([]byte{prefixTip})
+	if err == pebble.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("pebble get: %w", err)
+	}
+	defer closer.Close()
+
+	return int32(binary.BigEndian.Uint32(b)), nil
+}
+
+func (repo *Pebble) SetHeight(height int32) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	value := make([]byte, 4)
+	binary.BigEndian.PutUint32(value, uint32(height))
+
+	return repo.db.Set([]byte{prefixTip}, value, pebble.NoSync)
+}
+
+// Snapshot returns a read-only copy of the repo as it is now, which must be closed.
+func (repo *Pebble) Snapshot() (claimid.Repo, error) {
+
+	if repo.snap != nil {
+		return nil, errReadOnly
+	}
+
+	return &Pebble{snap: repo.db.NewSnapshot()}, nil
+}
+
+// reader returns the snapshot of a snapshot repo, or the DB.
+func (repo *Pebble) reader() pebble.Reader {
+	if repo.snap != nil {
+		return repo.snap
+	}
+	return repo.db
+}
+
+func (repo *Pebble) Close() error {
+
+	if repo.snap != nil {
+		return repo.snap.Close()
+	}
+
+	err := repo.db.Flush()
+	if err != nil {
+		return fmt.Errorf("pebble flush: %w", err)
+	}
+
+	err = repo.db.Close()
+	if err != nil {
+		return fmt.Errorf("pebble close: %w", err)
+	}
+
+	return nil
+}
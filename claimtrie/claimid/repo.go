@@ -0,0 +1,32 @@
+package claimid
+
+import (
+	"github.com/btcsuite/btcd/claimtrie/change"
+)
+
+// Repo defines APIs for the claim ID index to access persistence layer.
+type Repo interface {
+	// AppendChanges saves changes of claims into the repo.
+	// The chronological order must be preserved for the same claim.
+	AppendChanges(changes []change.Change) error
+
+	// LoadChanges loads the changes of a claim, ordered by height.
+	// If no changes found, both returned slice and error will be nil.
+	LoadChanges(claimID string) ([]change.Change, error)
+
+	// DropChanges removes the changes made above finalHeight.
+	DropChanges(finalHeight int32) error
+
+	// Height returns the height up to which the changes have been saved.
+	Height() (int32, error)
+
+	// SetHeight records the height up to which the changes have been saved.
+	SetHeight(height int32) error
+
+	// Snapshot returns a read-only copy of the repo as it is now, whose writes
+	// fail. It must be closed, and can be read concurrently with the repo.
+	Snapshot() (Repo, error)
+
+	// Close closes the repo.
+	Close() error
+}
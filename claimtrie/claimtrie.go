@@ -2,29 +2,33 @@ package claimtrie
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"path/filepath"
 	"runtime"
 	"sort"
+	"sync"
 
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
 	"github.com/btcsuite/btcd/claimtrie/block"
-	"github.com/btcsuite/btcd/claimtrie/block/blockrepo"
 	"github.com/btcsuite/btcd/claimtrie/chain"
-	"github.com/btcsuite/btcd/claimtrie/chain/chainrepo"
 	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/channel"
+	"github.com/btcsuite/btcd/claimtrie/claimid"
 	"github.com/btcsuite/btcd/claimtrie/config"
 	"github.com/btcsuite/btcd/claimtrie/merkletrie"
-	"github.com/btcsuite/btcd/claimtrie/merkletrie/merkletrierepo"
 	"github.com/btcsuite/btcd/claimtrie/node"
-	"github.com/btcsuite/btcd/claimtrie/node/noderepo"
 	"github.com/btcsuite/btcd/claimtrie/param"
+	"github.com/btcsuite/btcd/claimtrie/takeover"
 	"github.com/btcsuite/btcd/claimtrie/temporal"
-	"github.com/btcsuite/btcd/claimtrie/temporal/temporalrepo"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 )
 
+// ErrClaimIDIndexDisabled is returned when a query requires the claim ID index,
+// which is not enabled.
+var ErrClaimIDIndexDisabled = errors.New("claim ID index is disabled")
+
 // ClaimTrie implements a Merkle Trie supporting linear history of commits.
 type ClaimTrie struct {
 
@@ -42,11 +46,29 @@ type ClaimTrie struct {
 	// due to stake expiration or delayed activation.
 	temporalRepo temporal.Repo
 
+	// Repository for changes of claims indexed by claim ID (optional).
+	claimIDRepo claimid.Repo
+
+	// Repository for claims indexed by channel (optional).
+	channelRepo channel.Repo
+
+	// Repository for the anomalies found while applying the changes.
+	anomalyRepo anomaly.Repo
+
+	// Repository for the takeovers of the names.
+	takeoverRepo takeover.Repo
+
+	// Repository for changes to nodes, which is owned by the Node Manager.
+	nodeRepo node.Repo
+
 	// Cache layer of Nodes.
 	nodeManager node.Manager
 
+	// Collapses the history of the nodes in the background (optional).
+	pruner *pruner
+
 	// Prefix tree (trie) that manages merkle hash of each node.
-	merkleTrie *merkletrie.MerkleTrie
+	merkleTrie merkletrie.Trie
 
 	// Current block height, which is increased by one when AppendBlock() is called.
 	height int32
@@ -55,21 +77,36 @@ type ClaimTrie struct {
 	// flushed before block is appended.
 	changes []change.Change
 
+	// Anomalies recorded for the block being appended.
+	anomalies []anomaly.Anomaly
+
+	// Channel index entries recorded for the block being appended.
+	channelEntries []channel.Entry
+
 	// Registrered cleanup functions which are invoked in the Close() in reverse order.
 	cleanups []func() error
+
+	// Serializes the creation of views with the updates of the repos by
+	// AppendBlock and ResetHeight, so the views are taken between blocks.
+	mu sync.RWMutex
 }
 
 func New(cfg config.Config) (*ClaimTrie, error) {
 
+	err := checkBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	var cleanups []func() error
 
-	blockRepo, err := blockrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.BlockRepoPebble.Path))
+	blockRepo, err := newBlockRepo(cfg, cfg.BlockRepoPebble.Path)
 	if err != nil {
 		return nil, fmt.Errorf("new block repo: %w", err)
 	}
 	cleanups = append(cleanups, blockRepo.Close)
 
-	temporalRepo, err := temporalrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.TemporalRepoPebble.Path))
+	temporalRepo, err := newTemporalRepo(cfg, cfg.TemporalRepoPebble.Path)
 	if err != nil {
 		return nil, fmt.Errorf("new temporal repo: %w", err)
 	}
@@ -77,26 +114,42 @@ func New(cfg config.Config) (*ClaimTrie, error) {
 
 	// Initialize repository for changes to nodes.
 	// The cleanup is delegated to the Node Manager.
-	nodeRepo, err := noderepo.NewPebble(filepath.Join(cfg.DataDir, cfg.NodeRepoPebble.Path))
+	nodeRepo, err := newNodeRepo(cfg, cfg.NodeRepoPebble.Path)
 	if err != nil {
 		return nil, fmt.Errorf("new node repo: %w", err)
 	}
 
-	baseManager, err := node.NewBaseManager(nodeRepo)
+	baseManager, err := node.NewBaseManager(nodeRepo, cfg.NodeCacheSize)
 	if err != nil {
 		return nil, fmt.Errorf("new node manager: %w", err)
 	}
 	nodeManager := node.NewNormalizingManager(baseManager)
 	cleanups = append(cleanups, nodeManager.Close)
 
-	// Initialize repository for MerkleTrie.
-	// The cleanup is delegated to MerkleTrie.
-	trieRepo, err := merkletrierepo.NewPebble(filepath.Join(cfg.DataDir, cfg.MerkleTrieRepoPebble.Path))
+	anomalyRepo, err := newAnomalyRepo(cfg, cfg.AnomalyRepoPebble.Path)
 	if err != nil {
-		return nil, fmt.Errorf("new trie repo: %w", err)
+		return nil, fmt.Errorf("new anomaly repo: %w", err)
 	}
+	cleanups = append(cleanups, anomalyRepo.Close)
 
-	trie := merkletrie.New(nodeManager, trieRepo)
+	takeoverRepo, err := newTakeoverRepo(cfg, cfg.TakeoverRepoPebble.Path)
+	if err != nil {
+		return nil, fmt.Errorf("new takeover repo: %w", err)
+	}
+	cleanups = append(cleanups, takeoverRepo.Close)
+
+	var trie merkletrie.Trie
+	if cfg.RamTrie {
+		trie = merkletrie.NewRamTrie(nodeManager)
+	} else {
+		// Initialize repository for MerkleTrie.
+		// The cleanup is delegated to MerkleTrie.
+		trieRepo, err := newTrieRepo(cfg, cfg.MerkleTrieRepoPebble.Path)
+		if err != nil {
+			return nil, fmt.Errorf("new trie repo: %w", err)
+		}
+		trie = merkletrie.New(nodeManager, trieRepo)
+	}
 	cleanups = append(cleanups, trie.Close)
 
 	// Restore the last height.
@@ -110,39 +163,100 @@ func New(cfg config.Config) (*ClaimTrie, error) {
 		if err != nil {
 			return nil, fmt.Errorf("get hash: %w", err)
 		}
-		trie.SetRoot(hash)
+		trie.SetRoot(hash, nil)
 
 		_, err = nodeManager.IncrementHeightTo(previousHeight)
 		if err != nil {
 			return nil, fmt.Errorf("node manager init: %w", err)
 		}
+
+		if cfg.RamTrie {
+			err = buildTrie(trie, nodeManager, hash, previousHeight)
+			if err != nil {
+				return nil, fmt.Errorf("rebuild ram trie: %w", err)
+			}
+		}
+	}
+
+	// Drop the anomalies of blocks which weren't completely appended.
+	err = anomalyRepo.Drop(previousHeight)
+	if err != nil {
+		return nil, fmt.Errorf("drop anomalies: %w", err)
+	}
+	err = takeoverRepo.Drop(previousHeight)
+	if err != nil {
+		return nil, fmt.Errorf("drop takeovers: %w", err)
 	}
 
 	ct := &ClaimTrie{
 		blockRepo:    blockRepo,
 		temporalRepo: temporalRepo,
+		anomalyRepo:  anomalyRepo,
+		takeoverRepo: takeoverRepo,
 
+		nodeRepo:    nodeRepo,
 		nodeManager: nodeManager,
 		merkleTrie:  trie,
 
 		height: previousHeight,
 	}
 
+	if cfg.ClaimIDIndex {
+		claimIDRepo, err := newClaimIDRepo(cfg, cfg.ClaimIDRepoPebble.Path)
+		if err != nil {
+			return nil, fmt.Errorf("new claim ID repo: %w", err)
+		}
+		cleanups = append(cleanups, claimIDRepo.Close)
+		ct.claimIDRepo = claimIDRepo
+
+		err = syncClaimIDIndex(claimIDRepo, nodeRepo, previousHeight)
+		if err != nil {
+			return nil, fmt.Errorf("sync claim ID index: %w", err)
+		}
+	}
+
+	if cfg.ChannelIndex {
+		channelRepo, err := newChannelRepo(cfg, cfg.ChannelRepoPebble.Path)
+		if err != nil {
+			return nil, fmt.Errorf("new channel repo: %w", err)
+		}
+		cleanups = append(cleanups, channelRepo.Close)
+		ct.channelRepo = channelRepo
+
+		// An index lagging behind is caught up by the chain.
+		indexHeight, err := channelRepo.Height()
+		if err != nil {
+			return nil, fmt.Errorf("channel repo height: %w", err)
+		}
+		if indexHeight > previousHeight {
+			err = ct.dropChannelEntries(previousHeight)
+			if err != nil {
+				return nil, fmt.Errorf("drop channel entries: %w", err)
+			}
+		}
+	}
+
 	if cfg.Record {
-		chainRepo, err := chainrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.ChainRepoPebble.Path))
+		chainRepo, err := newChainRepo(cfg, cfg.ChainRepoPebble.Path)
 		if err != nil {
 			return nil, fmt.Errorf("new change change repo: %w", err)
 		}
 		cleanups = append(cleanups, chainRepo.Close)
 		ct.chainRepo = chainRepo
 
-		reportedBlockRepo, err := blockrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.ReportedBlockRepoPebble.Path))
+		reportedBlockRepo, err := newBlockRepo(cfg, cfg.ReportedBlockRepoPebble.Path)
 		if err != nil {
 			return nil, fmt.Errorf("new reported block repo: %w", err)
 		}
 		cleanups = append(cleanups, reportedBlockRepo.Close)
 		ct.reportedBlockRepo = reportedBlockRepo
 	}
+
+	if cfg.NodePruneDepth > 0 {
+		// Stopped first, before the node manager is closed.
+		ct.pruner = newPruner(nodeManager, cfg.NodePruneDepth)
+		cleanups = append(cleanups, ct.pruner.stop)
+	}
 	ct.cleanups = cleanups
 
 	return ct, nil
@@ -222,15 +336,38 @@ func (ct *ClaimTrie) SpendSupport(name []byte, op wire.OutPoint, id node.ClaimID
 // AppendBlock increases block by one.
 func (ct *ClaimTrie) AppendBlock() error {
 
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
 	ct.height++
 
-	if len(ct.changes) > 0 && ct.chainRepo != nil {
-		err := ct.chainRepo.Save(ct.height, ct.changes)
-		if err != nil {
-			return fmt.Errorf("chain change repo save: %w", err)
+	if len(ct.changes) > 0 {
+		if ct.chainRepo != nil {
+			err := ct.chainRepo.Save(ct.height, ct.changes)
+			if err != nil {
+				return fmt.Errorf("chain change repo save: %w", err)
+			}
+		}
+		if ct.claimIDRepo != nil {
+			err := ct.claimIDRepo.AppendChanges(claimChanges(nil, ct.changes))
+			if err != nil {
+				return fmt.Errorf("claim ID repo append: %w", err)
+			}
 		}
 		ct.changes = ct.changes[:0]
 	}
+	if ct.claimIDRepo != nil {
+		err := ct.claimIDRepo.SetHeight(ct.height)
+		if err != nil {
+			return fmt.Errorf("claim ID repo set height: %w", err)
+		}
+	}
+	if ct.channelRepo != nil {
+		err := ct.CommitChannelIndex(ct.height)
+		if err != nil {
+			return fmt.Errorf("commit channel index: %w", err)
+		}
+	}
 
 	names, err := ct.nodeManager.IncrementHeightTo(ct.height)
 	if err != nil {
@@ -272,13 +409,33 @@ func (ct *ClaimTrie) AppendBlock() error {
 	hitFork := ct.updateTrieForHashForkIfNecessary()
 
 	h := ct.MerkleHash()
+
+	// The anomalies are found when the nodes are built for the Merkle Hash.
+	err = ct.saveAnomalies()
+	if err != nil {
+		return fmt.Errorf("save anomalies: %w", err)
+	}
+
+	err = ct.saveTakeovers(names)
+	if err != nil {
+		return fmt.Errorf("save takeovers: %w", err)
+	}
+
 	ct.blockRepo.Set(ct.height, h)
 
 	if hitFork {
-		ct.merkleTrie.SetRoot(h) // for clearing the memory entirely
+		ct.merkleTrie.SetRoot(h, nil) // for clearing the memory entirely
 		runtime.GC()
 	}
 
+	if ct.pruner != nil {
+		ct.pruner.notify(ct.height)
+	}
+
+	if ct.height%1000 == 0 {
+		log.Debugf("Node cache at height %d: %s", ct.height, ct.nodeManager.CacheStats())
+	}
+
 	return nil
 }
 
@@ -297,6 +454,98 @@ func (ct *ClaimTrie) updateTrieForHashForkIfNecessary() bool {
 	return true
 }
 
+// buildTrie loads all names of the node manager into the trie, and checks
+// that the resulting Merkle Hash matches the one recorded for the height.
+func buildTrie(trie merkletrie.Trie, nodeManager node.Manager, hash *chainhash.Hash, height int32) error {
+
+	log.Infof("Building merkle trie at height %d", height)
+	nodeManager.IterateNames(func(name []byte) bool {
+		trie.Update(name, false)
+		return true
+	})
+
+	var h *chainhash.Hash
+	if height >= param.AllClaimsInMerkleForkHeight {
+		h = trie.MerkleHashAllClaims()
+	} else {
+		h = trie.MerkleHash()
+	}
+	if !h.IsEqual(hash) {
+		return fmt.Errorf("merkle hash %s doesn't match %s recorded at height %d", h, hash, height)
+	}
+
+	log.Infof("Completed building merkle trie")
+	return nil
+}
+
+// syncClaimIDIndex brings the claim ID index to the height of the ClaimTrie.
+// An index that lags behind is rebuilt from the changes in the node repo.
+func syncClaimIDIndex(repo claimid.Repo, nodeRepo node.Repo, height int32) error {
+
+	indexHeight, err := repo.Height()
+	if err != nil {
+		return err
+	}
+	if indexHeight == height {
+		return nil
+	}
+	if indexHeight > height {
+		err = repo.DropChanges(height)
+		if err != nil {
+			return err
+		}
+		return repo.SetHeight(height)
+	}
+
+	log.Infof("Rebuilding claim ID index from %d to %d", indexHeight, height)
+	err = repo.DropChanges(0)
+	if err != nil {
+		return err
+	}
+
+	var changes []change.Change
+	nodeRepo.IterateAll(func(name []byte) bool {
+		var all []change.Change
+		all, err = nodeRepo.LoadChanges(name)
+		if err != nil {
+			return false
+		}
+		if len(all) > 0 && all[0].Type == change.Checkpoint {
+			err = fmt.Errorf("the history of %q is pruned", name)
+			return false
+		}
+		for i := range all {
+			if all[i].Height > height {
+				all = all[:i]
+				break
+			}
+		}
+		changes = claimChanges(changes[:0], all)
+		err = repo.AppendChanges(changes)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Completed rebuilding claim ID index")
+	return repo.SetHeight(height)
+}
+
+// claimChanges appends to dst the changes that add, update or spend a claim.
+func claimChanges(dst, changes []change.Change) []change.Change {
+	for _, chg := range changes {
+		if chg.ClaimID == "" || chg.VisibleHeight > 0 {
+			continue // generated by the normalization fork
+		}
+		switch chg.Type {
+		case change.AddClaim, change.UpdateClaim, change.SpendClaim:
+			dst = append(dst, chg)
+		}
+	}
+	return dst
+}
+
 func removeDuplicates(names [][]byte) [][]byte { // this might be too expensive; we'll have to profile it
 	sort.Slice(names, func(i, j int) bool { // put names in order so we can skip duplicates
 		return bytes.Compare(names[i], names[j]) < 0
@@ -324,6 +573,17 @@ func (ct *ClaimTrie) ReportHash(height int32, hash chainhash.Hash) error {
 // ResetHeight resets the ClaimTrie to a previous known height..
 func (ct *ClaimTrie) ResetHeight(height int32) error {
 
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	base, err := ct.blockRepo.BaseHeight()
+	if err != nil {
+		return fmt.Errorf("block repo base height: %w", err)
+	}
+	if height < base {
+		return fmt.Errorf("%w: reset to height %d, snapshot at %d", ErrBelowSnapshot, height, base)
+	}
+
 	names := make([][]byte, 0)
 	for h := height + 1; h <= ct.height; h++ {
 		results, err := ct.temporalRepo.NodesAt(h)
@@ -332,20 +592,68 @@ func (ct *ClaimTrie) ResetHeight(height int32) error {
 		}
 		names = append(names, results...)
 	}
-	err := ct.nodeManager.DecrementHeightTo(names, height)
+	err = ct.nodeManager.DecrementHeightTo(names, height)
+	if err != nil {
+		return err
+	}
+
+	ct.anomalies = ct.anomalies[:0]
+	err = ct.anomalyRepo.Drop(height)
+	if err != nil {
+		return err
+	}
+
+	err = ct.takeoverRepo.Drop(height)
+	if err != nil {
+		return err
+	}
+
+	if ct.claimIDRepo != nil {
+		err = ct.claimIDRepo.DropChanges(height)
+		if err != nil {
+			return err
+		}
+		err = ct.claimIDRepo.SetHeight(height)
+		if err != nil {
+			return err
+		}
+	}
+
+	if ct.channelRepo != nil {
+		err = ct.dropChannelEntries(height)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = ct.blockRepo.Truncate(height)
 	if err != nil {
 		return err
 	}
 
 	ct.height = height
-	hash, err := ct.blockRepo.Get(height)
+	hash, err := ct.merkleHashAt(height)
 	if err != nil {
 		return err
 	}
-	ct.merkleTrie.SetRoot(hash)
+	ct.merkleTrie.SetRoot(hash, names)
+
+	// The trie is rehashed right away, as its forks require it to be hashed.
+	if h := ct.MerkleHash(); !h.IsEqual(hash) {
+		return fmt.Errorf("merkle hash %s after the reset to height %d, expected %s", h, height, hash)
+	}
 	return nil
 }
 
+// merkleHashAt returns the Merkle Hash recorded for a previous height.
+// Nothing is recorded for the genesis block, whose ClaimTrie is empty.
+func (ct *ClaimTrie) merkleHashAt(height int32) (*chainhash.Hash, error) {
+	if height == 0 {
+		return merkletrie.EmptyTrieHash, nil
+	}
+	return ct.blockRepo.Get(height)
+}
+
 // MerkleHash returns the Merkle Hash of the claimTrie.
 func (ct *ClaimTrie) MerkleHash() *chainhash.Hash {
 	if ct.height >= param.AllClaimsInMerkleForkHeight {
@@ -391,3 +699,348 @@ func (ct *ClaimTrie) forwardNodeChange(chg change.Change) error {
 func (ct *ClaimTrie) Node(name []byte) (*node.Node, error) {
 	return ct.nodeManager.Node(name)
 }
+
+// NodeAt returns the node of the name as it was at the specified height, with
+// its claims, supports, best claim and takeover height, or nil if the name held
+// nothing. The name must be normalized if necessary at that height.
+// The node is built from the history of the name, which leaves the node manager
+// and its cache untouched; node.ErrPruned is wrapped if that history was pruned.
+func (ct *ClaimTrie) NodeAt(height int32, name []byte) (*node.Node, error) {
+
+	if height < 0 || height > ct.height {
+		return nil, fmt.Errorf("height %d is out of the range of the ClaimTrie [0, %d]", height, ct.height)
+	}
+
+	return ct.nodeManager.NodeAt(height, name)
+}
+
+// SimulateTakeover simulates a claim of the amount on the name at the next
+// height, or a support of the claim with the ID if it is not nil, without
+// altering the ClaimTrie. The hypothetical claim has a placeholder claim ID.
+// The name is normalized if necessary.
+func (ct *ClaimTrie) SimulateTakeover(name []byte, amount int64, id *node.ClaimID) (*node.Simulation, error) {
+
+	chg := change.Change{
+		Type:     change.AddClaim,
+		Name:     name,
+		Height:   ct.height + 1,
+		OutPoint: wire.OutPoint{}.String(),
+		Amount:   amount,
+	}
+	if id != nil {
+		chg.Type = change.AddSupport
+		chg.ClaimID = id.String()
+	} else {
+		chg.ClaimID = node.NewClaimID(wire.OutPoint{}).String()
+	}
+
+	return ct.nodeManager.Simulate(chg)
+}
+
+// ListedName is a name listed by ListNames, along with its node.
+type ListedName struct {
+	Name []byte
+	Node *node.Node
+}
+
+// ListNames returns, in byte order, up to limit names starting with the prefix
+// which hold an active best claim at the current height. The listing resumes
+// after the cursor if it is not nil; next is the cursor of the following page,
+// or nil if there are no more names. The prefix must be normalized if necessary.
+func (ct *ClaimTrie) ListNames(prefix, cursor []byte, limit int) (names []ListedName, next []byte, err error) {
+
+	start := prefix
+	if bytes.Compare(cursor, start) > 0 {
+		start = cursor
+	}
+
+	ct.nodeRepo.IterateFrom(start, func(name []byte) bool {
+		if !bytes.HasPrefix(name, prefix) {
+			return false
+		}
+		if cursor != nil && bytes.Compare(name, cursor) <= 0 {
+			return true
+		}
+
+		// The iteration buffer of the name is reused.
+		name = append([]byte(nil), name...)
+		var n *node.Node
+		n, err = ct.nodeManager.NodeAt(ct.height, name)
+		if err != nil {
+			err = fmt.Errorf("node at %q: %w", name, err)
+			return false
+		}
+		if n == nil || n.BestClaim == nil || n.BestClaim.Status != node.Activated {
+			return true
+		}
+		if len(names) == limit {
+			next = names[limit-1].Name
+			return false
+		}
+		names = append(names, ListedName{Name: name, Node: n})
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return names, next, nil
+}
+
+// ScheduledClaim is a claim or support listed by ClaimsExpiring or
+// PendingActivations, along with the name it belongs to.
+type ScheduledClaim struct {
+	Name    []byte
+	Claim   *node.Claim
+	Support bool
+}
+
+// ClaimsExpiring returns the claims and supports which expire from the height
+// from to the height to, inclusively, ordered by expiration height.
+func (ct *ClaimTrie) ClaimsExpiring(from, to int32) ([]ScheduledClaim, error) {
+	return ct.scheduledClaims(from, to, func(c *node.Claim) int32 {
+		return c.ExpireAt()
+	})
+}
+
+// PendingActivations returns the claims and supports which are accepted but not
+// active yet, and activate from the height from to the height to, inclusively,
+// ordered by activation height.
+func (ct *ClaimTrie) PendingActivations(from, to int32) ([]ScheduledClaim, error) {
+	return ct.scheduledClaims(from, to, func(c *node.Claim) int32 {
+		if c.Status != node.Accepted {
+			return 0
+		}
+		return c.ActiveAt
+	})
+}
+
+// scheduledClaims returns the claims and supports for which at returns a height
+// in the range, ordered by that height. Only the names scheduled for an update
+// in the temporal repo up to the end of the range are visited, as every name is
+// scheduled at the height of its next update.
+func (ct *ClaimTrie) scheduledClaims(from, to int32, at func(c *node.Claim) int32) ([]ScheduledClaim, error) {
+
+	if from <= ct.height {
+		from = ct.height + 1
+	}
+
+	var names [][]byte
+	scheduled := map[string]bool{}
+	err := ct.temporalRepo.IterateFrom(ct.height+1, func(height int32, name []byte) bool {
+		if height > to {
+			return false
+		}
+		if !scheduled[string(name)] {
+			scheduled[string(name)] = true
+			names = append(names, name)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("temporal repo iterate: %w", err)
+	}
+
+	var claims []ScheduledClaim
+	for _, name := range names {
+		n, err := ct.nodeManager.NodeAt(ct.height, name)
+		if err != nil {
+			return nil, fmt.Errorf("node at %q: %w", name, err)
+		}
+		if n == nil {
+			continue
+		}
+		for _, c := range n.Claims {
+			if h := at(c); h >= from && h <= to {
+				claims = append(claims, ScheduledClaim{Name: name, Claim: c})
+			}
+		}
+		for _, s := range n.Supports {
+			if h := at(s); h >= from && h <= to {
+				claims = append(claims, ScheduledClaim{Name: name, Claim: s, Support: true})
+			}
+		}
+	}
+
+	sort.Slice(claims, func(i, j int) bool {
+		hi, hj := at(claims[i].Claim), at(claims[j].Claim)
+		if hi != hj {
+			return hi < hj
+		}
+		if c := bytes.Compare(claims[i].Name, claims[j].Name); c != 0 {
+			return c < 0
+		}
+		return claims[i].Claim.OutPoint.String() < claims[j].Claim.OutPoint.String()
+	})
+
+	return claims, nil
+}
+
+// NodeCacheStats returns the usage of the cache of the node manager.
+func (ct *ClaimTrie) NodeCacheStats() node.CacheStats {
+	return ct.nodeManager.CacheStats()
+}
+
+// ClaimChanges returns the changes made to the claim with the ID, ordered by height.
+// It requires the claim ID index, which is enabled by config.ClaimIDIndex.
+func (ct *ClaimTrie) ClaimChanges(id node.ClaimID) ([]change.Change, error) {
+
+	if ct.claimIDRepo == nil {
+		return nil, ErrClaimIDIndexDisabled
+	}
+	return ct.claimIDRepo.LoadChanges(id.String())
+}
+
+// NameProof returns a proof of the best claim of the name, or of its absence,
+// against the Merkle Hash at the specified height. The name is normalized if
+// necessary. The node of the name at that height is returned along with the
+// proof; it is nil if the name holds no claims.
+func (ct *ClaimTrie) NameProof(name []byte, height int32) (*merkletrie.Proof, *node.Node, error) {
+
+	if height > ct.height {
+		return nil, nil, fmt.Errorf("height %d is beyond the current height %d", height, ct.height)
+	}
+
+	// The root is recorded for the current height too, which spares the views
+	// computing it from a trie that's updated under them.
+	root, err := ct.merkleHashAt(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("block repo get: %w", err)
+	}
+
+	name = node.NormalizeIfNecessary(name, height)
+	n, err := ct.nodeManager.NodeAt(height, name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("node manager node at: %w", err)
+	}
+
+	allClaims := height >= param.AllClaimsInMerkleForkHeight
+	var claimHashes []*chainhash.Hash
+	if allClaims && n != nil {
+		claimHashes = n.ClaimHashes()
+	}
+
+	proof, err := ct.merkleTrie.Proof(root, name, allClaims, claimHashes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("merkle trie proof: %w", err)
+	}
+
+	return proof, n, nil
+}
+
+// DiffMerkleTrie compares the current Merkle Trie to another one, with the
+// specified root, persisted in repo. It requires the vertices to be persisted,
+// which the RAM trie doesn't do.
+func (ct *ClaimTrie) DiffMerkleTrie(repo merkletrie.Repo, root *chainhash.Hash) ([]merkletrie.Difference, error) {
+
+	mt, ok := ct.merkleTrie.(*merkletrie.MerkleTrie)
+	if !ok {
+		return nil, fmt.Errorf("the RAM trie can't be compared")
+	}
+
+	return mt.Diff(ct.MerkleHash(), repo, root)
+}
+
+// RecordAnomaly records an anomaly of the block being appended, which is found
+// by the caller rather than while applying the changes, such as an invalid update.
+// The height of the anomaly is set by the ClaimTrie.
+func (ct *ClaimTrie) RecordAnomaly(a anomaly.Anomaly) {
+	a.Height = ct.height + 1
+	ct.anomalies = append(ct.anomalies, a)
+}
+
+func (ct *ClaimTrie) saveAnomalies() error {
+
+	anomalies := append(ct.anomalies, ct.nodeManager.Anomalies()...)
+	ct.anomalies = ct.anomalies[:0]
+	if len(anomalies) == 0 {
+		return nil
+	}
+
+	for _, a := range anomalies {
+		log.Debugf("Anomaly: %s", a)
+	}
+
+	return ct.anomalyRepo.Save(anomalies)
+}
+
+// Anomalies returns the anomalies found from fromHeight to toHeight inclusively, ordered by height.
+func (ct *ClaimTrie) Anomalies(fromHeight, toHeight int32) ([]anomaly.Anomaly, error) {
+	return ct.anomalyRepo.Load(fromHeight, toHeight)
+}
+
+// saveTakeovers journals the takeovers of the names updated by the block being
+// appended, from the controlling claims the nodes recorded before it.
+func (ct *ClaimTrie) saveTakeovers(names [][]byte) error {
+
+	var takeovers []takeover.Takeover
+	for _, name := range names {
+
+		n, err := ct.nodeManager.Node(name)
+		if err != nil {
+			return fmt.Errorf("node of %q: %w", name, err)
+		}
+		if n == nil || n.TakenOverAt != ct.height {
+			continue
+		}
+
+		t := takeover.Takeover{Height: ct.height, Name: name}
+		t.OldClaimID, t.OldAmount = n.Overtaken()
+		if best := controllingClaim(n); best != nil {
+			t.NewClaimID = best.ClaimID
+			t.NewAmount = best.EffectiveAmount(n.Supports)
+		}
+		if t.OldClaimID == "" && t.NewClaimID == "" {
+			continue // the name holds only supports, or nothing active
+		}
+
+		log.Debugf("Takeover: %s", t)
+		takeovers = append(takeovers, t)
+	}
+
+	if len(takeovers) == 0 {
+		return nil
+	}
+
+	return ct.takeoverRepo.Save(takeovers)
+}
+
+// controllingClaim returns the activated best claim of the node, if any.
+func controllingClaim(n *node.Node) *node.Claim {
+	if n == nil || n.BestClaim == nil || n.BestClaim.Status != node.Activated {
+		return nil
+	}
+	return n.BestClaim
+}
+
+// Takeovers returns the takeovers at the height, ordered by name.
+func (ct *ClaimTrie) Takeovers(height int32) ([]takeover.Takeover, error) {
+	return ct.takeoverRepo.LoadAt(height)
+}
+
+// NameHistory returns the takeovers of the name, ordered by height. The name
+// is logged as claimed before the normalization fork, and normalized after it,
+// so the takeovers of both are returned.
+func (ct *ClaimTrie) NameHistory(name []byte) ([]takeover.Takeover, error) {
+
+	takeovers, err := ct.takeoverRepo.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	normalized := node.NormalizeIfNecessary(name, ct.height)
+	if bytes.Equal(normalized, name) {
+		return takeovers, nil
+	}
+
+	more, err := ct.takeoverRepo.Load(normalized)
+	if err != nil {
+		return nil, err
+	}
+	takeovers = append(takeovers, more...)
+	sort.SliceStable(takeovers, func(i, j int) bool {
+		return takeovers[i].Height < takeovers[j].Height
+	})
+
+	return takeovers, nil
+}
@@ -1,16 +1,30 @@
 package claimtrie
 
 import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
+	"github.com/btcsuite/btcd/claimtrie/change"
 	"github.com/btcsuite/btcd/claimtrie/config"
 	"github.com/btcsuite/btcd/claimtrie/merkletrie"
+	"github.com/btcsuite/btcd/claimtrie/metadata"
 	"github.com/btcsuite/btcd/claimtrie/node"
 	"github.com/btcsuite/btcd/claimtrie/param"
+	"github.com/btcsuite/btcd/claimtrie/snapshot"
+	"github.com/btcsuite/btcd/claimtrie/takeover"
 
+	"github.com/btcsuite/btcd/btcec"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 
+	"github.com/cockroachdb/pebble"
 	"github.com/stretchr/testify/require"
 )
 
@@ -231,3 +245,1192 @@ func verifyBestIndex(t *testing.T, ct *ClaimTrie, name string, idx uint32, claim
 		r.Equal(idx, n.BestClaim.OutPoint.Index)
 	}
 }
+
+func TestNameProof(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	param.AllClaimsInMerkleForkHeight = 3
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{1, 2, 3})
+	names := []string{"test", "tes", "test2", "other"}
+	for i, name := range names {
+		o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+		err = ct.AddClaim([]byte(name), o, node.NewClaimID(o), int64(10+i), nil)
+		r.NoError(err)
+	}
+	o := wire.OutPoint{Hash: hash, Index: 10}
+	err = ct.AddClaim([]byte("test"), o, node.NewClaimID(o), 20, nil)
+	r.NoError(err)
+
+	roots := map[int32]*chainhash.Hash{}
+	for i := 0; i < 4; i++ {
+		err = ct.AppendBlock()
+		r.NoError(err)
+		roots[ct.Height()] = ct.MerkleHash()
+	}
+
+	for height := int32(1); height <= ct.Height(); height++ {
+		for _, name := range names {
+			proof, n, err := ct.NameProof([]byte(name), height)
+			r.NoError(err)
+			r.NotNil(n)
+			r.Equal(height >= param.AllClaimsInMerkleForkHeight, proof.AllClaims)
+
+			claimHash := node.CalculateNodeHash(n.BestClaim.OutPoint, n.TakenOverAt)
+			r.NoError(merkletrie.VerifyProof(roots[height], proof, claimHash), name)
+		}
+
+		proof, n, err := ct.NameProof([]byte("te"), height)
+		r.NoError(err)
+		r.Nil(n)
+		r.NoError(merkletrie.VerifyProof(roots[height], proof, nil))
+	}
+
+	_, _, err = ct.NameProof([]byte("test"), ct.Height()+1)
+	r.Error(err)
+}
+
+func TestClaimIDIndex(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	c := cfg
+	c.ClaimIDIndex = true
+	ct, err := New(c)
+	r.NoError(err)
+
+	hash := chainhash.HashH([]byte{1, 2, 3})
+	o1 := wire.OutPoint{Hash: hash, Index: 1}
+	o2 := wire.OutPoint{Hash: hash, Index: 2}
+	id := node.NewClaimID(o1)
+
+	err = ct.AddClaim([]byte("test"), o1, id, 10, nil)
+	r.NoError(err)
+	err = ct.AddSupport([]byte("test"), nil, o2, 5, id)
+	r.NoError(err)
+	err = ct.AppendBlock()
+	r.NoError(err)
+
+	err = ct.SpendClaim([]byte("test"), o1, id)
+	r.NoError(err)
+	err = ct.UpdateClaim([]byte("test"), o2, 8, id, []byte("v2"))
+	r.NoError(err)
+	err = ct.AppendBlock()
+	r.NoError(err)
+
+	changes, err := ct.ClaimChanges(id)
+	r.NoError(err)
+	r.Len(changes, 3)
+	r.Equal(change.AddClaim, changes[0].Type)
+	r.Equal(change.SpendClaim, changes[1].Type)
+	r.Equal(change.UpdateClaim, changes[2].Type)
+	r.Equal(o2.String(), changes[2].OutPoint)
+	r.Equal(int32(2), changes[2].Height)
+
+	err = ct.ResetHeight(1)
+	r.NoError(err)
+	changes, err = ct.ClaimChanges(id)
+	r.NoError(err)
+	r.Len(changes, 1)
+	r.NoError(ct.Close())
+
+	// The index is rebuilt from the node repo when it's enabled later on.
+	err = os.RemoveAll(filepath.Join(c.DataDir, c.ClaimIDRepoPebble.Path))
+	r.NoError(err)
+	ct, err = New(c)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+	changes, err = ct.ClaimChanges(id)
+	r.NoError(err)
+	r.Len(changes, 1)
+	r.Equal(change.AddClaim, changes[0].Type)
+
+	ct2 := &ClaimTrie{}
+	_, err = ct2.ClaimChanges(id)
+	r.ErrorIs(err, ErrClaimIDIndexDisabled)
+}
+
+func TestRamTrie(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	param.AllClaimsInMerkleForkHeight = 3
+
+	persistent, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := persistent.Close()
+		r.NoError(err)
+	}()
+
+	c := cfg
+	c.RamTrie = true
+	c.DataDir = t.TempDir()
+	ram, err := New(c)
+	r.NoError(err)
+
+	hash := chainhash.HashH([]byte{1, 2, 3})
+	for i := 0; i < 6; i++ {
+		for _, ct := range []*ClaimTrie{persistent, ram} {
+			o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+			err = ct.AddClaim([]byte(fmt.Sprintf("test%d", i%3)), o, node.NewClaimID(o), int64(10+i), nil)
+			r.NoError(err)
+			if i > 1 {
+				o := wire.OutPoint{Hash: hash, Index: uint32(i - 2)}
+				err = ct.SpendClaim([]byte(fmt.Sprintf("test%d", (i-2)%3)), o, node.NewClaimID(o))
+				r.NoError(err)
+			}
+			err = ct.AppendBlock()
+			r.NoError(err)
+		}
+		r.Equal(persistent.MerkleHash()[:], ram.MerkleHash()[:])
+	}
+
+	// Roll back across the fork.
+	r.NoError(persistent.ResetHeight(2))
+	r.NoError(ram.ResetHeight(2))
+	r.Equal(persistent.MerkleHash()[:], ram.MerkleHash()[:])
+
+	// The RamTrie is rebuilt on restart.
+	r.NoError(ram.AppendBlock())
+	r.NoError(persistent.AppendBlock())
+	r.NoError(ram.Close())
+	ram, err = New(c)
+	r.NoError(err)
+	defer func() {
+		err := ram.Close()
+		r.NoError(err)
+	}()
+	r.Equal(persistent.Height(), ram.Height())
+	r.Equal(persistent.MerkleHash()[:], ram.MerkleHash()[:])
+}
+
+func TestMemoryBackend(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	c := cfg
+	c.ClaimIDIndex = true
+
+	persistent, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := persistent.Close()
+		r.NoError(err)
+	}()
+
+	c.Backend = config.MemoryBackend
+	c.Record = true
+	c.DataDir = ""
+	memory, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := memory.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{4, 5, 6})
+	for i := 0; i < 6; i++ {
+		for _, ct := range []*ClaimTrie{persistent, memory} {
+			o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+			err = ct.AddClaim([]byte(fmt.Sprintf("test%d", i%3)), o, node.NewClaimID(o), int64(10+i), nil)
+			r.NoError(err)
+			if i > 1 {
+				o := wire.OutPoint{Hash: hash, Index: uint32(i - 2)}
+				err = ct.SpendClaim([]byte(fmt.Sprintf("test%d", (i-2)%3)), o, node.NewClaimID(o))
+				r.NoError(err)
+			}
+			err = ct.AppendBlock()
+			r.NoError(err)
+		}
+		r.Equal(persistent.MerkleHash()[:], memory.MerkleHash()[:])
+	}
+
+	r.NoError(persistent.ResetHeight(3))
+	r.NoError(memory.ResetHeight(3))
+	r.Equal(persistent.MerkleHash()[:], memory.MerkleHash()[:])
+
+	id := node.NewClaimID(wire.OutPoint{Hash: hash, Index: 1})
+	expected, err := persistent.ClaimChanges(id)
+	r.NoError(err)
+	changes, err := memory.ClaimChanges(id)
+	r.NoError(err)
+	r.Equal(expected, changes)
+
+	c.Backend = "bogus"
+	_, err = New(c)
+	r.Error(err)
+}
+
+func TestSnapshot(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	appendBlocks := func(cts []*ClaimTrie, from, to int) {
+		for i := from; i < to; i++ {
+			for _, ct := range cts {
+				o := wire.OutPoint{Hash: hash, Index: uint32(i)}
+				err := ct.AddClaim([]byte(fmt.Sprintf("test%d", i%4)), o, node.NewClaimID(o), int64(10+i), nil)
+				r.NoError(err)
+				if i > 2 {
+					o := wire.OutPoint{Hash: hash, Index: uint32(i - 3)}
+					err = ct.SpendClaim([]byte(fmt.Sprintf("test%d", (i-3)%4)), o, node.NewClaimID(o))
+					r.NoError(err)
+				}
+				r.NoError(ct.AppendBlock())
+			}
+		}
+	}
+	appendBlocks([]*ClaimTrie{ct}, 0, 8)
+
+	var buf bytes.Buffer
+	hdr, err := ct.ExportSnapshot(&buf)
+	r.NoError(err)
+	r.Equal(ct.Height(), hdr.Height)
+	r.Equal(ct.MerkleHash()[:], hdr.Root[:])
+
+	// A corrupted snapshot is only detected once all its records are read,
+	// but leaves nothing behind.
+	corrupted := append([]byte(nil), buf.Bytes()...)
+	corrupted[len(corrupted)-1]++
+	c := cfg
+	c.DataDir = t.TempDir()
+	_, err = ImportSnapshot(c, bytes.NewReader(corrupted))
+	r.ErrorIs(err, snapshot.ErrChecksum)
+	for _, path := range []string{c.NodeRepoPebble.Path, c.TemporalRepoPebble.Path, c.MerkleTrieRepoPebble.Path} {
+		_, err = os.Stat(filepath.Join(c.DataDir, path))
+		r.True(os.IsNotExist(err), path)
+	}
+
+	imported, err := ImportSnapshot(c, bytes.NewReader(buf.Bytes()))
+	r.NoError(err)
+	r.Equal(hdr, imported)
+
+	_, err = ImportSnapshot(c, bytes.NewReader(buf.Bytes()))
+	r.ErrorIs(err, ErrNotEmpty)
+
+	ct2, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := ct2.Close()
+		r.NoError(err)
+	}()
+	r.Equal(ct.Height(), ct2.Height())
+	r.Equal(ct.MerkleHash()[:], ct2.MerkleHash()[:])
+
+	// Both follow the same chain afterwards, including the pending activations.
+	appendBlocks([]*ClaimTrie{ct, ct2}, 8, 12)
+	r.Equal(ct.MerkleHash()[:], ct2.MerkleHash()[:])
+	for i := 0; i < 4; i++ {
+		name := []byte(fmt.Sprintf("test%d", i))
+		n, err := ct.Node(name)
+		r.NoError(err)
+		n2, err := ct2.Node(name)
+		r.NoError(err)
+		r.Equal(n.Hash(), n2.Hash())
+	}
+
+	r.NoError(ct.ResetHeight(10))
+	r.NoError(ct2.ResetHeight(10))
+	r.Equal(ct.MerkleHash()[:], ct2.MerkleHash()[:])
+
+	// The imported ClaimTrie can be reset down to the snapshot, but not below.
+	root := *ct2.MerkleHash()
+	err = ct2.ResetHeight(hdr.Height - 1)
+	r.ErrorIs(err, ErrBelowSnapshot)
+	r.Equal(int32(10), ct2.Height())
+	r.Equal(root[:], ct2.MerkleHash()[:])
+
+	r.NoError(ct.ResetHeight(hdr.Height))
+	r.NoError(ct2.ResetHeight(hdr.Height))
+	r.Equal(hdr.Root[:], ct2.MerkleHash()[:])
+	r.NoError(ct.ResetHeight(hdr.Height - 1))
+}
+
+func TestAnomalies(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+
+	op1 := wire.OutPoint{Hash: chainhash.Hash{1}, Index: 1}
+	op2 := wire.OutPoint{Hash: chainhash.Hash{2}, Index: 1}
+	id1 := node.NewClaimID(op1)
+	id2 := node.NewClaimID(op2)
+
+	r.NoError(ct.AddClaim(b("a"), op1, id1, 10, nil))
+	r.NoError(ct.AppendBlock())
+
+	r.NoError(ct.SpendClaim(b("a"), op2, id2))
+	r.NoError(ct.SpendSupport(b("b"), op2, id2))
+	ct.RecordAnomaly(anomaly.Anomaly{Type: anomaly.InvalidUpdate, Name: b("c"), ClaimID: id2.String(), OutPoint: op2.String()})
+	r.NoError(ct.AppendBlock())
+	r.NoError(ct.AppendBlock())
+
+	expected := []anomaly.Anomaly{
+		{Type: anomaly.MissingSpentClaim, Height: 2, Name: b("a"), ClaimID: id2.String(), OutPoint: op2.String()},
+		{Type: anomaly.MissingSpentSupport, Height: 2, Name: b("b"), ClaimID: id2.String(), OutPoint: op2.String()},
+		{Type: anomaly.InvalidUpdate, Height: 2, Name: b("c"), ClaimID: id2.String(), OutPoint: op2.String()},
+	}
+	anomalies, err := ct.Anomalies(0, ct.Height())
+	r.NoError(err)
+	r.ElementsMatch(expected, anomalies)
+
+	// The journal survives restarts, and the nodes rebuilt later don't report the anomalies again.
+	r.NoError(ct.Close())
+	ct, err = New(cfg)
+	r.NoError(err)
+	defer func() {
+		err = ct.Close()
+		r.NoError(err)
+	}()
+	_, err = ct.Node(b("a"))
+	r.NoError(err)
+	r.NoError(ct.AppendBlock())
+
+	anomalies, err = ct.Anomalies(0, ct.Height())
+	r.NoError(err)
+	r.ElementsMatch(expected, anomalies)
+
+	anomalies, err = ct.Anomalies(3, ct.Height())
+	r.NoError(err)
+	r.Empty(anomalies)
+
+	// The anomalies of removed blocks are dropped.
+	r.NoError(ct.ResetHeight(1))
+	anomalies, err = ct.Anomalies(0, 10)
+	r.NoError(err)
+	r.Empty(anomalies)
+}
+
+func TestPruneNodes(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	c := cfg
+	c.Backend = config.MemoryBackend
+	c.DataDir = ""
+
+	full, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := full.Close()
+		r.NoError(err)
+	}()
+
+	c.NodePruneDepth = 100
+	pruned, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := pruned.Close()
+		r.NoError(err)
+	}()
+
+	names := [][]byte{b("prune"), b("prunea"), b("pruned")}
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	appendBlocks := func(count int) {
+		for i := 0; i < count; i++ {
+			for _, ct := range []*ClaimTrie{full, pruned} {
+				height := uint32(ct.Height())
+				name := names[height%3]
+				o := wire.OutPoint{Hash: hash, Index: height}
+				r.NoError(ct.AddClaim(name, o, node.NewClaimID(o), int64(10+height%7), nil))
+				r.NoError(ct.AddSupport(name, nil, wire.OutPoint{Hash: hash, Index: height + 1<<30}, 5, node.NewClaimID(o)))
+				if height > 6 {
+					o := wire.OutPoint{Hash: hash, Index: height - 6}
+					r.NoError(ct.SpendClaim(names[(height-6)%3], o, node.NewClaimID(o)))
+				}
+				r.NoError(ct.AppendBlock())
+			}
+			r.Equal(full.MerkleHash()[:], pruned.MerkleHash()[:], "at %d", full.Height())
+		}
+	}
+
+	// The first pass starts once the cutoff is past the normalization fork and the interval.
+	appendBlocks(pruneInterval + 100)
+
+	checkpointed := func() bool {
+		changes, err := pruned.nodeRepo.LoadChanges(names[0])
+		r.NoError(err)
+		return len(changes) > 0 && changes[0].Type == change.Checkpoint
+	}
+	r.Eventually(checkpointed, 10*time.Second, 10*time.Millisecond)
+
+	appendBlocks(50)
+
+	changes, err := pruned.nodeRepo.LoadChanges(names[0])
+	r.NoError(err)
+	r.Less(len(changes), 200) // out of about 1150
+	r.LessOrEqual(changes[0].Height, int32(pruneInterval))
+
+	// The ClaimTrie can't be reset below the checkpoints.
+	err = pruned.ResetHeight(changes[0].Height - 10)
+	r.True(errors.Is(err, node.ErrPruned))
+
+	r.NoError(full.ResetHeight(pruned.Height() - 50))
+	r.NoError(pruned.ResetHeight(pruned.Height() - 50))
+	r.Equal(full.MerkleHash()[:], pruned.MerkleHash()[:])
+
+	appendBlocks(10)
+}
+
+func TestNodeAt(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	type state struct {
+		best        string
+		takenOverAt int32
+		claims      int
+	}
+
+	// Record the state of the node after each block, as a competing claim takes over and its support is spent.
+	hash := chainhash.HashH([]byte{4, 5, 6})
+	op1 := wire.OutPoint{Hash: hash, Index: 1}
+	op2 := wire.OutPoint{Hash: hash, Index: 2}
+	op3 := wire.OutPoint{Hash: hash, Index: 3}
+	id1, id2 := node.NewClaimID(op1), node.NewClaimID(op2)
+	states := map[int32]state{}
+	for i := 0; i < 40; i++ {
+		switch i {
+		case 0:
+			r.NoError(ct.AddClaim(b("at"), op1, id1, 10, nil))
+		case 10:
+			r.NoError(ct.AddClaim(b("at"), op2, id2, 20, nil))
+		case 25:
+			r.NoError(ct.AddSupport(b("at"), nil, op3, 50, id1))
+		case 35:
+			r.NoError(ct.SpendSupport(b("at"), op3, id1))
+		}
+		r.NoError(ct.AppendBlock())
+
+		n, err := ct.Node(b("at"))
+		r.NoError(err)
+		states[ct.Height()] = state{n.BestClaim.ClaimID, n.TakenOverAt, len(n.Claims)}
+	}
+	r.NotEqual(states[5].best, states[ct.Height()].best)
+
+	stats := ct.NodeCacheStats()
+	for height, expected := range states {
+		n, err := ct.NodeAt(height, b("at"))
+		r.NoError(err)
+		r.Equal(expected, state{n.BestClaim.ClaimID, n.TakenOverAt, len(n.Claims)}, "at %d", height)
+	}
+	r.Equal(stats, ct.NodeCacheStats())
+
+	n, err := ct.NodeAt(0, b("at"))
+	r.NoError(err)
+	r.Nil(n)
+
+	_, err = ct.NodeAt(ct.Height()+1, b("at"))
+	r.Error(err)
+	_, err = ct.NodeAt(-1, b("at"))
+	r.Error(err)
+
+	anomalies, err := ct.Anomalies(0, ct.Height())
+	r.NoError(err)
+	r.Empty(anomalies)
+}
+
+func TestListNames(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{5, 6, 7})
+	op := func(i uint32) wire.OutPoint { return wire.OutPoint{Hash: hash, Index: i} }
+	for i, name := range []string{"a", "ab", "abc", "abd", "abe", "b", "spent"} {
+		r.NoError(ct.AddClaim(b(name), op(uint32(i)), node.NewClaimID(op(uint32(i))), 10, nil))
+	}
+	r.NoError(ct.AppendBlock())
+	r.NoError(ct.SpendClaim(b("spent"), op(6), node.NewClaimID(op(6))))
+	r.NoError(ct.AppendBlock())
+
+	list := func(prefix, cursor string, limit int) ([]string, string) {
+		var c []byte
+		if cursor != "" {
+			c = b(cursor)
+		}
+		names, next, err := ct.ListNames(b(prefix), c, limit)
+		r.NoError(err)
+		var listed []string
+		for _, ln := range names {
+			r.Equal(node.Activated, ln.Node.BestClaim.Status)
+			listed = append(listed, string(ln.Name))
+		}
+		return listed, string(next)
+	}
+
+	names, next := list("ab", "", 2)
+	r.Equal([]string{"ab", "abc"}, names)
+	r.Equal("abc", next)
+	names, next = list("ab", next, 2)
+	r.Equal([]string{"abd", "abe"}, names)
+	r.Equal("", next)
+
+	names, next = list("", "", 10)
+	r.Equal([]string{"a", "ab", "abc", "abd", "abe", "b"}, names)
+	r.Equal("", next)
+
+	names, _ = list("", "abe", 10)
+	r.Equal([]string{"b"}, names)
+	names, _ = list("s", "", 10)
+	r.Nil(names)
+}
+
+func TestSimulateTakeover(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{6, 7, 8})
+	op := wire.OutPoint{Hash: hash, Index: 1}
+	id := node.NewClaimID(op)
+	r.NoError(ct.AddClaim(b("sim"), op, id, 10, nil))
+	for i := 0; i < 64; i++ {
+		r.NoError(ct.AppendBlock())
+	}
+	root := *ct.MerkleHash()
+
+	// A larger claim is delayed by (65 - 1) / 32 blocks, then takes over.
+	sim, err := ct.SimulateTakeover(b("sim"), 20, nil)
+	r.NoError(err)
+	r.Equal(int32(67), sim.ActiveAt)
+	r.Equal(int32(67), sim.BestAt)
+	r.Equal([]node.SimulationStep{
+		{Height: 65, BestClaimID: id.String(), BestAmount: 10},
+		{Height: 67, BestClaimID: sim.ClaimID, BestAmount: 20, EffectiveAmount: 20},
+	}, sim.Steps)
+
+	// A smaller claim only becomes best when the current one expires.
+	sim, err = ct.SimulateTakeover(b("sim"), 5, nil)
+	r.NoError(err)
+	n, err := ct.Node(b("sim"))
+	r.NoError(err)
+	r.Equal(n.BestClaim.ExpireAt(), sim.BestAt)
+	r.Equal(int64(5), sim.Steps[len(sim.Steps)-1].EffectiveAmount)
+
+	// A support of the best claim is active right away.
+	sim, err = ct.SimulateTakeover(b("sim"), 5, &id)
+	r.NoError(err)
+	r.Equal(id.String(), sim.ClaimID)
+	r.Equal(int32(65), sim.ActiveAt)
+	r.Equal(int32(65), sim.BestAt)
+	r.Equal([]node.SimulationStep{{Height: 65, BestClaimID: id.String(), BestAmount: 15, EffectiveAmount: 15}}, sim.Steps)
+
+	// A claim of a free name is active right away.
+	sim, err = ct.SimulateTakeover(b("free"), 1, nil)
+	r.NoError(err)
+	r.Equal(int32(65), sim.ActiveAt)
+	r.Equal(int32(65), sim.BestAt)
+
+	missing := node.NewClaimID(wire.OutPoint{Hash: hash, Index: 2})
+	_, err = ct.SimulateTakeover(b("sim"), 5, &missing)
+	r.True(errors.Is(err, node.ErrClaimNotFound))
+
+	// The simulations leave the ClaimTrie untouched.
+	r.Equal(root, *ct.MerkleHash())
+	n, err = ct.Node(b("sim"))
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	r.Len(n.Supports, 0)
+}
+
+func TestChannelIndex(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	c := cfg
+	c.ChannelIndex = true
+	ct, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	// The values are built by hand: a channel holding a public key, and a stream.
+	channelValue := func(key *btcec.PrivateKey) []byte {
+		der, err := hex.DecodeString("3056301006072a8648ce3d020106052b8104000a034200")
+		r.NoError(err)
+		der = append(der, key.PubKey().SerializeUncompressed()...)
+		channel := append([]byte{0x0a, byte(len(der))}, der...)
+		return append([]byte{0x00, 0x12, byte(len(channel))}, channel...)
+	}
+	stream := []byte{0x0a, 0x00}
+	signedValue := func(key *btcec.PrivateKey, channelID node.ClaimID, firstInput wire.OutPoint) []byte {
+		v := metadata.Value{Signed: true, SigningChannelID: channelID, Payload: stream}
+		sig, err := key.Sign(v.SignatureDigest(firstInput))
+		r.NoError(err)
+		value := append([]byte{0x01}, channelID[:]...)
+		value = append(value, sig.R.FillBytes(make([]byte, 32))...)
+		value = append(value, sig.S.FillBytes(make([]byte, 32))...)
+		return append(value, stream...)
+	}
+
+	key1, err := btcec.NewPrivateKey(btcec.S256())
+	r.NoError(err)
+	key2, err := btcec.NewPrivateKey(btcec.S256())
+	r.NoError(err)
+
+	hash := chainhash.HashH([]byte{7, 7, 7})
+	op := func(i uint32) wire.OutPoint { return wire.OutPoint{Hash: hash, Index: i} }
+	firstInput := op(100)
+	chanID := node.NewClaimID(op(1))
+	idA, idB, idC := node.NewClaimID(op(2)), node.NewClaimID(op(3)), node.NewClaimID(op(4))
+
+	add := func(name string, o wire.OutPoint, id node.ClaimID, value []byte) {
+		r.NoError(ct.AddClaim(b(name), o, id, 10, value))
+		ct.IndexChannelClaim(ct.Height()+1, b(name), o, id, value, firstInput)
+	}
+
+	// Block 1: the channel. Block 2: a valid claim, a claim whose signature
+	// covers another transaction, and an unsigned claim.
+	add("@chan", op(1), chanID, channelValue(key1))
+	r.NoError(ct.AppendBlock())
+	add("a", op(2), idA, signedValue(key1, chanID, firstInput))
+	add("b", op(3), idB, signedValue(key1, chanID, op(101)))
+	add("c", op(4), idC, stream)
+	r.NoError(ct.AppendBlock())
+
+	check := func(height int32, expected map[string]bool) {
+		claims, err := ct.ClaimsInChannel(chanID, height)
+		r.NoError(err)
+		actual := map[string]bool{}
+		for _, cc := range claims {
+			actual[string(cc.Name)] = cc.SignatureValid
+			valid, err := ct.ClaimSignatureValid(cc.Claim, height)
+			r.NoError(err)
+			r.Equal(cc.SignatureValid, valid)
+		}
+		r.Equal(expected, actual, "at %d", height)
+	}
+	check(1, map[string]bool{})
+	check(2, map[string]bool{"a": true, "b": false})
+
+	// Block 3: the channel changes its key, which invalidates the signatures.
+	r.NoError(ct.SpendClaim(b("@chan"), op(1), chanID))
+	r.NoError(ct.UpdateClaim(b("@chan"), op(5), 10, chanID, channelValue(key2)))
+	ct.IndexChannelClaim(ct.Height()+1, b("@chan"), op(5), chanID, channelValue(key2), firstInput)
+	r.NoError(ct.AppendBlock())
+	check(2, map[string]bool{"a": true, "b": false})
+	check(3, map[string]bool{"a": false, "b": false})
+
+	// Block 4: a spent claim leaves the channel.
+	r.NoError(ct.SpendClaim(b("a"), op(2), idA))
+	r.NoError(ct.AppendBlock())
+	check(4, map[string]bool{"b": false})
+
+	r.NoError(ct.ResetHeight(2))
+	height, err := ct.ChannelIndexHeight()
+	r.NoError(err)
+	r.Equal(int32(2), height)
+	check(2, map[string]bool{"a": true, "b": false})
+
+	_, err = ct.ClaimsInChannel(chanID, 3)
+	r.Error(err)
+
+	c.ChannelIndex = false
+	c.DataDir = t.TempDir()
+	disabled, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := disabled.Close()
+		r.NoError(err)
+	}()
+	_, err = disabled.ClaimsInChannel(chanID, 0)
+	r.Equal(ErrChannelIndexDisabled, err)
+}
+
+func TestScheduledClaims(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	opA := wire.OutPoint{Hash: hash, Index: 1}
+	opS := wire.OutPoint{Hash: hash, Index: 2}
+	opB := wire.OutPoint{Hash: hash, Index: 3}
+	opC := wire.OutPoint{Hash: hash, Index: 4}
+
+	r.NoError(ct.AddClaim(b("exp"), opA, node.NewClaimID(opA), 10, nil))
+	r.NoError(ct.AddSupport(b("exp"), nil, opS, 5, node.NewClaimID(opA)))
+	for i := 0; i < 64; i++ {
+		r.NoError(ct.AppendBlock())
+	}
+
+	// The larger claim is delayed by (65 - 1) / 32 blocks, while the claim of
+	// a free name is active right away.
+	r.NoError(ct.AddClaim(b("exp"), opB, node.NewClaimID(opB), 20, nil))
+	r.NoError(ct.AddClaim(b("other"), opC, node.NewClaimID(opC), 1, nil))
+	r.NoError(ct.AppendBlock())
+
+	outPoints := func(claims []ScheduledClaim) []wire.OutPoint {
+		var ops []wire.OutPoint
+		for _, c := range claims {
+			ops = append(ops, c.Claim.OutPoint)
+		}
+		return ops
+	}
+
+	claims, err := ct.PendingActivations(0, 100)
+	r.NoError(err)
+	r.Equal([]wire.OutPoint{opB}, outPoints(claims))
+	r.Equal(b("exp"), claims[0].Name)
+	r.Equal(int32(67), claims[0].Claim.ActiveAt)
+
+	claims, err = ct.PendingActivations(68, 100)
+	r.NoError(err)
+	r.Empty(claims)
+
+	// The claims expire 500 blocks after they are accepted, as they do so
+	// before the extended expiration fork.
+	claims, err = ct.ClaimsExpiring(0, 1000)
+	r.NoError(err)
+	r.Equal([]wire.OutPoint{opA, opS, opB, opC}, outPoints(claims))
+	r.False(claims[0].Support)
+	r.True(claims[1].Support)
+	r.Equal(int32(501), claims[1].Claim.ExpireAt())
+	r.Equal(b("other"), claims[3].Name)
+	r.Equal(int32(565), claims[3].Claim.ExpireAt())
+
+	claims, err = ct.ClaimsExpiring(0, 501)
+	r.NoError(err)
+	r.Equal([]wire.OutPoint{opA, opS}, outPoints(claims))
+
+	claims, err = ct.ClaimsExpiring(502, 1000)
+	r.NoError(err)
+	r.Equal([]wire.OutPoint{opB, opC}, outPoints(claims))
+}
+
+func TestTakeovers(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	ct, err := New(cfg)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{4, 5, 6})
+	opA := wire.OutPoint{Hash: hash, Index: 1}
+	opS := wire.OutPoint{Hash: hash, Index: 2}
+	opB := wire.OutPoint{Hash: hash, Index: 3}
+	idA, idB := node.NewClaimID(opA).String(), node.NewClaimID(opB).String()
+
+	r.NoError(ct.AddClaim(b("tk"), opA, node.NewClaimID(opA), 10, nil))
+	r.NoError(ct.AddSupport(b("sup"), nil, opS, 5, node.NewClaimID(opA)))
+	for i := 0; i < 64; i++ {
+		r.NoError(ct.AppendBlock())
+	}
+
+	// The larger claim takes over when it is activated, (65 - 1) / 32 blocks later.
+	r.NoError(ct.AddClaim(b("tk"), opB, node.NewClaimID(opB), 20, nil))
+	for i := 0; i < 3; i++ {
+		r.NoError(ct.AppendBlock())
+	}
+
+	// The name holding only a support is never taken over.
+	takeovers, err := ct.NameHistory(b("sup"))
+	r.NoError(err)
+	r.Empty(takeovers)
+
+	takeovers, err = ct.NameHistory(b("tk"))
+	r.NoError(err)
+	r.Equal([]takeover.Takeover{
+		{Height: 1, Name: b("tk"), NewClaimID: idA, NewAmount: 10},
+		{Height: 67, Name: b("tk"), OldClaimID: idA, NewClaimID: idB, OldAmount: 10, NewAmount: 20},
+	}, takeovers)
+
+	takeovers, err = ct.Takeovers(67)
+	r.NoError(err)
+	r.Len(takeovers, 1)
+
+	// The takeover is reverted with its block.
+	r.NoError(ct.ResetHeight(66))
+	takeovers, err = ct.Takeovers(67)
+	r.NoError(err)
+	r.Empty(takeovers)
+
+	takeovers, err = ct.NameHistory(b("tk"))
+	r.NoError(err)
+	r.Len(takeovers, 1)
+
+	// The amount of the old claim is that from before the block, even if
+	// the block spends it.
+	r.NoError(ct.SpendClaim(b("tk"), opA, node.NewClaimID(opA)))
+	r.NoError(ct.AppendBlock())
+	takeovers, err = ct.Takeovers(67)
+	r.NoError(err)
+	r.Equal([]takeover.Takeover{
+		{Height: 67, Name: b("tk"), OldClaimID: idA, NewClaimID: idB, OldAmount: 10, NewAmount: 20},
+	}, takeovers)
+}
+
+func TestView(t *testing.T) {
+
+	setup(t)
+	c := cfg
+	c.ClaimIDIndex = true
+	c.ChannelIndex = true
+	testView(t, c)
+
+	c.RamTrie = true
+	c.DataDir = t.TempDir()
+	testView(t, c)
+
+	c.RamTrie = false
+	c.Backend = config.MemoryBackend
+	c.DataDir = ""
+	testView(t, c)
+}
+
+func testView(t *testing.T, c config.Config) {
+
+	r := require.New(t)
+
+	ct, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	hash := chainhash.HashH([]byte{7, 8, 9})
+	op1 := wire.OutPoint{Hash: hash, Index: 1}
+	op2 := wire.OutPoint{Hash: hash, Index: 2}
+	op3 := wire.OutPoint{Hash: hash, Index: 3}
+	id1 := node.NewClaimID(op1)
+
+	r.NoError(ct.AddClaim(b("view"), op1, id1, 10, nil))
+	r.NoError(ct.AppendBlock())
+	r.NoError(ct.AppendBlock())
+	root := ct.MerkleHash()
+
+	v, err := ct.View()
+	r.NoError(err)
+	defer func() {
+		err := v.Close()
+		r.NoError(err)
+	}()
+
+	// The view is left as it was by the blocks appended and reset after it.
+	r.NoError(ct.AddClaim(b("view"), op2, node.NewClaimID(op2), 20, nil))
+	r.NoError(ct.AddClaim(b("other"), op3, node.NewClaimID(op3), 30, nil))
+	r.NoError(ct.SpendClaim(b("view"), op1, id1))
+	r.NoError(ct.AppendBlock())
+	r.NoError(ct.AppendBlock())
+	r.NoError(ct.ResetHeight(3))
+	r.NoError(ct.AppendBlock())
+	r.NotEqual(root, ct.MerkleHash())
+
+	r.Equal(int32(2), v.Height())
+	h, err := v.MerkleHash()
+	r.NoError(err)
+	r.Equal(root, h)
+
+	n, err := v.NodeAt(2, b("view"))
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	r.Equal(id1.String(), n.BestClaim.ClaimID)
+	_, err = v.NodeAt(3, b("view"))
+	r.Error(err)
+
+	names, next, err := v.ListNames(nil, nil, 10)
+	r.NoError(err)
+	r.Nil(next)
+	r.Len(names, 1)
+	r.Equal(b("view"), names[0].Name)
+
+	changes, err := v.ClaimChanges(id1)
+	r.NoError(err)
+	r.Len(changes, 1)
+
+	takeovers, err := v.NameHistory(b("view"))
+	r.NoError(err)
+	r.Len(takeovers, 1)
+
+	sim, err := v.SimulateTakeover(b("view"), 5, nil)
+	r.NoError(err)
+	r.Equal(int32(3), sim.Steps[0].Height)
+
+	proof, n, err := v.NameProof(b("view"), 2)
+	if c.RamTrie {
+		r.ErrorIs(err, ErrNoViewProof)
+		return
+	}
+	r.NoError(err)
+	claimHash := node.CalculateNodeHash(n.BestClaim.OutPoint, n.TakenOverAt)
+	r.NoError(merkletrie.VerifyProof(root, proof, claimHash))
+}
+
+// TestViewConcurrency queries views from several goroutines while blocks are
+// appended and reset, which the race detector checks.
+func TestViewConcurrency(t *testing.T) {
+
+	r := require.New(t)
+
+	setup(t)
+	c := cfg
+	c.ClaimIDIndex = true
+	c.ChannelIndex = true
+	ct, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	// Each goroutine checks the views against themselves, as they change under it.
+	query := func() error {
+		v, err := ct.View()
+		if err != nil {
+			return err
+		}
+		defer v.Close()
+
+		height := v.Height()
+		root, err := v.MerkleHash()
+		if err != nil {
+			return err
+		}
+		names, _, err := v.ListNames(b("race"), nil, 100)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			proof, n, err := v.NameProof(name.Name, height)
+			if err != nil {
+				return err
+			}
+			claimHash := node.CalculateNodeHash(n.BestClaim.OutPoint, n.TakenOverAt)
+			err = merkletrie.VerifyProof(root, proof, claimHash)
+			if err != nil {
+				return fmt.Errorf("name %q at height %d: %w", name.Name, height, err)
+			}
+			// The claims are never updated, so their IDs derive from their outpoints.
+			changes, err := v.ClaimChanges(node.NewClaimID(n.BestClaim.OutPoint))
+			if err != nil {
+				return err
+			}
+			if len(changes) != 1 || changes[0].Height > height {
+				return fmt.Errorf("claim changes of %q at height %d: %v", name.Name, height, changes)
+			}
+		}
+		_, err = v.NameHistory(b("race0"))
+		return err
+	}
+
+	done := make(chan struct{})
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			for {
+				select {
+				case <-done:
+					errs <- nil
+					return
+				default:
+				}
+				if err := query(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	hash := chainhash.HashH([]byte{9, 9, 9})
+	for i := 0; i < 60; i++ {
+		op := wire.OutPoint{Hash: hash, Index: uint32(i)}
+		name := []byte(fmt.Sprintf("race%d", i%7))
+		r.NoError(ct.AddClaim(name, op, node.NewClaimID(op), int64(10+i), nil))
+		r.NoError(ct.AppendBlock())
+		if i%10 == 9 {
+			r.NoError(ct.ResetHeight(ct.Height() - 3))
+		}
+	}
+	close(done)
+
+	for i := 0; i < cap(errs); i++ {
+		r.NoError(<-errs)
+	}
+}
+
+func TestScratch(t *testing.T) {
+
+	setup(t)
+	param.NormalizedNameForkHeight = 10
+	param.AllClaimsInMerkleForkHeight = 20
+	c := cfg
+	c.Record = true
+	testScratch(t, c)
+
+	c.Record = false
+	c.RamTrie = true
+	c.DataDir = t.TempDir()
+	testScratch(t, c)
+
+	c.RamTrie = false
+	c.Backend = config.MemoryBackend
+	c.DataDir = ""
+	testScratch(t, c)
+}
+
+// testScratch appends each block past the forks to a scratch copy of the
+// ClaimTrie first, which must compute the same root and leave it untouched.
+func testScratch(t *testing.T, c config.Config) {
+
+	r := require.New(t)
+
+	ct, err := New(c)
+	r.NoError(err)
+	defer func() {
+		err := ct.Close()
+		r.NoError(err)
+	}()
+
+	names := []string{"Scratch", "scratch", "SCRATCH2", "other"}
+	outPoint := func(h int32, i uint32) wire.OutPoint {
+		return wire.OutPoint{Hash: chainhash.HashH([]byte(fmt.Sprint(h))), Index: i}
+	}
+	apply := func(ct *ClaimTrie, h int32) {
+		for i := uint32(0); i < 2; i++ {
+			op := outPoint(h, i)
+			name := b(names[(int(h)+int(i))%len(names)])
+			r.NoError(ct.AddClaim(name, op, node.NewClaimID(op), int64(h%13)+1, nil))
+		}
+		if h > 1 {
+			op := outPoint(h, 2)
+			name := b(names[int(h)%len(names)])
+			r.NoError(ct.AddSupport(name, nil, op, 7, node.NewClaimID(outPoint(h-1, 1))))
+		}
+		if h > 5 && h%2 == 0 {
+			op := outPoint(h-5, 0)
+			name := b(names[int(h-5)%len(names)])
+			r.NoError(ct.SpendClaim(name, op, node.NewClaimID(op)))
+		}
+	}
+
+	// The reference is appended the same blocks, without scratches.
+	rc := c
+	rc.Backend = config.MemoryBackend
+	rc.RamTrie = false
+	rc.Record = false
+	ref, err := New(rc)
+	r.NoError(err)
+	defer func() {
+		err := ref.Close()
+		r.NoError(err)
+	}()
+
+	var appended int32
+	appendBlock := func(h int32) {
+		root := *ct.MerkleHash()
+
+		// A discarded scratch changes all the names, which the next one must not see.
+		st, err := ct.Scratch()
+		r.NoError(err)
+		for i, name := range names {
+			op := outPoint(h, uint32(10+i))
+			r.NoError(st.AddClaim(b(name), op, node.NewClaimID(op), 100, nil))
+		}
+		r.NoError(st.AppendBlock())
+		r.NoError(st.Close())
+
+		st, err = ct.Scratch()
+		r.NoError(err)
+		apply(st, h)
+		r.NoError(st.AppendBlock())
+		expected := *st.MerkleHash()
+		r.NoError(st.Close())
+
+		r.Equal(h-1, ct.Height())
+		r.Equal(root[:], ct.MerkleHash()[:])
+		if ct.chainRepo != nil && h > appended {
+			_, err = ct.chainRepo.Load(h)
+			r.ErrorIs(err, pebble.ErrNotFound)
+		}
+
+		apply(ct, h)
+		r.NoError(ct.AppendBlock())
+		r.Equal(expected[:], ct.MerkleHash()[:], "height %d", h)
+
+		apply(ref, h)
+		r.NoError(ref.AppendBlock())
+		r.Equal(ref.MerkleHash()[:], ct.MerkleHash()[:], "height %d", h)
+		if h > appended {
+			appended = h
+		}
+	}
+
+	for h := int32(1); h <= param.AllClaimsInMerkleForkHeight+10; h++ {
+		appendBlock(h)
+		if h%8 == 0 {
+			r.NoError(ct.ResetHeight(h - 3))
+			r.NoError(ref.ResetHeight(h - 3))
+			for redo := h - 2; redo <= h; redo++ {
+				appendBlock(redo)
+			}
+		}
+	}
+}
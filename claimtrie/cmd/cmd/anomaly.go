@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+
+	"github.com/btcsuite/btcd/claimtrie/anomaly/anomalyrepo"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(anomalyCmd)
+}
+
+var anomalyCmd = &cobra.Command{
+	Use:   "anomalies [<fromHeight> [<toHeight>]]",
+	Short: "List the anomalies found while applying the claim operations from <fromHeight> to <toHeight> inclusively",
+	Args:  cobra.RangeArgs(0, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		fromHeight, toHeight := 0, math.MaxInt32
+
+		var err error
+		if len(args) > 0 {
+			fromHeight, err = strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid args")
+			}
+		}
+		if len(args) > 1 {
+			toHeight, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid args")
+			}
+		}
+
+		repo, err := anomalyrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.AnomalyRepoPebble.Path))
+		if err != nil {
+			return fmt.Errorf("open anomaly repo: %w", err)
+		}
+		defer repo.Close()
+
+		anomalies, err := repo.Load(int32(fromHeight), int32(toHeight))
+		if err != nil {
+			return fmt.Errorf("load anomalies: %w", err)
+		}
+
+		if jsonOutput() {
+			type jsonAnomaly struct {
+				Height   int32  `json:"height"`
+				Type     string `json:"type"`
+				Name     string `json:"name"`
+				ClaimID  string `json:"claimid"`
+				OutPoint string `json:"outpoint"`
+			}
+			results := make([]jsonAnomaly, 0, len(anomalies))
+			for _, a := range anomalies {
+				results = append(results, jsonAnomaly{a.Height, a.Type.String(), string(a.Name), a.ClaimID, a.OutPoint})
+			}
+			return printJSON(results)
+		}
+
+		for _, a := range anomalies {
+			fmt.Printf("%7d: %-20s %q, ID: %s, TXO: %s\n", a.Height, a.Type, a.Name, a.ClaimID, a.OutPoint)
+		}
+
+		return nil
+	},
+}
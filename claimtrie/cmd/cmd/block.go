@@ -7,8 +7,6 @@ import (
 	"strconv"
 
 	"github.com/btcsuite/btcd/claimtrie/block/blockrepo"
-	"github.com/btcsuite/btcd/claimtrie/merkletrie"
-	"github.com/btcsuite/btcd/claimtrie/merkletrie/merkletrierepo"
 	"github.com/btcsuite/btcd/claimtrie/param"
 	"github.com/btcsuite/btcd/claimtrie/temporal/temporalrepo"
 
@@ -23,6 +21,11 @@ func init() {
 	blockCmd.AddCommand(blockNameCmd)
 }
 
+type jsonBlock struct {
+	Height int32  `json:"height"`
+	Hash   string `json:"hash"`
+}
+
 var blockCmd = &cobra.Command{
 	Use:   "block",
 	Short: "Block related commands",
@@ -48,6 +51,10 @@ var blockLastCmd = &cobra.Command{
 			return fmt.Errorf("load changes from repo: %w", err)
 		}
 
+		if jsonOutput() {
+			return printJSON(jsonBlock{last, hash.String()})
+		}
+
 		fmt.Printf("blk %-7d: %s\n", last, hash.String())
 
 		return nil
@@ -87,14 +94,23 @@ var blockListCmd = &cobra.Command{
 			toHeight = int(last)
 		}
 
+		results := []jsonBlock{}
 		for i := fromHeight; i < toHeight; i++ {
 			hash, err := repo.Get(int32(i))
 			if err != nil {
 				return fmt.Errorf("load changes from repo: %w", err)
 			}
+			if jsonOutput() {
+				results = append(results, jsonBlock{int32(i), hash.String()})
+				continue
+			}
 			fmt.Printf("blk %-7d: %s\n", i, hash.String())
 		}
 
+		if jsonOutput() {
+			return printJSON(results)
+		}
+
 		return nil
 	},
 }
@@ -105,39 +121,21 @@ var blockNameCmd = &cobra.Command{
 	Args:  cobra.RangeArgs(2, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		repo, err := blockrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.BlockRepoPebble.Path))
-		if err != nil {
-			return fmt.Errorf("can't open reported block repo: %w", err)
-		}
-		defer repo.Close()
-
 		height, err := strconv.Atoi(args[0])
 		if err != nil {
 			return fmt.Errorf("invalid args")
 		}
 
-		last, err := repo.Load()
+		trie, root, err := openMerkleTrieAt(int32(height))
 		if err != nil {
-			return fmt.Errorf("load previous height: %w", err)
-		}
-
-		if last < int32(height) {
-			return fmt.Errorf("requested height is unavailable")
-		}
-
-		hash, err := repo.Get(int32(height))
-		if err != nil {
-			return fmt.Errorf("load previous height: %w", err)
+			return err
 		}
+		defer trie.Close()
 
-		trieRepo, err := merkletrierepo.NewPebble(filepath.Join(cfg.DataDir, cfg.MerkleTrieRepoPebble.Path))
-		if err != nil {
-			return fmt.Errorf("can't open merkle trie repo: %w", err)
+		if jsonOutput() {
+			return showPath(trie, root, int32(height), []byte(args[1]))
 		}
 
-		trie := merkletrie.New(nil, trieRepo)
-		defer trie.Close()
-		trie.SetRoot(hash)
 		if len(args) > 1 {
 			trie.Dump(args[1], param.AllClaimsInMerkleForkHeight >= int32(height))
 		} else {
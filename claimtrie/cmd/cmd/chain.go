@@ -55,6 +55,7 @@ var chainDumpCmd = &cobra.Command{
 			return fmt.Errorf("open node repo: %w", err)
 		}
 
+		results := []jsonChange{}
 		for height := fromHeight; height < toHeight; height++ {
 			changes, err := chainRepo.Load(int32(height))
 			if err == pebble.ErrNotFound {
@@ -68,10 +69,18 @@ var chainDumpCmd = &cobra.Command{
 				if int(chg.Height) > height {
 					break
 				}
+				if jsonOutput() {
+					results = append(results, toJSONChange(chg))
+					continue
+				}
 				showChange(chg)
 			}
 		}
 
+		if jsonOutput() {
+			return printJSON(results)
+		}
+
 		return nil
 	},
 }
@@ -82,7 +91,9 @@ var chainReplayCmd = &cobra.Command{
 	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 
-		fmt.Printf("not working until we pass record flag to claimtrie\n")
+		if !jsonOutput() {
+			fmt.Printf("not working until we pass record flag to claimtrie\n")
+		}
 
 		fromHeight := 2
 		toHeight := int(math.MaxInt32)
@@ -100,7 +111,9 @@ var chainReplayCmd = &cobra.Command{
 			return fmt.Errorf("delete node repo: %w", err)
 		}
 
-		fmt.Printf("Deleted node repo\n")
+		if !jsonOutput() {
+			fmt.Printf("Deleted node repo\n")
+		}
 
 		chainRepo, err := chainrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.ChainRepoPebble.Path))
 		if err != nil {
@@ -133,53 +146,63 @@ var chainReplayCmd = &cobra.Command{
 				return fmt.Errorf("load from change repo: %w", err)
 			}
 
-			for _, chg := range changes {
-				claimID, _ := node.NewIDFromString(chg.ClaimID)
-
-				switch chg.Type {
-				case change.AddClaim:
-					op := *node.NewOutPointFromString(chg.OutPoint)
-					err = ct.AddClaim(chg.Name, op, claimID, chg.Amount, chg.Value)
-
-				case change.UpdateClaim:
-					op := *node.NewOutPointFromString(chg.OutPoint)
-					err = ct.UpdateClaim(chg.Name, op, chg.Amount, claimID, chg.Value)
-
-				case change.SpendClaim:
-					op := *node.NewOutPointFromString(chg.OutPoint)
-					err = ct.SpendClaim(chg.Name, op, claimID)
-
-				case change.AddSupport:
-					op := *node.NewOutPointFromString(chg.OutPoint)
-					claimID, _ := node.NewIDFromString(chg.ClaimID)
-					id := claimID
-					err = ct.AddSupport(chg.Name, chg.Value, op, chg.Amount, id)
-
-				case change.SpendSupport:
-					op := *node.NewOutPointFromString(chg.OutPoint)
-					err = ct.SpendSupport(chg.Name, op, claimID)
-
-				default:
-					err = fmt.Errorf("invalid change: %v", chg)
-				}
-
-				if err != nil {
-					return fmt.Errorf("execute change %v: %w", chg, err)
-				}
+			err = applyChanges(ct, changes)
+			if err != nil {
+				return err
 			}
 			err = appendBlock(ct, reportedBlockRepo)
 			if err != nil {
 				return err
 			}
-			if ct.Height()%1000 == 0 {
+			if ct.Height()%1000 == 0 && !jsonOutput() {
 				fmt.Printf("block: %d\n", ct.Height())
 			}
 		}
 
+		if jsonOutput() {
+			return printJSON(jsonBlock{ct.Height(), ct.MerkleHash().String()})
+		}
+
 		return nil
 	},
 }
 
+// applyChanges executes the recorded changes of a block on the ClaimTrie.
+func applyChanges(ct *claimtrie.ClaimTrie, changes []change.Change) error {
+
+	for _, chg := range changes {
+		claimID, _ := node.NewIDFromString(chg.ClaimID)
+		op := *node.NewOutPointFromString(chg.OutPoint)
+
+		var err error
+		switch chg.Type {
+		case change.AddClaim:
+			err = ct.AddClaim(chg.Name, op, claimID, chg.Amount, chg.Value)
+
+		case change.UpdateClaim:
+			err = ct.UpdateClaim(chg.Name, op, chg.Amount, claimID, chg.Value)
+
+		case change.SpendClaim:
+			err = ct.SpendClaim(chg.Name, op, claimID)
+
+		case change.AddSupport:
+			err = ct.AddSupport(chg.Name, chg.Value, op, chg.Amount, claimID)
+
+		case change.SpendSupport:
+			err = ct.SpendSupport(chg.Name, op, claimID)
+
+		default:
+			err = fmt.Errorf("invalid change: %v", chg)
+		}
+
+		if err != nil {
+			return fmt.Errorf("execute change %v: %w", chg, err)
+		}
+	}
+
+	return nil
+}
+
 func appendBlock(ct *claimtrie.ClaimTrie, blockRepo block.Repo) error {
 
 	err := ct.AppendBlock()
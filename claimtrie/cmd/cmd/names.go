@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/claimtrie/block/blockrepo"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/node/noderepo"
+
+	"github.com/spf13/cobra"
+)
+
+var namesFilter struct {
+	height    int32
+	prefix    string
+	minClaims int
+	minAmount int64
+	limit     int
+}
+
+func init() {
+	rootCmd.AddCommand(namesCmd)
+
+	namesCmd.Flags().Int32Var(&namesFilter.height, "height", 0, "Height of the nodes (default: the last block)")
+	namesCmd.Flags().StringVar(&namesFilter.prefix, "prefix", "", "List the names starting with the prefix")
+	namesCmd.Flags().IntVar(&namesFilter.minClaims, "min-claims", 0, "List the names with at least this number of claims")
+	namesCmd.Flags().Int64Var(&namesFilter.minAmount, "min-amount", 0, "List the names whose best claim has at least this effective amount")
+	namesCmd.Flags().IntVar(&namesFilter.limit, "limit", 0, "List at most this number of names (default: no limit)")
+}
+
+type jsonName struct {
+	Name            string `json:"name"`
+	BestClaimID     string `json:"bestclaimid,omitempty"`
+	EffectiveAmount int64  `json:"effectiveamount"`
+	TakeoverHeight  int32  `json:"takeoverheight"`
+	Claims          int    `json:"claims"`
+	Supports        int    `json:"supports"`
+}
+
+var namesCmd = &cobra.Command{
+	Use:   "names",
+	Short: "List the names holding claims or supports at a height, in byte order, with their best claim",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		height, err := heightOrLast(namesFilter.height)
+		if err != nil {
+			return err
+		}
+
+		listed := 0
+		results := []jsonName{}
+		err = iterateNodes(height, []byte(namesFilter.prefix), func(name []byte, n *node.Node) bool {
+
+			result := jsonName{
+				Name:           string(name),
+				TakeoverHeight: n.TakenOverAt,
+				Claims:         len(n.Claims),
+				Supports:       len(n.Supports),
+			}
+			if n.BestClaim != nil && n.BestClaim.Status == node.Activated {
+				result.BestClaimID = n.BestClaim.ClaimID
+				result.EffectiveAmount = n.BestClaim.EffectiveAmount(n.Supports)
+			}
+			if result.Claims < namesFilter.minClaims || result.EffectiveAmount < namesFilter.minAmount {
+				return true
+			}
+
+			if jsonOutput() {
+				results = append(results, result)
+			} else {
+				fmt.Printf("%q: best: %s, effective amount: %d, takeover: %d, claims: %d, supports: %d\n",
+					name, result.BestClaimID, result.EffectiveAmount, result.TakeoverHeight, result.Claims, result.Supports)
+			}
+			listed++
+			return namesFilter.limit <= 0 || listed < namesFilter.limit
+		})
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput() {
+			return printJSON(results)
+		}
+
+		return nil
+	},
+}
+
+// heightOrLast returns the height if it is set, or the height of the last block.
+func heightOrLast(height int32) (int32, error) {
+
+	if height > 0 {
+		return height, nil
+	}
+
+	repo, err := blockrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.BlockRepoPebble.Path))
+	if err != nil {
+		return 0, fmt.Errorf("open block repo: %w", err)
+	}
+	defer repo.Close()
+
+	last, err := repo.Load()
+	if err != nil {
+		return 0, fmt.Errorf("load previous height: %w", err)
+	}
+
+	return last, nil
+}
+
+// iterateNodes calls f with the node of each name starting with the prefix, as
+// of the height, in byte order, until f returns false. The names which hold no
+// claims or supports at the height are skipped.
+func iterateNodes(height int32, prefix []byte, f func(name []byte, n *node.Node) bool) error {
+
+	repo, err := noderepo.NewPebble(filepath.Join(cfg.DataDir, cfg.NodeRepoPebble.Path))
+	if err != nil {
+		return fmt.Errorf("open node repo: %w", err)
+	}
+	defer repo.Close()
+
+	nm, err := node.NewBaseManager(repo, 0)
+	if err != nil {
+		return fmt.Errorf("create node manager: %w", err)
+	}
+	if height <= 0 {
+		return nil
+	}
+	_, err = nm.IncrementHeightTo(height)
+	if err != nil {
+		return fmt.Errorf("increment height: %w", err)
+	}
+
+	var iterErr error
+	repo.IterateFrom(prefix, func(name []byte) bool {
+		if !bytes.HasPrefix(name, prefix) {
+			return false
+		}
+
+		// The iteration buffer of the name is reused.
+		name = append([]byte(nil), name...)
+		n, err := nm.NodeAt(height, name)
+		if err != nil {
+			iterErr = fmt.Errorf("node at %q: %w", name, err)
+			return false
+		}
+		if n == nil || len(n.Claims)+len(n.Supports) == 0 {
+			return true
+		}
+		return f(name, n)
+	})
+
+	return iterErr
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/hex"
 	"fmt"
 	"math"
 	"path/filepath"
@@ -17,6 +18,7 @@ func init() {
 
 	nodeCmd.AddCommand(nodeDumpCmd)
 	nodeCmd.AddCommand(nodeReplayCmd)
+	nodeCmd.AddCommand(nodeNamesCmd)
 }
 
 var nodeCmd = &cobra.Command{
@@ -50,13 +52,22 @@ var nodeDumpCmd = &cobra.Command{
 			return fmt.Errorf("load commands: %w", err)
 		}
 
+		results := []jsonChange{}
 		for _, chg := range changes {
 			if int(chg.Height) > height {
 				break
 			}
+			if jsonOutput() {
+				results = append(results, toJSONChange(chg))
+				continue
+			}
 			showChange(chg)
 		}
 
+		if jsonOutput() {
+			return printJSON(results)
+		}
+
 		return nil
 	},
 }
@@ -82,7 +93,7 @@ var nodeReplayCmd = &cobra.Command{
 			}
 		}
 
-		nm, err := node.NewBaseManager(repo)
+		nm, err := node.NewBaseManager(repo, 0)
 		if err != nil {
 			return fmt.Errorf("create node manager: %w", err)
 		}
@@ -98,7 +109,45 @@ var nodeReplayCmd = &cobra.Command{
 			return fmt.Errorf("get node: %w", err)
 		}
 
+		if jsonOutput() {
+			return printJSON(toJSONNode(name, n))
+		}
+
 		showNode(n)
 		return nil
 	},
 }
+
+var nodeNamesCmd = &cobra.Command{
+	Use:   "names",
+	Short: "List the names of all the nodes, hex encoded, one per line",
+	Long: `List the names of all the nodes, hex encoded, one per line. The output can be used
+as a corpus to compare the Go and ICU normalizations:
+
+  CLAIMTRIE_NAME_CORPUS=<file> go test -tags use_icu_normalization -run Corpus ./claimtrie/node`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		repo, err := noderepo.NewPebble(filepath.Join(cfg.DataDir, cfg.NodeRepoPebble.Path))
+		if err != nil {
+			return fmt.Errorf("open node repo: %w", err)
+		}
+		defer repo.Close()
+
+		names := []string{}
+		repo.IterateAll(func(name []byte) bool {
+			if jsonOutput() {
+				names = append(names, hex.EncodeToString(name))
+				return true
+			}
+			fmt.Println(hex.EncodeToString(name))
+			return true
+		})
+
+		if jsonOutput() {
+			return printJSON(names)
+		}
+
+		return nil
+	},
+}
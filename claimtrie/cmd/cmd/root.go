@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/btcsuite/btcd/claimtrie/config"
 	"github.com/btcsuite/btcd/claimtrie/param"
 	"github.com/btcsuite/btcd/wire"
@@ -12,12 +14,20 @@ var cfg = config.DefaultConfig
 
 func init() {
 	param.SetNetwork(wire.MainNet)
+
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", outputText, "Output format: text or json")
 }
 
 var rootCmd = &cobra.Command{
 	Use:          "claimtrie",
 	Short:        "ClaimTrie Command Line Interface",
 	SilenceUsage: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if output != outputText && output != outputJSON {
+			return fmt.Errorf("invalid output format %q", output)
+		}
+		return nil
+	},
 }
 
 func Execute() {
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/btcsuite/btcd/claimtrie"
+	"github.com/btcsuite/btcd/claimtrie/snapshot"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	snapshotCmd.AddCommand(snapshotExportCmd)
+	snapshotCmd.AddCommand(snapshotImportCmd)
+}
+
+// showSnapshot shows the header of an exported or imported snapshot.
+func showSnapshot(action string, hdr *snapshot.Header) error {
+
+	if jsonOutput() {
+		return printJSON(jsonBlock{hdr.Height, hdr.Root.String()})
+	}
+
+	fmt.Printf("%s snapshot at height %d, root %s\n", action, hdr.Height, hdr.Root)
+	return nil
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Export or import a snapshot of the ClaimTrie",
+}
+
+var snapshotExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export a snapshot of the ClaimTrie at its current height (the node must be stopped)",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		ct, err := claimtrie.New(cfg)
+		if err != nil {
+			return fmt.Errorf("open claimtrie: %w", err)
+		}
+		defer ct.Close()
+
+		f, err := os.Create(args[0])
+		if err != nil {
+			return fmt.Errorf("create snapshot: %w", err)
+		}
+		defer f.Close()
+
+		hdr, err := ct.ExportSnapshot(f)
+		if err != nil {
+			return fmt.Errorf("export snapshot: %w", err)
+		}
+
+		err = f.Sync()
+		if err != nil {
+			return fmt.Errorf("sync snapshot: %w", err)
+		}
+
+		return showSnapshot("Exported", hdr)
+	},
+}
+
+var snapshotImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import a snapshot into an empty ClaimTrie data dir",
+	Long: `Import a snapshot into an empty ClaimTrie data dir.
+
+The root of the snapshot is checked against the imported nodes here, and
+against the ClaimTrie hash of the block header when the node starts.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open snapshot: %w", err)
+		}
+		defer f.Close()
+
+		hdr, err := claimtrie.ImportSnapshot(cfg, f)
+		if err != nil {
+			return fmt.Errorf("import snapshot: %w", err)
+		}
+
+		return showSnapshot("Imported", hdr)
+	},
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/param"
+
+	"github.com/spf13/cobra"
+)
+
+var statsOptions struct {
+	height int32
+	bucket int32
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().Int32Var(&statsOptions.height, "height", 0, "Height of the nodes (default: the last block)")
+	statsCmd.Flags().Int32Var(&statsOptions.bucket, "bucket", 0, "Count the expirations by ranges of this number of heights (default: the whole range)")
+}
+
+type jsonStats struct {
+	Height         int32            `json:"height"`
+	Names          int              `json:"names"`
+	Claims         int              `json:"claims"`
+	ActiveClaims   int              `json:"activeclaims"`
+	Supports       int              `json:"supports"`
+	ActiveSupports int              `json:"activesupports"`
+	Expirations    []jsonExpiration `json:"expirations"`
+}
+
+type jsonExpiration struct {
+	FromHeight int32 `json:"fromheight"`
+	ToHeight   int32 `json:"toheight"`
+	Claims     int   `json:"claims"`
+	Supports   int   `json:"supports"`
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [<fromHeight> <toHeight>]",
+	Short: "Count the names, claims and supports at a height, and those expiring from <fromHeight> to <toHeight> inclusively",
+	Long: `Count the names, claims and supports at a height, and those expiring from
+<fromHeight> to <toHeight> inclusively, by default from the next height up to the
+expiration of the claims made at the next height.`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		height, err := heightOrLast(statsOptions.height)
+		if err != nil {
+			return err
+		}
+
+		fromHeight, toHeight := height+1, height+param.ExtendedClaimExpirationTime
+		if len(args) == 1 {
+			return fmt.Errorf("invalid args")
+		}
+		if len(args) == 2 {
+			from, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid args")
+			}
+			to, err := strconv.Atoi(args[1])
+			if err != nil || to < from {
+				return fmt.Errorf("invalid args")
+			}
+			fromHeight, toHeight = int32(from), int32(to)
+		}
+
+		bucket := statsOptions.bucket
+		if bucket <= 0 {
+			bucket = toHeight - fromHeight + 1
+		}
+
+		stats := jsonStats{Height: height}
+		for from := fromHeight; from <= toHeight && from >= fromHeight; from += bucket {
+			to := from + bucket - 1
+			if to > toHeight || to < from {
+				to = toHeight
+			}
+			stats.Expirations = append(stats.Expirations, jsonExpiration{FromHeight: from, ToHeight: to})
+		}
+		expire := func(c *node.Claim) *jsonExpiration {
+			at := c.ExpireAt()
+			if at < fromHeight || at > toHeight {
+				return nil
+			}
+			return &stats.Expirations[(at-fromHeight)/bucket]
+		}
+
+		err = iterateNodes(height, nil, func(name []byte, n *node.Node) bool {
+			stats.Names++
+			for _, c := range n.Claims {
+				stats.Claims++
+				if c.Status == node.Activated {
+					stats.ActiveClaims++
+				}
+				if e := expire(c); e != nil {
+					e.Claims++
+				}
+			}
+			for _, s := range n.Supports {
+				stats.Supports++
+				if s.Status == node.Activated {
+					stats.ActiveSupports++
+				}
+				if e := expire(s); e != nil {
+					e.Supports++
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput() {
+			return printJSON(stats)
+		}
+
+		fmt.Printf("Height: %d\n", stats.Height)
+		fmt.Printf("Names: %d\n", stats.Names)
+		fmt.Printf("Claims: %d (%d active)\n", stats.Claims, stats.ActiveClaims)
+		fmt.Printf("Supports: %d (%d active)\n", stats.Supports, stats.ActiveSupports)
+		fmt.Printf("Expirations from %d to %d:\n", fromHeight, toHeight)
+		for _, e := range stats.Expirations {
+			fmt.Printf("  %7d - %7d: claims: %d, supports: %d\n", e.FromHeight, e.ToHeight, e.Claims, e.Supports)
+		}
+
+		return nil
+	},
+}
@@ -41,6 +41,12 @@ func runListNodes(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	type jsonHeight struct {
+		Height int32    `json:"height"`
+		Names  []string `json:"names"`
+	}
+	results := []jsonHeight{}
+
 	for height := fromHeight; height < toHeight; height++ {
 		names, err := repo.NodesAt(int32(height))
 		if err != nil {
@@ -51,6 +57,15 @@ func runListNodes(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
+		if jsonOutput() {
+			result := jsonHeight{Height: int32(height)}
+			for _, name := range names {
+				result.Names = append(result.Names, string(name))
+			}
+			results = append(results, result)
+			continue
+		}
+
 		fmt.Printf("%7d: %q", height, names[0])
 		for _, name := range names[1:] {
 			fmt.Printf(", %q ", name)
@@ -58,5 +73,9 @@ func runListNodes(cmd *cobra.Command, args []string) error {
 		fmt.Printf("\n")
 	}
 
+	if jsonOutput() {
+		return printJSON(results)
+	}
+
 	return nil
 }
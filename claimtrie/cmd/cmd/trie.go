@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/block/blockrepo"
+	"github.com/btcsuite/btcd/claimtrie/merkletrie"
+	"github.com/btcsuite/btcd/claimtrie/merkletrie/merkletrierepo"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(trieCmd)
+
+	trieCmd.AddCommand(trieVertexCmd)
+}
+
+var trieCmd = &cobra.Command{
+	Use:   "trie",
+	Short: "Merkle Trie related commands",
+}
+
+var trieVertexCmd = &cobra.Command{
+	Use:   "vertex <height> <name>",
+	Short: "Show the vertices on the path of name in the Merkle Trie of block at height, with their child hashes",
+	Long: `Show the vertices on the path of name in the Merkle Trie of block at height, from
+the root to the vertex of the name, or to its deepest existing ancestor if the name
+isn't in the trie. Each vertex is listed with all of its child hashes, which makes
+the path a proof of the name.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		height, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid args")
+		}
+
+		trie, root, err := openMerkleTrieAt(int32(height))
+		if err != nil {
+			return err
+		}
+		defer trie.Close()
+
+		return showPath(trie, root, int32(height), []byte(args[1]))
+	},
+}
+
+// openMerkleTrieAt opens the Merkle Trie of the data dir, with the root it had at the height.
+func openMerkleTrieAt(height int32) (*merkletrie.MerkleTrie, *chainhash.Hash, error) {
+
+	repo, err := blockrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.BlockRepoPebble.Path))
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't open block repo: %w", err)
+	}
+	defer repo.Close()
+
+	last, err := repo.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("load previous height: %w", err)
+	}
+
+	if last < height {
+		return nil, nil, fmt.Errorf("requested height is unavailable")
+	}
+
+	hash, err := repo.Get(height)
+	if err != nil {
+		return nil, nil, fmt.Errorf("load previous height: %w", err)
+	}
+
+	trieRepo, err := merkletrierepo.NewPebble(filepath.Join(cfg.DataDir, cfg.MerkleTrieRepoPebble.Path))
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't open merkle trie repo: %w", err)
+	}
+
+	trie := merkletrie.New(nil, trieRepo)
+	trie.SetRoot(hash, nil)
+
+	return trie, hash, nil
+}
+
+type jsonChild struct {
+	Char string `json:"char"`
+	Hash string `json:"hash"`
+}
+
+type jsonVertex struct {
+	Key       string      `json:"key"`
+	Hash      string      `json:"hash"`
+	ValueHash string      `json:"valuehash,omitempty"`
+	Children  []jsonChild `json:"children"`
+}
+
+type jsonPath struct {
+	Height int32        `json:"height"`
+	Root   string       `json:"root"`
+	Name   string       `json:"name"`
+	Found  bool         `json:"found"`
+	Path   []jsonVertex `json:"path"`
+}
+
+// showPath shows the vertices on the path of the name in the trie with the root.
+func showPath(trie *merkletrie.MerkleTrie, root *chainhash.Hash, height int32, name []byte) error {
+
+	path, err := trie.Path(root, name)
+	if err != nil {
+		return fmt.Errorf("load path: %w", err)
+	}
+	last := path[len(path)-1]
+	found := bytes.Equal(last.Key, name) && last.ValueHash != nil
+
+	if jsonOutput() {
+		result := jsonPath{Height: height, Root: root.String(), Name: string(name), Found: found}
+		for _, pv := range path {
+			vertex := jsonVertex{Key: string(pv.Key), Hash: pv.Hash.String(), Children: []jsonChild{}}
+			if pv.ValueHash != nil {
+				vertex.ValueHash = pv.ValueHash.String()
+			}
+			for _, c := range pv.Children {
+				vertex.Children = append(vertex.Children, jsonChild{string([]byte{c.Char}), c.Hash.String()})
+			}
+			result.Path = append(result.Path, vertex)
+		}
+		return printJSON(result)
+	}
+
+	fmt.Printf("Root at height %d: %s\n", height, root)
+	for i, pv := range path {
+		fmt.Printf("Vertex %q: hash: %s, value: %v\n", pv.Key, pv.Hash, pv.ValueHash)
+		for _, c := range pv.Children {
+			mark := " "
+			if i < len(name) && c.Char == name[i] {
+				mark = ">"
+			}
+			fmt.Printf("  %s Child %q hash: %s\n", mark, c.Char, c.Hash)
+		}
+	}
+	if !found {
+		fmt.Printf("Name %q is not in the trie\n", name)
+	}
+
+	return nil
+}
@@ -1,13 +1,24 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/metadata"
 	"github.com/btcsuite/btcd/claimtrie/node"
 )
 
+// The output formats selected by --output.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var output = outputText
+
 var status = map[node.Status]string{
 	node.Accepted:    "Accepted",
 	node.Activated:   "Activated",
@@ -26,13 +37,198 @@ func changeName(c change.ChangeType) string {
 		return "AddSupport"
 	case change.SpendSupport:
 		return "SpendSupport"
+	case change.Checkpoint:
+		return "Checkpoint"
 	}
 	return "Unknown"
 }
 
+// jsonOutput tells whether the commands print JSON rather than text.
+func jsonOutput() bool {
+	return output == outputJSON
+}
+
+// printJSON prints v as indented JSON.
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+type jsonChange struct {
+	Height   int32      `json:"height"`
+	Type     string     `json:"type"`
+	Name     string     `json:"name"`
+	ClaimID  string     `json:"claimid,omitempty"`
+	OutPoint string     `json:"outpoint,omitempty"`
+	Amount   int64      `json:"amount,omitempty"`
+	Value    *jsonValue `json:"value,omitempty"`
+}
+
+// jsonValue summarizes a claim value, as showValue does.
+type jsonValue struct {
+	Size             int      `json:"size"`
+	Error            string   `json:"error,omitempty"`
+	Type             string   `json:"type,omitempty"`
+	Title            string   `json:"title,omitempty"`
+	Legacy           bool     `json:"legacy,omitempty"`
+	SigningChannelID string   `json:"signingchannelid,omitempty"`
+	MediaType        string   `json:"mediatype,omitempty"`
+	FeeAmount        uint64   `json:"feeamount,omitempty"`
+	FeeCurrency      string   `json:"feecurrency,omitempty"`
+	ClaimIDs         []string `json:"claimids,omitempty"`
+}
+
+type jsonSupport struct {
+	ClaimID          string `json:"claimid"`
+	OutPoint         string `json:"outpoint"`
+	AcceptedHeight   int32  `json:"acceptedheight"`
+	ActiveHeight     int32  `json:"activeheight"`
+	ExpirationHeight int32  `json:"expirationheight"`
+	Status           string `json:"status"`
+	Amount           int64  `json:"amount"`
+}
+
+type jsonClaim struct {
+	jsonSupport
+	EffectiveAmount int64         `json:"effectiveamount"`
+	Best            bool          `json:"best"`
+	Value           *jsonValue    `json:"value,omitempty"`
+	Supports        []jsonSupport `json:"supports"`
+}
+
+type jsonNode struct {
+	Name           string      `json:"name,omitempty"`
+	Hash           string      `json:"hash,omitempty"`
+	TakeoverHeight int32       `json:"takeoverheight"`
+	Claims         []jsonClaim `json:"claims"`
+}
+
+func toJSONChange(chg change.Change) jsonChange {
+	result := jsonChange{
+		Height:   chg.Height,
+		Type:     changeName(chg.Type),
+		Name:     string(chg.Name),
+		ClaimID:  chg.ClaimID,
+		OutPoint: chg.OutPoint,
+		Amount:   chg.Amount,
+	}
+	if chg.Type == change.AddClaim || chg.Type == change.UpdateClaim {
+		result.Value = describeValue(chg.Value)
+	}
+	return result
+}
+
+// describeValue decodes the metadata of a claim value. It returns nil if the
+// value is empty.
+func describeValue(value []byte) *jsonValue {
+	v, err := metadata.Decode(value)
+	if err != nil {
+		if len(value) == 0 {
+			return nil
+		}
+		return &jsonValue{Size: len(value), Error: err.Error()}
+	}
+
+	m := v.Meta
+	result := &jsonValue{
+		Size:   len(value),
+		Type:   m.Type.String(),
+		Title:  m.Title,
+		Legacy: m.Legacy,
+	}
+	if v.Signed {
+		result.SigningChannelID = v.SigningChannelID.String()
+	}
+	if m.Source != nil {
+		result.MediaType = m.Source.MediaType
+	}
+	if m.Fee != nil {
+		result.FeeAmount = m.Fee.Amount
+		result.FeeCurrency = m.Fee.Currency
+	}
+	for _, id := range m.ClaimIDs {
+		result.ClaimIDs = append(result.ClaimIDs, id.String())
+	}
+	return result
+}
+
+func toJSONSupport(s *node.Claim) jsonSupport {
+	return jsonSupport{
+		ClaimID:          s.ClaimID,
+		OutPoint:         s.OutPoint.String(),
+		AcceptedHeight:   s.AcceptedAt,
+		ActiveHeight:     s.ActiveAt,
+		ExpirationHeight: s.ExpireAt(),
+		Status:           s.Status.String(),
+		Amount:           s.Amount,
+	}
+}
+
+func toJSONNode(name []byte, n *node.Node) jsonNode {
+	result := jsonNode{
+		Name:           string(name),
+		TakeoverHeight: n.TakenOverAt,
+		Claims:         []jsonClaim{},
+	}
+	if h := n.Hash(); h != nil {
+		result.Hash = h.String()
+	}
+
+	n.SortClaims()
+	for _, c := range n.Claims {
+		claim := jsonClaim{
+			jsonSupport:     toJSONSupport(c),
+			EffectiveAmount: c.EffectiveAmount(n.Supports),
+			Best:            c == n.BestClaim,
+			Value:           describeValue(c.Value),
+			Supports:        []jsonSupport{},
+		}
+		for _, s := range n.Supports {
+			if s.ClaimID == c.ClaimID {
+				claim.Supports = append(claim.Supports, toJSONSupport(s))
+			}
+		}
+		result.Claims = append(result.Claims, claim)
+	}
+	return result
+}
+
 func showChange(chg change.Change) {
 	fmt.Printf(">>> Height: %6d: %s for %04s, %d, %s\n",
 		chg.Height, changeName(chg.Type), chg.ClaimID, chg.Amount, chg.OutPoint)
+	if chg.Type == change.AddClaim || chg.Type == change.UpdateClaim {
+		showValue(chg.Value)
+	}
+}
+
+func showValue(value []byte) {
+	v := describeValue(value)
+	if v == nil {
+		return
+	}
+	if v.Error != "" {
+		fmt.Printf("    Value: %d bytes, %s\n", v.Size, v.Error)
+		return
+	}
+
+	fmt.Printf("    Meta: %s %q", v.Type, v.Title)
+	if v.Legacy {
+		fmt.Printf(", legacy")
+	}
+	if v.SigningChannelID != "" {
+		fmt.Printf(", signed by %s", v.SigningChannelID)
+	}
+	if v.MediaType != "" {
+		fmt.Printf(", %s", v.MediaType)
+	}
+	if v.FeeCurrency != "" {
+		fmt.Printf(", fee: %d %s", v.FeeAmount, v.FeeCurrency)
+	}
+	for _, id := range v.ClaimIDs {
+		fmt.Printf(", %s", id)
+	}
+	fmt.Printf("\n")
 }
 
 func showClaim(c *node.Claim, n *node.Node) {
@@ -43,6 +239,7 @@ func showClaim(c *node.Claim, n *node.Node) {
 
 	fmt.Printf("%s  C  ID: %s, TXO: %s\n   %5d/%-5d, Status: %9s, Amount: %15d, Effective Amount: %15d\n",
 		mark, c.ClaimID, c.OutPoint, c.AcceptedAt, c.ActiveAt, status[c.Status], c.Amount, c.EffectiveAmount(n.Supports))
+	showValue(c.Value)
 }
 
 func showSupport(c *node.Claim) {
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie"
+	"github.com/btcsuite/btcd/claimtrie/block/blockrepo"
+	"github.com/btcsuite/btcd/claimtrie/chain/chainrepo"
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/config"
+	"github.com/btcsuite/btcd/claimtrie/merkletrie/merkletrierepo"
+	"github.com/btcsuite/btcd/claimtrie/node"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+type jsonVerify struct {
+	Height   int32         `json:"height"` // the last verified height
+	Mismatch *jsonMismatch `json:"mismatch,omitempty"`
+}
+
+type jsonMismatch struct {
+	Height      int32            `json:"height"`
+	Reported    string           `json:"reported"`
+	Replayed    string           `json:"replayed"`
+	DataDir     string           `json:"datadir"`
+	Differences []jsonDifference `json:"differences,omitempty"`
+	Nodes       []jsonNode       `json:"nodes"`
+}
+
+type jsonDifference struct {
+	Name           string `json:"name"`
+	Hash           string `json:"hash,omitempty"`
+	OtherHash      string `json:"otherhash,omitempty"`
+	ValueHash      string `json:"valuehash,omitempty"`
+	OtherValueHash string `json:"othervaluehash,omitempty"`
+	Missing        bool   `json:"missing,omitempty"`
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [<toHeight>]",
+	Short: "Replay the recorded changes on a scratch ClaimTrie, and report where its root first differs from the reported one",
+	Long: `Replay the changes recorded with --clmtrecord on a scratch ClaimTrie in memory,
+leaving the repos of the data dir untouched. At the first height whose root differs
+from the one reported by the block, the differing vertices are listed by comparing
+the scratch Merkle Trie to the one of the data dir, or, if both computed the same
+root, the names changed at that height are shown.`,
+	Args: cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		toHeight := int32(math.MaxInt32)
+		if len(args) == 1 {
+			h, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid args")
+			}
+			toHeight = int32(h)
+		}
+
+		chainRepo, err := chainrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.ChainRepoPebble.Path))
+		if err != nil {
+			return fmt.Errorf("open change repo: %w", err)
+		}
+		defer chainRepo.Close()
+
+		reportedBlockRepo, err := blockrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.ReportedBlockRepoPebble.Path))
+		if err != nil {
+			return fmt.Errorf("open reported block repo: %w", err)
+		}
+		defer reportedBlockRepo.Close()
+
+		scratchCfg := cfg
+		scratchCfg.Backend = config.MemoryBackend
+		scratchCfg.Record = false
+		scratchCfg.RamTrie = false // the vertices are needed for the comparison
+		scratchCfg.ClaimIDIndex = false
+
+		ct, err := claimtrie.New(scratchCfg)
+		if err != nil {
+			return fmt.Errorf("create scratch claimtrie: %w", err)
+		}
+		defer ct.Close()
+
+		for height := int32(1); height <= toHeight; height++ {
+
+			reported, err := reportedBlockRepo.Get(height)
+			if err == pebble.ErrNotFound {
+				if jsonOutput() {
+					return printJSON(jsonVerify{Height: height - 1})
+				}
+				fmt.Printf("No hash reported at height %d; verified up to height %d\n", height, height-1)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("load from reported block repo: %w", err)
+			}
+
+			changes, err := chainRepo.Load(height)
+			if err != nil && err != pebble.ErrNotFound {
+				return fmt.Errorf("load from change repo: %w", err)
+			}
+
+			err = applyChanges(ct, changes)
+			if err != nil {
+				return err
+			}
+			err = ct.AppendBlock()
+			if err != nil {
+				return fmt.Errorf("append block: %w", err)
+			}
+
+			if !ct.MerkleHash().IsEqual(reported) {
+				mismatch := &jsonMismatch{Height: height, Reported: reported.String(), Replayed: ct.MerkleHash().String()}
+				if !jsonOutput() {
+					fmt.Printf("Root mismatched at height %d: reported: %s, replayed: %s\n", height, reported, ct.MerkleHash())
+				}
+				err = reportDivergence(ct, changes, mismatch)
+				if err != nil {
+					return err
+				}
+				if jsonOutput() {
+					err = printJSON(jsonVerify{Height: height - 1, Mismatch: mismatch})
+					if err != nil {
+						return err
+					}
+				}
+				return fmt.Errorf("root mismatched at height %d", height)
+			}
+
+			if height%1000 == 0 && !jsonOutput() {
+				fmt.Printf("block: %d\n", height)
+			}
+		}
+
+		if jsonOutput() {
+			return printJSON(jsonVerify{Height: toHeight})
+		}
+
+		fmt.Printf("Verified up to height %d\n", toHeight)
+
+		return nil
+	},
+}
+
+// reportDivergence compares the scratch ClaimTrie to the one of the data dir at
+// the same height, and records the outcome in the mismatch.
+func reportDivergence(ct *claimtrie.ClaimTrie, changes []change.Change, mismatch *jsonMismatch) error {
+
+	height := ct.Height()
+
+	blockRepo, err := blockrepo.NewPebble(filepath.Join(cfg.DataDir, cfg.BlockRepoPebble.Path))
+	if err != nil {
+		return fmt.Errorf("open block repo: %w", err)
+	}
+	defer blockRepo.Close()
+
+	recorded, err := blockRepo.Get(height)
+	if err != nil {
+		return fmt.Errorf("load from block repo: %w", err)
+	}
+
+	mismatch.DataDir = recorded.String()
+	if recorded.IsEqual(ct.MerkleHash()) {
+		if !jsonOutput() {
+			fmt.Printf("The data dir computed the same root. Names changed at this height:\n")
+		}
+		mismatch.Nodes = showNames(ct, changedNames(changes))
+		return nil
+	}
+
+	if !jsonOutput() {
+		fmt.Printf("The data dir computed %s. Differing vertices, replayed vs data dir:\n", recorded)
+	}
+
+	trieRepo, err := merkletrierepo.NewPebble(filepath.Join(cfg.DataDir, cfg.MerkleTrieRepoPebble.Path))
+	if err != nil {
+		return fmt.Errorf("open merkle trie repo: %w", err)
+	}
+	defer trieRepo.Close()
+
+	diffs, err := ct.DiffMerkleTrie(trieRepo, recorded)
+	if err != nil {
+		return fmt.Errorf("diff merkle trie: %w", err)
+	}
+
+	var names [][]byte
+	for _, d := range diffs {
+		mismatch.Differences = append(mismatch.Differences, jsonDifference{
+			Name:           string(d.Name),
+			Hash:           hashString(d.Hash),
+			OtherHash:      hashString(d.OtherHash),
+			ValueHash:      hashString(d.ValueHash),
+			OtherValueHash: hashString(d.OtherValueHash),
+			Missing:        d.Missing,
+		})
+		if !jsonOutput() {
+			fmt.Printf("  %s\n", d)
+		}
+		if d.Hash != nil && (d.ValueHash != nil || d.OtherHash == nil) {
+			names = append(names, d.Name)
+		}
+	}
+
+	if !jsonOutput() {
+		fmt.Printf("Replayed nodes:\n")
+	}
+	mismatch.Nodes = showNames(ct, names)
+
+	return nil
+}
+
+// hashString returns the hash as a string, or an empty string if it is nil.
+func hashString(h *chainhash.Hash) string {
+	if h == nil {
+		return ""
+	}
+	return h.String()
+}
+
+func changedNames(changes []change.Change) [][]byte {
+
+	seen := map[string]bool{}
+	var names [][]byte
+	for _, chg := range changes {
+		name := node.NormalizeIfNecessary(chg.Name, chg.Height)
+		if !seen[string(name)] {
+			seen[string(name)] = true
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// showNames shows the nodes of the names, and returns them in the JSON output format.
+func showNames(ct *claimtrie.ClaimTrie, names [][]byte) []jsonNode {
+
+	nodes := []jsonNode{}
+	for _, name := range names {
+		n, err := ct.Node(name)
+		if err != nil || n == nil {
+			if jsonOutput() {
+				nodes = append(nodes, jsonNode{Name: string(name), Claims: []jsonClaim{}})
+				continue
+			}
+			fmt.Printf("%q: no node\n", name)
+			continue
+		}
+		if jsonOutput() {
+			nodes = append(nodes, toJSONNode(name, n))
+			continue
+		}
+		fmt.Printf("%q: hash: %v\n", name, n.Hash())
+		showNode(n)
+	}
+
+	return nodes
+}
@@ -7,8 +7,14 @@ import (
 )
 
 var DefaultConfig = Config{
-	Record:  false,
-	RamTrie: false,
+	Backend:      PebbleBackend,
+	Record:       false,
+	RamTrie:      false,
+	ClaimIDIndex: false,
+	ChannelIndex: false,
+
+	NodeCacheSize:  0,
+	NodePruneDepth: 0,
 
 	DataDir: filepath.Join(btcutil.AppDataDir("chain", false), "data", "mainnet", "claim_dbs"),
 
@@ -30,12 +36,42 @@ var DefaultConfig = Config{
 	ReportedBlockRepoPebble: pebbleConfig{
 		Path: "reported_blocks_pebble_db",
 	},
+	ClaimIDRepoPebble: pebbleConfig{
+		Path: "claimid_pebble_db",
+	},
+	ChannelRepoPebble: pebbleConfig{
+		Path: "channel_pebble_db",
+	},
+	AnomalyRepoPebble: pebbleConfig{
+		Path: "anomaly_pebble_db",
+	},
+	TakeoverRepoPebble: pebbleConfig{
+		Path: "takeover_pebble_db",
+	},
 }
 
 // Config is the container of all configurations.
 type Config struct {
-	Record  bool
-	RamTrie bool
+	Backend      Backend
+	Record       bool
+	RamTrie      bool
+	ClaimIDIndex bool
+
+	// ChannelIndex maintains an index of the claims of each channel, and of
+	// the claims signed by it. As it records the first input of the claim
+	// transactions, which the ClaimTrie doesn't keep, an index lagging behind
+	// is caught up by the chain, from its blocks.
+	ChannelIndex bool
+
+	// NodeCacheSize is the number of nodes kept in memory by the node manager.
+	// Zero selects param.MaxNodeManagerCacheSize.
+	NodeCacheSize int
+
+	// NodePruneDepth is the number of blocks of node history kept by the node repo.
+	// The older changes are collapsed in the background, after which the ClaimTrie
+	// can't be reset to the heights they covered, nor the claim ID index rebuilt.
+	// It must exceed the deepest reorganization. Zero keeps the whole history.
+	NodePruneDepth int32
 
 	DataDir string
 
@@ -46,8 +82,25 @@ type Config struct {
 
 	ChainRepoPebble         pebbleConfig
 	ReportedBlockRepoPebble pebbleConfig
+
+	ClaimIDRepoPebble pebbleConfig
+	ChannelRepoPebble pebbleConfig
+
+	AnomalyRepoPebble  pebbleConfig
+	TakeoverRepoPebble pebbleConfig
 }
 
+// Backend selects where the repositories of the ClaimTrie are kept.
+type Backend string
+
+const (
+	// PebbleBackend keeps the repositories on disk, under DataDir.
+	PebbleBackend Backend = "pebble"
+
+	// MemoryBackend keeps the repositories in memory, which is lost on Close.
+	MemoryBackend Backend = "memory"
+)
+
 type pebbleConfig struct {
 	Path string
 }
@@ -0,0 +1,127 @@
+package merkletrie
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/cockroachdb/pebble"
+)
+
+// Difference describes a vertex which differs between two tries.
+// Hash and ValueHash belong to the first trie, OtherHash and OtherValueHash to
+// the second; a nil Hash means the vertex is absent from that trie.
+// ValueHash is the hash of the claims of the name, which is nil if it has none.
+type Difference struct {
+	Name []byte
+
+	Hash      *chainhash.Hash
+	OtherHash *chainhash.Hash
+
+	ValueHash      *chainhash.Hash
+	OtherValueHash *chainhash.Hash
+
+	// Missing is set when a vertex can't be resolved from its repo, so its content isn't compared.
+	Missing bool
+}
+
+func (d Difference) String() string {
+	s := fmt.Sprintf("%q: vertex %v vs %v", d.Name, d.Hash, d.OtherHash)
+	if d.Missing {
+		return s + ", missing from the repo"
+	}
+	if d.Hash != nil && d.OtherHash != nil {
+		s += fmt.Sprintf(", value %v vs %v", d.ValueHash, d.OtherValueHash)
+	}
+	return s
+}
+
+// Diff compares the trie with the specified root to another trie persisted in a Repo.
+// It returns the deepest vertices which differ: those whose values differ, and
+// the subtrees which exist on only one side. The vertices whose hashes differ
+// only because of their children are not listed.
+func (t *MerkleTrie) Diff(root *chainhash.Hash, other Repo, otherRoot *chainhash.Hash) ([]Difference, error) {
+	return Diff(t.repo, root, other, otherRoot)
+}
+
+// Diff compares the tries with the specified roots persisted in two repos.
+// See MerkleTrie.Diff for the vertices reported.
+func Diff(repo Repo, root *chainhash.Hash, other Repo, otherRoot *chainhash.Hash) ([]Difference, error) {
+	var diffs []Difference
+	err := diffVertices(nil, repo, root, other, otherRoot, &diffs)
+	return diffs, err
+}
+
+func diffVertices(prefix []byte, repo Repo, h *chainhash.Hash, other Repo, oh *chainhash.Hash, diffs *[]Difference) error {
+
+	if h.IsEqual(oh) {
+		return nil
+	}
+
+	nb, found, err := loadVertex(repo, prefix, h)
+	if err != nil {
+		return err
+	}
+	onb, ofound, err := loadVertex(other, prefix, oh)
+	if err != nil {
+		return err
+	}
+	if !found || !ofound {
+		*diffs = append(*diffs, Difference{Name: prefix, Hash: h, OtherHash: oh, Missing: true})
+		return nil
+	}
+
+	_, value := nb.hasValue()
+	_, otherValue := onb.hasValue()
+	if !value.IsEqual(otherValue) {
+		*diffs = append(*diffs, Difference{Name: prefix, Hash: h, OtherHash: oh, ValueHash: value, OtherValueHash: otherValue})
+	}
+
+	children := map[byte]*chainhash.Hash{}
+	for i := 0; i < nb.entries(); i++ {
+		ch, h := nb.entry(i)
+		children[ch] = h
+	}
+	otherChildren := map[byte]*chainhash.Hash{}
+	for i := 0; i < onb.entries(); i++ {
+		ch, h := onb.entry(i)
+		otherChildren[ch] = h
+	}
+
+	for ch := 0; ch < 256; ch++ {
+		h, oh := children[byte(ch)], otherChildren[byte(ch)]
+		if h == nil && oh == nil {
+			continue
+		}
+		name := append(append([]byte(nil), prefix...), byte(ch))
+		if h == nil || oh == nil {
+			*diffs = append(*diffs, Difference{Name: name, Hash: h, OtherHash: oh})
+			continue
+		}
+		err = diffVertices(name, repo, h, other, oh, diffs)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadVertex returns a copy of the vertex persisted with the key and hash.
+// The empty trie has no persisted vertex, and is returned as an empty one.
+func loadVertex(repo Repo, key []byte, h *chainhash.Hash) (nbuf, bool, error) {
+
+	if len(key) == 0 && h.IsEqual(EmptyTrieHash) {
+		return nil, true, nil
+	}
+
+	value, closer, err := repo.Get(append(append([]byte(nil), key...), h[:]...))
+	if err == pebble.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get vertex %q: %w", key, err)
+	}
+	defer closer.Close()
+
+	return append(nbuf(nil), value...), true, nil
+}
@@ -0,0 +1,78 @@
+package merkletrie
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+
+	r := require.New(t)
+
+	build := func(store mapStore, allClaims bool) (*MerkleTrie, *chainhash.Hash) {
+		mt := New(store, mapRepo{})
+		for name := range store {
+			mt.Update([]byte(name), false)
+		}
+		if allClaims {
+			return mt, mt.MerkleHashAllClaims()
+		}
+		return mt, mt.MerkleHash()
+	}
+
+	for _, allClaims := range []bool{false, true} {
+		store := mapStore{
+			"a":    {{1}},
+			"ab":   {{2}, {2, 1}},
+			"abc":  {{3}},
+			"b":    {{4}},
+			"test": {{5}},
+		}
+		other := mapStore{
+			"a":   {{1}},
+			"ab":  {{2, 2}},
+			"abc": {{3}},
+			"b":   {{4}},
+			"x":   {{6}},
+		}
+
+		mt, root := build(store, allClaims)
+		omt, oroot := build(other, allClaims)
+
+		diffs, err := mt.Diff(root, mt.repo, root)
+		r.NoError(err)
+		r.Empty(diffs)
+
+		diffs, err = mt.Diff(root, omt.repo, oroot)
+		r.NoError(err)
+		r.Len(diffs, 3, "all claims: %t", allClaims)
+
+		r.Equal([]byte("ab"), diffs[0].Name)
+		r.NotNil(diffs[0].ValueHash)
+		r.NotNil(diffs[0].OtherValueHash)
+		r.NotEqual(diffs[0].ValueHash, diffs[0].OtherValueHash)
+
+		r.Equal([]byte("t"), diffs[1].Name)
+		r.NotNil(diffs[1].Hash)
+		r.Nil(diffs[1].OtherHash)
+
+		r.Equal([]byte("x"), diffs[2].Name)
+		r.Nil(diffs[2].Hash)
+		r.NotNil(diffs[2].OtherHash)
+
+		// The vertices of a root unknown to the repo can't be compared.
+		diffs, err = mt.Diff(root, mapRepo{}, oroot)
+		r.NoError(err)
+		r.Len(diffs, 1)
+		r.True(diffs[0].Missing)
+
+		// Against an empty trie, the whole subtrees differ.
+		diffs, err = mt.Diff(root, mapRepo{}, EmptyTrieHash)
+		r.NoError(err)
+		r.Len(diffs, 3)
+		r.Equal([]byte("a"), diffs[0].Name)
+	}
+}
@@ -19,11 +19,30 @@ var (
 )
 
 // ValueStore enables MerkleTrie to query node values from different implementations.
+// Subtrees are hashed in parallel, so the methods must be safe for concurrent use.
 type ValueStore interface {
 	ClaimHashes(name []byte) []*chainhash.Hash
 	Hash(name []byte) *chainhash.Hash
 }
 
+// Trie maintains the Merkle Hash of the names held by a ValueStore.
+// MerkleTrie persists its vertices to a Repo, while RamTrie keeps them all in memory.
+type Trie interface {
+	// SetRoot sets the root to the specified hash, which must have been computed earlier.
+	// Names lists the names that changed since; implementations which can't resolve
+	// vertices by hash rely on it.
+	SetRoot(h *chainhash.Hash, names [][]byte)
+	Update(name []byte, restoreChildren bool)
+	MerkleHash() *chainhash.Hash
+	MerkleHashAllClaims() *chainhash.Hash
+	Proof(root *chainhash.Hash, name []byte, allClaims bool, claimHashes []*chainhash.Hash) (*Proof, error)
+	// Fork returns a trie at the current root over the store, whose updates and
+	// hashes leave this trie untouched. This trie must be hashed, and must not be
+	// updated while the fork is in use.
+	Fork(store ValueStore) Trie
+	Close() error
+}
+
 // MerkleTrie implements a 256-way prefix tree.
 type MerkleTrie struct {
 	store ValueStore
@@ -31,6 +50,10 @@ type MerkleTrie struct {
 
 	root *vertex
 	bufs *sync.Pool
+
+	// pool hashes the subtrees in parallel, while repoMtx serializes the writes to the repo.
+	pool    *workerPool
+	repoMtx sync.Mutex
 }
 
 // New returns a MerkleTrie.
@@ -45,13 +68,27 @@ func New(store ValueStore, repo Repo) *MerkleTrie {
 			},
 		},
 		root: newVertex(EmptyTrieHash),
+		pool: newWorkerPool(0),
 	}
 
 	return tr
 }
 
+// SetWorkers sets the number of goroutines hashing subtrees in parallel, which
+// defaults to the number of CPUs. With one worker, the hashes are computed sequentially.
+func (t *MerkleTrie) SetWorkers(n int) {
+	t.pool = newWorkerPool(n)
+}
+
+func (t *MerkleTrie) setVertex(key, value []byte) {
+	t.repoMtx.Lock()
+	defer t.repoMtx.Unlock()
+	t.repo.Set(key, value) // nolint : errchk
+}
+
 // SetRoot drops all resolved nodes in the MerkleTrie, and set the root with specified hash.
-func (t *MerkleTrie) SetRoot(h *chainhash.Hash) {
+// The names are not needed, as the vertices are resolved from the repo on Update.
+func (t *MerkleTrie) SetRoot(h *chainhash.Hash, names [][]byte) {
 	t.root = newVertex(h)
 }
 
@@ -130,14 +167,13 @@ func (t *MerkleTrie) merkle(prefix []byte, v *vertex) *chainhash.Hash {
 	b.Reset()
 
 	keys := keysInOrder(v)
+	hashes := t.pool.hashChildren(prefix, v, keys, t.merkle)
 
-	for _, ch := range keys {
-		child := v.childLinks[ch]
-		if child == nil {
+	for i, ch := range keys {
+		if v.childLinks[ch] == nil {
 			continue
 		}
-		p := append(prefix, ch)
-		h := t.merkle(p, child)
+		h := hashes[i]
 		if h != nil {
 			b.WriteByte(ch) // nolint : errchk
 			b.Write(h[:])   // nolint : errchk
@@ -163,7 +199,7 @@ func (t *MerkleTrie) merkle(prefix []byte, v *vertex) *chainhash.Hash {
 	if b.Len() > 0 {
 		h := chainhash.DoubleHashH(b.Bytes())
 		v.merkleHash = &h
-		t.repo.Set(append(prefix, h[:]...), b.Bytes())
+		t.setVertex(append(prefix, h[:]...), b.Bytes())
 	}
 
 	return v.merkleHash
@@ -195,14 +231,14 @@ func (t *MerkleTrie) merkleAllClaims(prefix []byte, v *vertex) *chainhash.Hash {
 	b.Reset()
 
 	keys := keysInOrder(v)
+	hashes := t.pool.hashChildren(prefix, v, keys, t.merkleAllClaims)
+
 	childHashes := make([]*chainhash.Hash, 0, len(keys))
-	for _, ch := range keys {
-		n := v.childLinks[ch]
-		if n == nil {
+	for i, ch := range keys {
+		if v.childLinks[ch] == nil {
 			continue
 		}
-		p := append(prefix, ch)
-		h := t.merkleAllClaims(p, n)
+		h := hashes[i]
 		if h != nil {
 			childHashes = append(childHashes, h)
 			b.WriteByte(ch) // nolint : errchk
@@ -240,15 +276,26 @@ func (t *MerkleTrie) merkleAllClaims(prefix []byte, v *vertex) *chainhash.Hash {
 
 		h := hashMerkleBranches(left, right)
 		v.merkleHash = h
-		t.repo.Set(append(prefix, h[:]...), b.Bytes())
+		t.setVertex(append(prefix, h[:]...), b.Bytes())
 	} else if len(childHashes) == 1 {
 		v.merkleHash = childHashes[0] // pass it up the tree
-		t.repo.Set(append(prefix, v.merkleHash[:]...), b.Bytes())
+		t.setVertex(append(prefix, v.merkleHash[:]...), b.Bytes())
 	}
 
 	return v.merkleHash
 }
 
+// Fork returns a MerkleTrie at the current root over the store, which resolves
+// the vertices from the repo of this trie, and keeps those it computes in memory.
+func (t *MerkleTrie) Fork(store ValueStore) Trie {
+
+	tr := New(store, newOverlayRepo(t.repo))
+	tr.root = newVertex(t.root.merkleHash)
+	tr.pool = t.pool
+
+	return tr
+}
+
 func (t *MerkleTrie) Close() error {
 	return t.repo.Close()
 }
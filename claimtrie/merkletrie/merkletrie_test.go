@@ -22,3 +22,66 @@ func TestName(t *testing.T) {
 	root = computeMerkleRoot(data)
 	r.True(target.IsEqual(root))
 }
+
+func TestFork(t *testing.T) {
+
+	r := require.New(t)
+
+	hash := func(tr Trie, allClaims bool) []byte {
+		if allClaims {
+			return tr.MerkleHashAllClaims()[:]
+		}
+		return tr.MerkleHash()[:]
+	}
+	// expected computes the hash with a MerkleTrie built from scratch.
+	expected := func(store mapStore, allClaims bool) []byte {
+		mt := New(store, mapRepo{})
+		for name := range store {
+			mt.Update([]byte(name), false)
+		}
+		return hash(mt, allClaims)
+	}
+
+	names := []string{"a", "ab", "abc", "abd", "b", "bcdef", "test"}
+	for _, allClaims := range []bool{false, true} {
+
+		store := mapStore{}
+		for i, name := range names {
+			store[name] = []*chainhash.Hash{{byte(i + 1)}, {byte(i + 1), 1}}
+		}
+
+		// The fork sees some of the names changed.
+		forked := mapStore{}
+		for name, hashes := range store {
+			forked[name] = hashes
+		}
+		delete(forked, "abc")
+		forked["ab"] = []*chainhash.Hash{{0xff}}
+		forked["new"] = []*chainhash.Hash{{0xfe}}
+
+		repo := mapRepo{}
+		for _, tr := range []Trie{New(store, repo), NewRamTrie(store)} {
+			for _, name := range names {
+				tr.Update([]byte(name), true)
+			}
+			root := hash(tr, allClaims)
+			vertices := len(repo)
+
+			f := tr.Fork(forked)
+			for _, name := range []string{"abc", "ab", "new"} {
+				f.Update([]byte(name), true)
+			}
+			r.Equal(expected(forked, allClaims), hash(f, allClaims))
+			if _, ok := tr.(*RamTrie); ok {
+				// Switching the scheme rehashes the whole fork.
+				r.Equal(expected(forked, !allClaims), hash(f, !allClaims))
+			}
+
+			// The trie is left untouched, so rehashing a name it shares with the fork
+			// yields its root.
+			r.Equal(vertices, len(repo))
+			tr.Update([]byte("ab"), true)
+			r.Equal(root, hash(tr, allClaims))
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package merkletrie
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// workerPool bounds the number of goroutines hashing subtrees in parallel.
+// The goroutine which fans out keeps working, so a pool of n workers holds
+// n-1 tokens, and a pool of one worker hashes sequentially.
+type workerPool struct {
+	tokens chan struct{}
+}
+
+func newWorkerPool(workers int) *workerPool {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	return &workerPool{tokens: make(chan struct{}, workers-1)}
+}
+
+// hashChildren resolves the hashes of the children of v listed in keys, and
+// returns them in the same order. The subtrees which need to be rehashed are
+// handed to idle workers; the others are hashed by the calling goroutine.
+// Children which don't exist are reported with nil hashes.
+//
+// The hash function must only modify the subtree it is given.
+func (wp *workerPool) hashChildren(prefix []byte, v *vertex, keys []byte,
+	hash func(prefix []byte, v *vertex) *chainhash.Hash) []*chainhash.Hash {
+
+	hashes := make([]*chainhash.Hash, len(keys))
+
+	var wg sync.WaitGroup
+	for i, ch := range keys {
+		child := v.childLinks[ch]
+		if child == nil {
+			continue
+		}
+		if child.merkleHash == nil && len(keys) > 1 && wp.tryAcquire() {
+			// The prefix buffer is shared by the siblings hashed sequentially.
+			p := make([]byte, len(prefix)+1, len(prefix)+64)
+			copy(p, prefix)
+			p[len(prefix)] = ch
+
+			wg.Add(1)
+			go func(i int, p []byte, child *vertex) {
+				defer wg.Done()
+				defer wp.release()
+				hashes[i] = hash(p, child)
+			}(i, p, child)
+			continue
+		}
+		hashes[i] = hash(append(prefix, ch), child)
+	}
+	wg.Wait()
+
+	return hashes
+}
+
+func (wp *workerPool) tryAcquire() bool {
+	select {
+	case wp.tokens <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (wp *workerPool) release() {
+	<-wp.tokens
+}
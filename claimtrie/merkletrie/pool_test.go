@@ -0,0 +1,99 @@
+package merkletrie
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestParallelHashing ensures hashing subtrees in parallel yields the same
+// hashes, and writes the same vertices, as the sequential algorithm.
+func TestParallelHashing(t *testing.T) {
+
+	r := require.New(t)
+
+	rnd := rand.New(rand.NewSource(42))
+	store := mapStore{}
+	randomName := func() string {
+		name := make([]byte, 1+rnd.Intn(8))
+		for i := range name {
+			name[i] = "abcdefgh"[rnd.Intn(8)] // a small alphabet to share prefixes
+		}
+		return string(name)
+	}
+	setRandomValue := func(name string) {
+		hashes := make([]*chainhash.Hash, 1+rnd.Intn(3))
+		for i := range hashes {
+			hashes[i] = &chainhash.Hash{}
+			rnd.Read(hashes[i][:])
+		}
+		store[name] = hashes
+	}
+
+	var names []string
+	for i := 0; i < 2000; i++ {
+		name := randomName()
+		names = append(names, name)
+		setRandomValue(name)
+	}
+
+	for _, allClaims := range []bool{false, true} {
+		seqRepo, parRepo := mapRepo{}, mapRepo{}
+		seq := New(store, seqRepo)
+		seq.SetWorkers(1)
+		par := New(store, parRepo)
+		par.SetWorkers(8)
+		seqRam := NewRamTrie(store)
+		seqRam.SetWorkers(1)
+		parRam := NewRamTrie(store)
+		parRam.SetWorkers(8)
+		tries := []Trie{seq, par, seqRam, parRam}
+
+		// Each sequential trie is compared to its parallel counterpart.
+		check := func(msg string) {
+			for i := 0; i < len(tries); i += 2 {
+				expected, h := tries[i].MerkleHash(), tries[i+1].MerkleHash()
+				if allClaims {
+					expected, h = tries[i].MerkleHashAllClaims(), tries[i+1].MerkleHashAllClaims()
+				}
+				r.Equal(expected[:], h[:], "%s: trie %d, all claims: %t", msg, i+1, allClaims)
+			}
+			r.Equal(seqRepo, parRepo, msg)
+		}
+
+		for _, tr := range tries {
+			for _, name := range names {
+				tr.Update([]byte(name), false)
+			}
+		}
+		check("initial")
+
+		// Update, add and remove some names, as a block would.
+		for round := 0; round < 5; round++ {
+			var changed []string
+			for i := 0; i < 200; i++ {
+				name := names[rnd.Intn(len(names))]
+				switch rnd.Intn(3) {
+				case 0:
+					delete(store, name)
+				case 1:
+					name = randomName()
+					setRandomValue(name)
+				default:
+					setRandomValue(name)
+				}
+				changed = append(changed, name)
+			}
+			for _, tr := range tries {
+				for _, name := range changed {
+					tr.Update([]byte(name), true)
+				}
+			}
+			check(fmt.Sprintf("round %d", round))
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package merkletrie
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/cockroachdb/pebble"
+)
+
+// ErrInvalidProof is returned when a proof doesn't commit to the expected root.
+var ErrInvalidProof = errors.New("invalid proof")
+
+// ProofChild is a child link of a vertex in a Proof.
+type ProofChild struct {
+	Char byte
+	Hash *chainhash.Hash
+}
+
+// ProofNode is a vertex on the path of the name in a Proof.
+// The child link on the path is omitted from Children; it is recomputed by the verifier.
+type ProofNode struct {
+	Children  []ProofChild    // ordered by Char
+	ValueHash *chainhash.Hash // claims hash of the vertex, nil if the vertex holds no value
+}
+
+// ProofPair is a sibling on the merkle path from a claim hash to the claims hash of its vertex.
+type ProofPair struct {
+	Right bool // the sibling is on the right of the path
+	Hash  *chainhash.Hash
+}
+
+// Proof proves the inclusion or the exclusion of a name in a MerkleTrie.
+type Proof struct {
+	Name      []byte
+	AllClaims bool        // the hashing scheme after the AllClaimsInMerkle fork applies
+	Nodes     []ProofNode // from the root to the deepest existing vertex on the path of Name
+	Pairs     []ProofPair // merkle path from the best claim hash to the ValueHash of the name (AllClaims only)
+}
+
+// Proof returns a proof of the name against the specified root.
+// The vertices are read from the repo, so any root that has been committed can be used.
+// When allClaims is set, claimHashes should hold the claim hashes of the name, best claim first.
+func (t *MerkleTrie) Proof(root *chainhash.Hash, name []byte, allClaims bool, claimHashes []*chainhash.Hash) (*Proof, error) {
+
+	p := &Proof{Name: name, AllClaims: allClaims}
+
+	h := root
+	for i := 0; i <= len(name); i++ {
+		nb, err := t.loadVertex(name[:i], h)
+		if err != nil {
+			return nil, err
+		}
+
+		var next *chainhash.Hash
+		pn := ProofNode{}
+		_, pn.ValueHash = nb.hasValue()
+		for j := 0; j < nb.entries(); j++ {
+			c, ch := nb.entry(j)
+			if i < len(name) && c == name[i] {
+				next = ch
+				continue
+			}
+			pn.Children = append(pn.Children, ProofChild{Char: c, Hash: ch})
+		}
+		p.Nodes = append(p.Nodes, pn)
+
+		if next == nil {
+			// This is the deepest vertex on the path; all of its children are listed.
+			break
+		}
+		h = next
+	}
+
+	p.setPairs(claimHashes)
+
+	return p, nil
+}
+
+// PathVertex is a vertex on the path of a name, with all of its child links.
+type PathVertex struct {
+	Key       []byte
+	Hash      *chainhash.Hash
+	Children  []ProofChild    // ordered by Char
+	ValueHash *chainhash.Hash // claims hash of the vertex, nil if the vertex holds no value
+}
+
+// Path returns the vertices on the path of the name in the trie with the
+// specified root, from the root to the deepest existing vertex. The name is in
+// the trie if the key of the last vertex is the name and it holds a value.
+func (t *MerkleTrie) Path(root *chainhash.Hash, name []byte) ([]PathVertex, error) {
+
+	var path []PathVertex
+
+	h := root
+	for i := 0; i <= len(name); i++ {
+		nb, err := t.loadVertex(name[:i], h)
+		if err != nil {
+			return nil, err
+		}
+
+		var next *chainhash.Hash
+		pv := PathVertex{Key: name[:i], Hash: h}
+		_, pv.ValueHash = nb.hasValue()
+		for j := 0; j < nb.entries(); j++ {
+			c, ch := nb.entry(j)
+			if i < len(name) && c == name[i] {
+				next = ch
+			}
+			pv.Children = append(pv.Children, ProofChild{Char: c, Hash: ch})
+		}
+		path = append(path, pv)
+
+		if next == nil {
+			break
+		}
+		h = next
+	}
+
+	return path, nil
+}
+
+// setPairs sets the merkle path from the best claim to the value hash of the name,
+// if the proof includes the name and uses the AllClaims scheme.
+func (p *Proof) setPairs(claimHashes []*chainhash.Hash) {
+
+	last := p.Nodes[len(p.Nodes)-1]
+	if p.AllClaims && len(p.Nodes) == len(p.Name)+1 && last.ValueHash != nil && len(claimHashes) > 0 {
+		p.Pairs = merklePath(claimHashes, 0)
+	}
+}
+
+// loadVertex reads the on-disk format of the vertex at key with the hash h.
+func (t *MerkleTrie) loadVertex(key []byte, h *chainhash.Hash) (nbuf, error) {
+
+	if len(key) == 0 && h.IsEqual(EmptyTrieHash) {
+		return nbuf{}, nil
+	}
+
+	k := make([]byte, 0, len(key)+chainhash.HashSize)
+	k = append(k, key...)
+	k = append(k, h[:]...)
+
+	result, closer, err := t.repo.Get(k)
+	if err == pebble.ErrNotFound { // TODO: leaky abstraction
+		return nil, fmt.Errorf("missing vertex %q with hash %s", key, h)
+	} else if err != nil {
+		return nil, fmt.Errorf("load vertex %q: %w", key, err)
+	}
+	defer closer.Close()
+
+	nb := make(nbuf, len(result))
+	copy(nb, result)
+	return nb, nil
+}
+
+// merklePath returns the siblings on the path from hashes[index] to the merkle root of hashes.
+func merklePath(hashes []*chainhash.Hash, index int) []ProofPair {
+
+	var pairs []ProofPair
+
+	level := make([]*chainhash.Hash, len(hashes))
+	copy(level, hashes)
+	for len(level) > 1 {
+		if (len(level) & 1) > 0 { // odd count
+			level = append(level, level[len(level)-1])
+		}
+		if index&1 == 0 {
+			pairs = append(pairs, ProofPair{Right: true, Hash: level[index+1]})
+		} else {
+			pairs = append(pairs, ProofPair{Right: false, Hash: level[index-1]})
+		}
+		next := make([]*chainhash.Hash, 0, len(level)>>1)
+		for i := 0; i < len(level); i += 2 {
+			next = append(next, hashMerkleBranches(level[i], level[i+1]))
+		}
+		level = next
+		index >>= 1
+	}
+
+	return pairs
+}
+
+// VerifyProof verifies that the proof is committed by root.
+// If claimHash is set, the proof must show that claimHash is the best claim of the name.
+// Otherwise, the proof must show that the name holds no value.
+func VerifyProof(root *chainhash.Hash, p *Proof, claimHash *chainhash.Hash) error {
+
+	if len(p.Nodes) == 0 || len(p.Nodes) > len(p.Name)+1 {
+		return fmt.Errorf("%w: unexpected number of nodes", ErrInvalidProof)
+	}
+
+	last := len(p.Nodes) - 1
+	reached := last == len(p.Name)
+	valueHash := p.Nodes[last].ValueHash
+
+	switch {
+	case claimHash != nil && !reached:
+		return fmt.Errorf("%w: name is not in the trie", ErrInvalidProof)
+	case claimHash != nil && valueHash == nil:
+		return fmt.Errorf("%w: name holds no value", ErrInvalidProof)
+	case claimHash == nil && reached && valueHash != nil:
+		return fmt.Errorf("%w: name holds a value", ErrInvalidProof)
+	case claimHash == nil && !reached:
+		for _, c := range p.Nodes[last].Children {
+			if c.Char == p.Name[last] {
+				return fmt.Errorf("%w: name continues in the trie", ErrInvalidProof)
+			}
+		}
+	}
+
+	if claimHash != nil {
+		h := claimHash
+		if p.AllClaims {
+			for _, pair := range p.Pairs {
+				if !pair.Right { // the best claim is always the leftmost one
+					return fmt.Errorf("%w: claim is not the best claim", ErrInvalidProof)
+				}
+				h = hashMerkleBranches(h, pair.Hash)
+			}
+		}
+		if !h.IsEqual(valueHash) {
+			return fmt.Errorf("%w: claim hash mismatch", ErrInvalidProof)
+		}
+	}
+
+	var h *chainhash.Hash
+	for i := last; i >= 0; i-- {
+		children := p.Nodes[i].Children
+		if i < last && h != nil {
+			children = append(children[:len(children):len(children)], ProofChild{Char: p.Name[i], Hash: h})
+			sort.Slice(children, func(a, b int) bool { return children[a].Char < children[b].Char })
+		}
+		if p.AllClaims {
+			h = vertexHashAllClaims(children, p.Nodes[i].ValueHash)
+		} else {
+			h = vertexHash(children, p.Nodes[i].ValueHash)
+		}
+	}
+	if h == nil {
+		h = EmptyTrieHash
+	}
+
+	if !h.IsEqual(root) {
+		return fmt.Errorf("%w: root mismatch", ErrInvalidProof)
+	}
+	return nil
+}
+
+// vertexHash computes the hash of a vertex as MerkleTrie.merkle does.
+func vertexHash(children []ProofChild, valueHash *chainhash.Hash) *chainhash.Hash {
+
+	b := bytes.NewBuffer(nil)
+	for _, c := range children {
+		b.WriteByte(c.Char)
+		b.Write(c.Hash[:])
+	}
+	if valueHash != nil {
+		b.Write(valueHash[:])
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	h := chainhash.DoubleHashH(b.Bytes())
+	return &h
+}
+
+// vertexHashAllClaims computes the hash of a vertex as MerkleTrie.merkleAllClaims does.
+func vertexHashAllClaims(children []ProofChild, valueHash *chainhash.Hash) *chainhash.Hash {
+
+	childHashes := make([]*chainhash.Hash, 0, len(children))
+	for _, c := range children {
+		childHashes = append(childHashes, c.Hash)
+	}
+
+	if len(childHashes) > 1 || valueHash != nil {
+		left := NoChildrenHash
+		if len(childHashes) > 0 {
+			left = computeMerkleRoot(childHashes)
+		}
+		right := NoClaimsHash
+		if valueHash != nil {
+			right = valueHash
+		}
+		return hashMerkleBranches(left, right)
+	}
+	if len(childHashes) == 1 {
+		return childHashes[0]
+	}
+	return nil
+}
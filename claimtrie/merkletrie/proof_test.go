@@ -0,0 +1,154 @@
+package merkletrie
+
+import (
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/cockroachdb/pebble"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mapRepo map[string][]byte
+
+func (r mapRepo) Get(key []byte) ([]byte, io.Closer, error) {
+	v, ok := r[string(key)]
+	if !ok {
+		return nil, nil, pebble.ErrNotFound
+	}
+	return v, ioutil.NopCloser(nil), nil
+}
+
+func (r mapRepo) Set(key, value []byte) error {
+	v := make([]byte, len(value))
+	copy(v, value)
+	r[string(key)] = v
+	return nil
+}
+
+func (r mapRepo) Close() error {
+	return nil
+}
+
+type mapStore map[string][]*chainhash.Hash
+
+func (s mapStore) ClaimHashes(name []byte) []*chainhash.Hash {
+	// computeMerkleRoot reuses the slice, so hand out a copy.
+	return append([]*chainhash.Hash(nil), s[string(name)]...)
+}
+
+func (s mapStore) Hash(name []byte) *chainhash.Hash {
+	if hashes := s[string(name)]; len(hashes) > 0 {
+		return hashes[0]
+	}
+	return nil
+}
+
+func TestProof(t *testing.T) {
+
+	for _, allClaims := range []bool{false, true} {
+
+		r := require.New(t)
+
+		store := mapStore{}
+		names := []string{"a", "ab", "abc", "abd", "b", "bcdef", "test", "tes", "x"}
+		for i, name := range names {
+			hashes := []*chainhash.Hash{}
+			for j := 0; j <= i%4; j++ {
+				hashes = append(hashes, &chainhash.Hash{byte(i + 1), byte(j + 1)})
+			}
+			store[name] = hashes
+		}
+
+		tr := New(store, mapRepo{})
+		for _, name := range names {
+			tr.Update([]byte(name), false)
+		}
+
+		var root *chainhash.Hash
+		if allClaims {
+			root = tr.MerkleHashAllClaims()
+		} else {
+			root = tr.MerkleHash()
+		}
+
+		for _, name := range names {
+			p, err := tr.Proof(root, []byte(name), allClaims, store.ClaimHashes([]byte(name)))
+			r.NoError(err)
+			r.NoError(VerifyProof(root, p, store.Hash([]byte(name))), name)
+
+			r.Error(VerifyProof(root, p, nil), name)
+			r.Error(VerifyProof(root, p, &chainhash.Hash{0xff}), name)
+			if allClaims && len(p.Pairs) > 0 {
+				r.Error(VerifyProof(root, p, store[name][len(store[name])-1]), name)
+			}
+		}
+
+		for _, name := range []string{"", "c", "abcd", "bc", "te", "testing"} {
+			p, err := tr.Proof(root, []byte(name), allClaims, nil)
+			r.NoError(err)
+			r.NoError(VerifyProof(root, p, nil), name)
+			r.Error(VerifyProof(root, p, &chainhash.Hash{1}), name)
+			r.Error(VerifyProof(&chainhash.Hash{1}, p, nil), name)
+		}
+
+		// Tamper with a sibling of the path.
+		p, err := tr.Proof(root, []byte("abc"), allClaims, store.ClaimHashes([]byte("abc")))
+		r.NoError(err)
+		p.Nodes[2].Children[0].Hash = &chainhash.Hash{0xff}
+		r.Error(VerifyProof(root, p, store.Hash([]byte("abc"))))
+	}
+}
+
+func TestPath(t *testing.T) {
+
+	r := require.New(t)
+
+	store := mapStore{}
+	for i, name := range []string{"a", "ab", "abc", "abd", "b"} {
+		store[name] = []*chainhash.Hash{{byte(i + 1)}}
+	}
+	tr := New(store, mapRepo{})
+	for name := range store {
+		tr.Update([]byte(name), false)
+	}
+	root := tr.MerkleHash()
+
+	path, err := tr.Path(root, []byte("ab"))
+	r.NoError(err)
+	r.Len(path, 3)
+	r.Equal(root, path[0].Hash)
+	r.Nil(path[0].ValueHash)
+	r.Equal([]byte("ab"), path[2].Key)
+	r.Equal(store["ab"][0], path[2].ValueHash)
+	r.Len(path[2].Children, 2)
+
+	// Each vertex is linked to the next one, and hashes as the trie does.
+	for i := range path {
+		r.Equal(path[i].Hash, vertexHash(path[i].Children, path[i].ValueHash))
+		if i > 0 {
+			r.Contains(path[i-1].Children, ProofChild{Char: path[i].Key[i-1], Hash: path[i].Hash})
+		}
+	}
+
+	// The path of a missing name stops at its deepest existing vertex.
+	path, err = tr.Path(root, []byte("abx"))
+	r.NoError(err)
+	r.Len(path, 3)
+	r.Equal([]byte("ab"), path[len(path)-1].Key)
+}
+
+func TestProofEmptyTrie(t *testing.T) {
+
+	r := require.New(t)
+
+	tr := New(mapStore{}, mapRepo{})
+	root := tr.MerkleHash()
+
+	p, err := tr.Proof(root, []byte("test"), false, nil)
+	r.NoError(err)
+	r.NoError(VerifyProof(root, p, nil))
+	r.Error(VerifyProof(root, p, &chainhash.Hash{1}))
+}
@@ -0,0 +1,330 @@
+package merkletrie
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// RamTrie implements a 256-way prefix tree which keeps all of its vertices in memory.
+// Unlike MerkleTrie, it never reads nor writes a Repo, so it trades memory for speed.
+type RamTrie struct {
+	store ValueStore
+
+	root *vertex
+	bufs *sync.Pool
+	pool *workerPool
+
+	// allClaims reports which hashing scheme the resolved hashes were computed with.
+	allClaims bool
+
+	// shared reports whether the hashed vertices may be shared with the trie this
+	// one was forked from, in which case they're copied before being changed.
+	shared bool
+}
+
+// NewRamTrie returns a RamTrie.
+func NewRamTrie(store ValueStore) *RamTrie {
+
+	tr := &RamTrie{
+		store: store,
+		bufs: &sync.Pool{
+			New: func() interface{} {
+				return new(bytes.Buffer)
+			},
+		},
+		root: newVertex(nil),
+		pool: newWorkerPool(0),
+	}
+
+	return tr
+}
+
+// SetWorkers sets the number of goroutines hashing subtrees in parallel, which
+// defaults to the number of CPUs. With one worker, the hashes are computed sequentially.
+func (rt *RamTrie) SetWorkers(n int) {
+	rt.pool = newWorkerPool(n)
+}
+
+// SetRoot updates the names that changed since the root had the specified hash.
+// The vertices of a RamTrie can't be resolved by hash, so all names whose values
+// differ from the time the root was computed must be listed.
+func (rt *RamTrie) SetRoot(h *chainhash.Hash, names [][]byte) {
+
+	if rt.root.merkleHash != nil && rt.root.merkleHash.IsEqual(h) {
+		return
+	}
+
+	for _, name := range names {
+		rt.Update(name, false)
+	}
+}
+
+// Update updates the nodes along the path to the key.
+// Each node is created if necessary with their Hash cleared.
+// All vertices are resident, so restoreChildren is ignored.
+func (rt *RamTrie) Update(name []byte, restoreChildren bool) {
+
+	n := rt.root
+	for _, ch := range name {
+		child := n.childLinks[ch]
+		if child == nil {
+			child = newVertex(nil)
+			n.childLinks[ch] = child
+		} else if rt.shared && child.merkleHash != nil {
+			child = copyVertex(child)
+			n.childLinks[ch] = child
+		}
+		n.merkleHash = nil
+		n = child
+	}
+
+	n.hasValue = true
+	n.merkleHash = nil
+	n.claimsHash = nil
+}
+
+// invalidate clears all resolved hashes, which are recomputed with the requested scheme.
+func (rt *RamTrie) invalidate(v *vertex) {
+
+	if rt.shared {
+		v = cloneVertices(v)
+		rt.root = v
+		rt.shared = false
+	}
+	rt.clearHashes(v)
+}
+
+func (rt *RamTrie) clearHashes(v *vertex) {
+
+	v.merkleHash = nil
+	if v.claimsHash != nil {
+		v.claimsHash = nil
+		v.hasValue = true
+	}
+	for _, child := range v.childLinks {
+		rt.clearHashes(child)
+	}
+}
+
+// MerkleHash returns the Merkle Hash of the RamTrie.
+func (rt *RamTrie) MerkleHash() *chainhash.Hash {
+
+	if rt.allClaims {
+		rt.invalidate(rt.root)
+		rt.allClaims = false
+	}
+
+	buf := make([]byte, 0, 256)
+	if h := rt.merkle(buf, rt.root); h == nil {
+		return EmptyTrieHash
+	}
+	return rt.root.merkleHash
+}
+
+// merkle recursively resolves the hashes of the node.
+func (rt *RamTrie) merkle(prefix []byte, v *vertex) *chainhash.Hash {
+	if v.merkleHash != nil {
+		return v.merkleHash
+	}
+
+	b := rt.bufs.Get().(*bytes.Buffer)
+	defer rt.bufs.Put(b)
+	b.Reset()
+
+	keys := keysInOrder(v)
+	hashes := rt.pool.hashChildren(prefix, v, keys, rt.merkle)
+
+	for i, ch := range keys {
+		h := hashes[i]
+		if h == nil {
+			delete(v.childLinks, ch) // the name is gone
+			continue
+		}
+		b.WriteByte(ch) // nolint : errchk
+		b.Write(h[:])   // nolint : errchk
+	}
+
+	if v.hasValue {
+		claimHash := v.claimsHash
+		if claimHash == nil {
+			claimHash = rt.store.Hash(prefix)
+			v.claimsHash = claimHash
+		}
+		if claimHash != nil {
+			b.Write(claimHash[:]) // nolint : errchk
+		} else {
+			v.hasValue = false
+		}
+	}
+
+	if b.Len() > 0 {
+		h := chainhash.DoubleHashH(b.Bytes())
+		v.merkleHash = &h
+	}
+
+	return v.merkleHash
+}
+
+// MerkleHashAllClaims returns the Merkle Hash of the RamTrie, which commits to all claims of the names.
+func (rt *RamTrie) MerkleHashAllClaims() *chainhash.Hash {
+
+	if !rt.allClaims {
+		rt.invalidate(rt.root)
+		rt.allClaims = true
+	}
+
+	buf := make([]byte, 0, 256)
+	if h := rt.merkleAllClaims(buf, rt.root); h == nil {
+		return EmptyTrieHash
+	}
+	return rt.root.merkleHash
+}
+
+func (rt *RamTrie) merkleAllClaims(prefix []byte, v *vertex) *chainhash.Hash {
+	if v.merkleHash != nil {
+		return v.merkleHash
+	}
+
+	keys := keysInOrder(v)
+	hashes := rt.pool.hashChildren(prefix, v, keys, rt.merkleAllClaims)
+
+	childHashes := make([]*chainhash.Hash, 0, len(keys))
+	for i, ch := range keys {
+		h := hashes[i]
+		if h == nil {
+			delete(v.childLinks, ch) // the name is gone
+			continue
+		}
+		childHashes = append(childHashes, h)
+	}
+
+	var claimsHash *chainhash.Hash
+	if v.hasValue {
+		claimsHash = v.claimsHash
+		if claimsHash == nil {
+			claimHashes := rt.store.ClaimHashes(prefix)
+			if len(claimHashes) > 0 {
+				claimsHash = computeMerkleRoot(claimHashes)
+				v.claimsHash = claimsHash
+			} else {
+				v.hasValue = false
+			}
+		}
+	}
+
+	if len(childHashes) > 1 || claimsHash != nil {
+		left := NoChildrenHash
+		if len(childHashes) > 0 {
+			left = computeMerkleRoot(childHashes)
+		}
+		right := NoClaimsHash
+		if claimsHash != nil {
+			right = claimsHash
+		}
+		v.merkleHash = hashMerkleBranches(left, right)
+	} else if len(childHashes) == 1 {
+		v.merkleHash = childHashes[0] // pass it up the tree
+	}
+
+	return v.merkleHash
+}
+
+// Proof returns a proof of the name against the specified root.
+// Only the current root can be proven, as no previous vertices are kept.
+// The RamTrie isn't rehashed, so it must have been hashed last with the
+// scheme of the proof.
+func (rt *RamTrie) Proof(root *chainhash.Hash, name []byte, allClaims bool, claimHashes []*chainhash.Hash) (*Proof, error) {
+
+	if allClaims != rt.allClaims {
+		return nil, fmt.Errorf("the RAM trie isn't hashed with the requested scheme (all claims: %t)", allClaims)
+	}
+	current := rt.root.merkleHash
+	if current == nil {
+		if len(rt.root.childLinks) > 0 || rt.root.hasValue {
+			return nil, fmt.Errorf("the RAM trie isn't hashed")
+		}
+		current = EmptyTrieHash
+	}
+	if !current.IsEqual(root) {
+		return nil, fmt.Errorf("root %s is not the current root %s of the RAM trie", root, current)
+	}
+
+	p := &Proof{Name: name, AllClaims: allClaims}
+
+	v := rt.root
+	for i := 0; i <= len(name); i++ {
+
+		var next *vertex
+		pn := ProofNode{}
+		if v.hasValue {
+			pn.ValueHash = v.claimsHash
+		}
+		for _, c := range keysInOrder(v) {
+			child := v.childLinks[c]
+			if i < len(name) && c == name[i] {
+				next = child
+				continue
+			}
+			pn.Children = append(pn.Children, ProofChild{Char: c, Hash: child.merkleHash})
+		}
+		p.Nodes = append(p.Nodes, pn)
+
+		if next == nil {
+			// This is the deepest vertex on the path; all of its children are listed.
+			break
+		}
+		v = next
+	}
+
+	p.setPairs(claimHashes)
+
+	return p, nil
+}
+
+// Fork returns a RamTrie with the vertices of this one over the store. They're
+// shared until the fork changes them, which copies them first.
+func (rt *RamTrie) Fork(store ValueStore) Trie {
+
+	tr := &RamTrie{
+		store:     store,
+		root:      copyVertex(rt.root),
+		bufs:      rt.bufs,
+		pool:      rt.pool,
+		allClaims: rt.allClaims,
+		shared:    true,
+	}
+
+	return tr
+}
+
+// copyVertex returns a copy of the vertex, which shares its children.
+func copyVertex(v *vertex) *vertex {
+
+	c := *v
+	c.childLinks = make(map[byte]*vertex, len(v.childLinks))
+	for ch, child := range v.childLinks {
+		c.childLinks[ch] = child
+	}
+
+	return &c
+}
+
+// cloneVertices returns a copy of the vertex and all of its descendants.
+func cloneVertices(v *vertex) *vertex {
+
+	c := copyVertex(v)
+	for ch, child := range c.childLinks {
+		c.childLinks[ch] = cloneVertices(child)
+	}
+
+	return c
+}
+
+// Close releases the vertices of the RamTrie.
+func (rt *RamTrie) Close() error {
+	rt.root = newVertex(nil)
+	return nil
+}
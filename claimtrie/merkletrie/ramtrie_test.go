@@ -0,0 +1,91 @@
+package merkletrie
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRamTrie(t *testing.T) {
+
+	r := require.New(t)
+
+	store := mapStore{}
+	rt := NewRamTrie(store)
+
+	r.Equal(EmptyTrieHash[:], rt.MerkleHash()[:])
+	r.Equal(EmptyTrieHash[:], rt.MerkleHashAllClaims()[:])
+
+	update := func(name string, hashes ...*chainhash.Hash) {
+		if len(hashes) == 0 {
+			delete(store, name)
+		} else {
+			store[name] = hashes
+		}
+		rt.Update([]byte(name), false)
+	}
+	// expected computes the hash with a MerkleTrie built from scratch.
+	expected := func(allClaims bool) []byte {
+		mt := New(store, mapRepo{})
+		for name := range store {
+			mt.Update([]byte(name), false)
+		}
+		if allClaims {
+			return mt.MerkleHashAllClaims()[:]
+		}
+		return mt.MerkleHash()[:]
+	}
+	check := func() {
+		// Alternate the schemes to make sure switching recomputes everything.
+		r.Equal(expected(false), rt.MerkleHash()[:])
+		r.Equal(expected(true), rt.MerkleHashAllClaims()[:])
+		r.Equal(expected(false), rt.MerkleHash()[:])
+	}
+
+	names := []string{"a", "ab", "abc", "abd", "b", "bcdef", "test", "tes", "x"}
+	for i, name := range names {
+		update(name, &chainhash.Hash{byte(i + 1)}, &chainhash.Hash{byte(i + 1), 1})
+		check()
+	}
+
+	root := rt.MerkleHash()
+	rootAllClaims := rt.MerkleHashAllClaims()
+
+	update("abc")
+	update("bcdef")
+	update("ab", &chainhash.Hash{0xff})
+	update("new", &chainhash.Hash{0xfe})
+	check()
+	r.NotEqual(root[:], rt.MerkleHash()[:])
+
+	// Revert the store and roll the RamTrie back by listing the changed names.
+	store["abc"] = []*chainhash.Hash{{3}, {3, 1}}
+	store["bcdef"] = []*chainhash.Hash{{6}, {6, 1}}
+	store["ab"] = []*chainhash.Hash{{2}, {2, 1}}
+	delete(store, "new")
+	rt.SetRoot(root, [][]byte{[]byte("abc"), []byte("bcdef"), []byte("ab"), []byte("new")})
+	r.Equal(root[:], rt.MerkleHash()[:])
+	r.Equal(rootAllClaims[:], rt.MerkleHashAllClaims()[:])
+
+	for _, allClaims := range []bool{false, true} {
+		root := rt.MerkleHash()
+		if allClaims {
+			root = rt.MerkleHashAllClaims()
+		}
+		for _, name := range append(names, "", "c", "abcd", "testing") {
+			p, err := rt.Proof(root, []byte(name), allClaims, store.ClaimHashes([]byte(name)))
+			r.NoError(err)
+			r.NoError(VerifyProof(root, p, store.Hash([]byte(name))), name)
+		}
+		_, err := rt.Proof(&chainhash.Hash{1, 2, 3}, []byte("a"), allClaims, nil)
+		r.Error(err)
+
+		// A proof of the other scheme is refused rather than rehashing.
+		_, err = rt.Proof(root, []byte("a"), !allClaims, nil)
+		r.Error(err)
+		r.Equal(allClaims, rt.allClaims)
+		r.Equal(root[:], rt.root.merkleHash[:])
+	}
+}
@@ -2,6 +2,7 @@ package merkletrie
 
 import (
 	"io"
+	"io/ioutil"
 )
 
 // Repo defines APIs for MerkleTrie to access persistence layer.
@@ -10,3 +11,41 @@ type Repo interface {
 	Set(key, value []byte) error
 	Close() error
 }
+
+// overlayRepo reads the vertices from a base Repo, and keeps those set in memory,
+// so the base is left untouched. The base isn't closed with it.
+type overlayRepo struct {
+	base     Repo
+	vertices map[string][]byte
+}
+
+func newOverlayRepo(base Repo) *overlayRepo {
+	return &overlayRepo{
+		base:     base,
+		vertices: map[string][]byte{},
+	}
+}
+
+// Get is only called by Update, which isn't concurrent with the Set of MerkleHash.
+func (repo *overlayRepo) Get(key []byte) ([]byte, io.Closer, error) {
+
+	value, ok := repo.vertices[string(key)]
+	if ok {
+		return value, ioutil.NopCloser(nil), nil
+	}
+
+	return repo.base.Get(key)
+}
+
+// Set is serialized by MerkleTrie.
+func (repo *overlayRepo) Set(key, value []byte) error {
+
+	// The caller reuses its buffers.
+	repo.vertices[string(key)] = append([]byte(nil), value...)
+
+	return nil
+}
+
+func (repo *overlayRepo) Close() error {
+	return nil
+}
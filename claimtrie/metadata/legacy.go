@@ -0,0 +1,79 @@
+package metadata
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// legacyClaim is the JSON format of the first stream claims, in its versions
+// 0.0.1 to 0.0.3.
+type legacyClaim struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Author       string `json:"author"`
+	License      string `json:"license"`
+	LicenseURL   string `json:"license_url"`
+	Thumbnail    string `json:"thumbnail"`
+	ContentType  string `json:"content_type"`
+	ContentType1 string `json:"content-type"` // version 0.0.1
+	Sources      struct {
+		SDHash string `json:"lbry_sd_hash"`
+	} `json:"sources"`
+	Fee map[string]struct {
+		Amount  float64 `json:"amount"`
+		Address string  `json:"address"`
+	} `json:"fee"`
+}
+
+// legacyUnits are the numbers of the smallest units in a unit of each currency.
+var legacyUnits = map[string]float64{
+	"LBC": 1e8,
+	"BTC": 1e8,
+	"USD": 100,
+}
+
+// decodeLegacy decodes a claim value in the legacy JSON format.
+func decodeLegacy(value []byte) (*Metadata, error) {
+
+	var claim legacyClaim
+	err := json.Unmarshal(value, &claim)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Metadata{
+		Type:         Stream,
+		Legacy:       true,
+		Title:        claim.Title,
+		Description:  claim.Description,
+		ThumbnailURL: claim.Thumbnail,
+		Author:       claim.Author,
+		License:      claim.License,
+		LicenseURL:   claim.LicenseURL,
+		Source:       &Source{MediaType: claim.ContentType},
+	}
+	if claim.ContentType1 != "" {
+		meta.Source.MediaType = claim.ContentType1
+	}
+	if sdHash, err := hex.DecodeString(claim.Sources.SDHash); err == nil && len(sdHash) > 0 {
+		meta.Source.SDHash = sdHash
+	}
+
+	// The fee is keyed by its currency.
+	for currency, fee := range claim.Fee {
+		units, ok := legacyUnits[currency]
+		if !ok || fee.Amount < 0 {
+			continue
+		}
+		meta.Fee = &Fee{
+			Currency: currency,
+			Amount:   uint64(math.Round(fee.Amount * units)),
+			Address:  base58.Decode(fee.Address),
+		}
+	}
+
+	return meta, nil
+}
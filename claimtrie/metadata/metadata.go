@@ -0,0 +1,175 @@
+// Package metadata decodes the values of claims. They hold the metadata of the
+// published content in the protobuf schema of LBRY, optionally signed by a
+// channel, or in the legacy JSON format of the first claims.
+package metadata
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/claimtrie/node"
+)
+
+// Type identifies the kind of content described by a Metadata.
+type Type uint8
+
+const (
+	// Stream is a piece of content, such as a video or a document.
+	Stream Type = iota + 1
+
+	// Channel is an identity, which can sign the claims published under it.
+	Channel
+
+	// Collection is a list of claims.
+	Collection
+
+	// Repost is a reference to another claim.
+	Repost
+)
+
+var typeNames = map[Type]string{
+	Stream:     "stream",
+	Channel:    "channel",
+	Collection: "collection",
+	Repost:     "repost",
+}
+
+func (t Type) String() string {
+	if s, ok := typeNames[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(t))
+}
+
+// The sizes of the fields of a signed value.
+const (
+	channelIDSize = 20
+	signatureSize = 64
+)
+
+// The leading byte of the values in the protobuf schema.
+const (
+	unsignedPrefix = 0x00
+	signedPrefix   = 0x01
+)
+
+// ErrUnknownFormat is returned when the value is in none of the supported
+// formats, such as the first version of the protobuf schema.
+var ErrUnknownFormat = errors.New("unknown claim value format")
+
+// Value is a decoded claim value.
+type Value struct {
+	// Signed tells whether the value carries a channel signature.
+	Signed bool
+
+	// SigningChannelID is the claim ID of the channel which signed the value.
+	SigningChannelID node.ClaimID
+
+	// Signature is the signature of the channel, as the concatenation of
+	// its R and S values.
+	Signature []byte
+
+	// Payload is the serialized Metadata, as covered by the signature.
+	Payload []byte
+
+	Meta *Metadata
+}
+
+// Metadata describes the content of a claim. The fields which don't apply to
+// its Type are left empty.
+type Metadata struct {
+	Type Type
+
+	// Legacy tells whether the metadata was decoded from the legacy JSON format.
+	Legacy bool
+
+	Title        string
+	Description  string
+	ThumbnailURL string
+	Tags         []string
+
+	// Streams.
+	Author      string
+	License     string
+	LicenseURL  string
+	ReleaseTime int64  // seconds since the UNIX epoch
+	StreamType  string // image, video, audio or software, if specified
+	Source      *Source
+	Fee         *Fee
+
+	// Channels.
+	PublicKey  []byte // DER encoded
+	Email      string
+	WebsiteURL string
+	CoverURL   string
+
+	// ClaimIDs are the claims listed by a collection, featured by a channel,
+	// or the reposted claim.
+	ClaimIDs []node.ClaimID
+}
+
+// Source describes the data of a stream.
+type Source struct {
+	Name      string
+	Size      uint64
+	MediaType string
+	URL       string
+	Hash      []byte
+	SDHash    []byte
+}
+
+// Fee is the price asked for a stream.
+type Fee struct {
+	Currency string // LBC, BTC or USD
+
+	// Amount is in the smallest unit of the currency: dewies, satoshis or cents.
+	Amount uint64
+
+	// Address is the raw address, including its version and checksum.
+	Address []byte
+}
+
+// Decode decodes a claim value. The value of a support can be decoded as well,
+// if it isn't empty.
+func Decode(value []byte) (*Value, error) {
+
+	if len(value) == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	switch value[0] {
+	case unsignedPrefix:
+		meta, err := decodeClaim(value[1:])
+		if err != nil {
+			return nil, fmt.Errorf("decode claim: %w", err)
+		}
+		return &Value{Payload: value[1:], Meta: meta}, nil
+
+	case signedPrefix:
+		header := 1 + channelIDSize + signatureSize
+		if len(value) < header {
+			return nil, fmt.Errorf("signed value of %d bytes is truncated", len(value))
+		}
+		meta, err := decodeClaim(value[header:])
+		if err != nil {
+			return nil, fmt.Errorf("decode claim: %w", err)
+		}
+		v := &Value{
+			Signed:    true,
+			Signature: value[1+channelIDSize : header],
+			Payload:   value[header:],
+			Meta:      meta,
+		}
+		copy(v.SigningChannelID[:], value[1:1+channelIDSize])
+		return v, nil
+
+	case '{':
+		meta, err := decodeLegacy(value)
+		if err != nil {
+			return nil, fmt.Errorf("decode legacy claim: %w", err)
+		}
+		return &Value{Payload: value, Meta: meta}, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
@@ -0,0 +1,288 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/base58"
+
+	"github.com/stretchr/testify/require"
+)
+
+// message builds a protobuf message for the tests.
+type message struct {
+	bytes.Buffer
+}
+
+func (m *message) tag(num, wireType uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	m.Write(buf[:binary.PutUvarint(buf[:], num<<3|wireType)])
+}
+
+func (m *message) varint(num, v uint64) *message {
+	var buf [binary.MaxVarintLen64]byte
+	m.tag(num, wireVarint)
+	m.Write(buf[:binary.PutUvarint(buf[:], v)])
+	return m
+}
+
+func (m *message) bytes(num uint64, b []byte) *message {
+	var buf [binary.MaxVarintLen64]byte
+	m.tag(num, wireBytes)
+	m.Write(buf[:binary.PutUvarint(buf[:], uint64(len(b)))])
+	m.Write(b)
+	return m
+}
+
+func (m *message) string(num uint64, s string) *message {
+	return m.bytes(num, []byte(s))
+}
+
+func (m *message) message(num uint64, sub *message) *message {
+	return m.bytes(num, sub.Bytes())
+}
+
+func TestDecodeStream(t *testing.T) {
+
+	r := require.New(t)
+
+	address := base58.Decode("bHW58d37s1hBjj3wPBkn5zpCX3F8ZW3F4w")
+	source := (&message{}).
+		bytes(1, []byte{1, 2}).
+		string(2, "movie.mp4").
+		varint(3, 1234567).
+		string(4, "video/mp4").
+		bytes(6, []byte{3, 4})
+	stream := (&message{}).
+		message(1, source).
+		string(2, "author").
+		string(3, "CC-BY").
+		string(4, "https://license").
+		varint(5, 1600000000).
+		message(6, (&message{}).varint(1, 3).bytes(2, address).varint(3, 250)).
+		message(11, (&message{}).varint(1, 1920))
+	claim := (&message{}).
+		message(1, stream).
+		string(8, "title").
+		string(9, "description").
+		message(10, (&message{}).string(5, "https://thumbnail")).
+		string(11, "tag1").
+		string(11, "tag2").
+		varint(99, 7) // unknown fields are skipped
+
+	v, err := Decode(append([]byte{0}, claim.Bytes()...))
+	r.NoError(err)
+	r.False(v.Signed)
+	r.Equal(claim.Bytes(), v.Payload)
+
+	expected := &Metadata{
+		Type:         Stream,
+		Title:        "title",
+		Description:  "description",
+		ThumbnailURL: "https://thumbnail",
+		Tags:         []string{"tag1", "tag2"},
+		Author:       "author",
+		License:      "CC-BY",
+		LicenseURL:   "https://license",
+		ReleaseTime:  1600000000,
+		StreamType:   "video",
+		Source: &Source{
+			Name:      "movie.mp4",
+			Size:      1234567,
+			MediaType: "video/mp4",
+			Hash:      []byte{1, 2},
+			SDHash:    []byte{3, 4},
+		},
+		Fee: &Fee{Currency: "USD", Amount: 250, Address: address},
+	}
+	r.Equal(expected, v.Meta)
+}
+
+func TestDecodeSignedChannel(t *testing.T) {
+
+	r := require.New(t)
+
+	signer, err := node.NewIDFromString("0123456789abcdef0123456789abcdef01234567")
+	r.NoError(err)
+	featured := node.ClaimID{9, 8, 7}
+	signature := bytes.Repeat([]byte{5}, signatureSize)
+
+	channel := (&message{}).
+		bytes(1, []byte{0x30, 0x56}).
+		string(2, "me@example.com").
+		string(3, "https://example.com").
+		message(4, (&message{}).string(5, "https://cover")).
+		message(5, (&message{}).message(2, (&message{}).bytes(1, featured[:])))
+	claim := (&message{}).message(2, channel).string(8, "channel")
+
+	value := append([]byte{1}, signer[:]...)
+	value = append(value, signature...)
+	value = append(value, claim.Bytes()...)
+
+	v, err := Decode(value)
+	r.NoError(err)
+	r.True(v.Signed)
+	r.Equal("0123456789abcdef0123456789abcdef01234567", v.SigningChannelID.String())
+	r.Equal(signature, v.Signature)
+	r.Equal(claim.Bytes(), v.Payload)
+
+	expected := &Metadata{
+		Type:       Channel,
+		Title:      "channel",
+		PublicKey:  []byte{0x30, 0x56},
+		Email:      "me@example.com",
+		WebsiteURL: "https://example.com",
+		CoverURL:   "https://cover",
+		ClaimIDs:   []node.ClaimID{featured},
+	}
+	r.Equal(expected, v.Meta)
+
+	_, err = Decode(value[:1+channelIDSize+signatureSize-1])
+	r.Error(err)
+}
+
+func TestDecodeReferences(t *testing.T) {
+
+	r := require.New(t)
+
+	id1, id2 := node.ClaimID{1}, node.ClaimID{2}
+	ref1 := (&message{}).bytes(1, id1[:])
+	ref2 := (&message{}).bytes(1, id2[:])
+
+	repost := (&message{}).message(4, ref1)
+	v, err := Decode(append([]byte{0}, repost.Bytes()...))
+	r.NoError(err)
+	r.Equal(Repost, v.Meta.Type)
+	r.Equal([]node.ClaimID{id1}, v.Meta.ClaimIDs)
+
+	collection := (&message{}).message(3, (&message{}).varint(1, 0).message(2, ref1).message(2, ref2))
+	v, err = Decode(append([]byte{0}, collection.Bytes()...))
+	r.NoError(err)
+	r.Equal(Collection, v.Meta.Type)
+	r.Equal([]node.ClaimID{id1, id2}, v.Meta.ClaimIDs)
+
+	invalid := (&message{}).message(4, (&message{}).bytes(1, id1[:10]))
+	_, err = Decode(append([]byte{0}, invalid.Bytes()...))
+	r.Error(err)
+}
+
+func TestDecodeLegacy(t *testing.T) {
+
+	r := require.New(t)
+
+	value := []byte(`{"ver": "0.0.3", "title": "title", "description": "description", "author": "author",
+		"license": "Public Domain", "license_url": "", "language": "en", "thumbnail": "https://thumbnail",
+		"content_type": "video/mp4", "nsfw": false,
+		"sources": {"lbry_sd_hash": "0a0b"},
+		"fee": {"LBC": {"amount": 1.5, "address": "bHW58d37s1hBjj3wPBkn5zpCX3F8ZW3F4w"}}}`)
+
+	v, err := Decode(value)
+	r.NoError(err)
+	r.False(v.Signed)
+
+	expected := &Metadata{
+		Type:         Stream,
+		Legacy:       true,
+		Title:        "title",
+		Description:  "description",
+		ThumbnailURL: "https://thumbnail",
+		Author:       "author",
+		License:      "Public Domain",
+		Source:       &Source{MediaType: "video/mp4", SDHash: []byte{0x0a, 0x0b}},
+		Fee: &Fee{
+			Currency: "LBC",
+			Amount:   150000000,
+			Address:  base58.Decode("bHW58d37s1hBjj3wPBkn5zpCX3F8ZW3F4w"),
+		},
+	}
+	r.Equal(expected, v.Meta)
+
+	v, err = Decode([]byte(`{"ver": "0.0.1", "name": "x", "content-type": "audio/mpeg"}`))
+	r.NoError(err)
+	r.Equal("audio/mpeg", v.Meta.Source.MediaType)
+	r.Nil(v.Meta.Fee)
+
+	_, err = Decode([]byte(`{"title": `))
+	r.Error(err)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+
+	r := require.New(t)
+
+	_, err := Decode(nil)
+	r.True(errors.Is(err, ErrUnknownFormat))
+
+	// The first version of the protobuf schema isn't supported.
+	_, err = Decode([]byte{0x08, 0x01, 0x10, 0x01})
+	r.True(errors.Is(err, ErrUnknownFormat))
+
+	claim := (&message{}).message(1, (&message{}).string(2, "author"))
+	_, err = Decode(append([]byte{0}, claim.Bytes()[:claim.Len()-1]...))
+	r.Error(err)
+
+	// A claim must have a type.
+	_, err = Decode(append([]byte{0}, (&message{}).string(8, "title").Bytes()...))
+	r.Error(err)
+}
+
+// secp256k1PublicKeyPrefix is the DER encoding of a SubjectPublicKeyInfo holding
+// an uncompressed secp256k1 public key, up to the key.
+const secp256k1PublicKeyPrefix = "3056301006072a8648ce3d020106052b8104000a034200"
+
+func TestVerifySignature(t *testing.T) {
+
+	r := require.New(t)
+
+	priv, err := btcec.NewPrivateKey(btcec.S256())
+	r.NoError(err)
+	prefix, err := hex.DecodeString(secp256k1PublicKeyPrefix)
+	r.NoError(err)
+	publicKey := append(prefix, priv.PubKey().SerializeUncompressed()...)
+
+	key, err := ParsePublicKey(publicKey)
+	r.NoError(err)
+	r.True(key.IsEqual(priv.PubKey()))
+
+	channelID := node.ClaimID{1, 2, 3}
+	firstInput := wire.OutPoint{Hash: chainhash.Hash{4, 5, 6}, Index: 7}
+	claim := (&message{}).message(1, (&message{}).string(2, "author")).string(8, "signed")
+
+	sign := func(firstInput wire.OutPoint) []byte {
+		v := &Value{Signed: true, SigningChannelID: channelID, Payload: claim.Bytes()}
+		sig, err := priv.Sign(v.SignatureDigest(firstInput))
+		r.NoError(err)
+		value := append([]byte{1}, channelID[:]...)
+		value = append(value, sig.R.FillBytes(make([]byte, 32))...)
+		value = append(value, sig.S.FillBytes(make([]byte, 32))...)
+		return append(value, claim.Bytes()...)
+	}
+
+	v, err := Decode(sign(firstInput))
+	r.NoError(err)
+	r.True(v.VerifySignature(firstInput, publicKey))
+
+	// The signature is bound to the transaction, the channel and the payload.
+	r.False(v.VerifySignature(wire.OutPoint{Hash: firstInput.Hash, Index: 8}, publicKey))
+	other, err := btcec.NewPrivateKey(btcec.S256())
+	r.NoError(err)
+	r.False(v.VerifySignature(firstInput, append(prefix, other.PubKey().SerializeUncompressed()...)))
+	r.False(v.VerifySignature(firstInput, publicKey[:len(publicKey)-1]))
+
+	tampered := sign(firstInput)
+	tampered[len(tampered)-1] ^= 1
+	v, err = Decode(tampered)
+	r.NoError(err)
+	r.False(v.VerifySignature(firstInput, publicKey))
+
+	v, err = Decode(append([]byte{0}, claim.Bytes()...))
+	r.NoError(err)
+	r.False(v.VerifySignature(firstInput, publicKey))
+}
@@ -0,0 +1,274 @@
+package metadata
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The wire types of the protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated protobuf message")
+
+// field is a field of a protobuf message. Either varint or bytes is set,
+// depending on its wire type.
+type field struct {
+	num    uint64
+	varint uint64
+	bytes  []byte
+}
+
+// parseFields calls f for each varint and length-delimited field of the
+// message, in order. The fixed-size fields are skipped, as the schema has none.
+func parseFields(msg []byte, f func(fld field) error) error {
+
+	for len(msg) > 0 {
+		tag, n := binary.Uvarint(msg)
+		if n <= 0 {
+			return errTruncated
+		}
+		msg = msg[n:]
+
+		fld := field{num: tag >> 3}
+		switch tag & 7 {
+		case wireVarint:
+			fld.varint, n = binary.Uvarint(msg)
+			if n <= 0 {
+				return errTruncated
+			}
+			msg = msg[n:]
+
+		case wireBytes:
+			size, n := binary.Uvarint(msg)
+			if n <= 0 || size > uint64(len(msg)-n) {
+				return errTruncated
+			}
+			fld.bytes = msg[n : n+int(size)]
+			msg = msg[n+int(size):]
+
+		case wireFixed64:
+			if len(msg) < 8 {
+				return errTruncated
+			}
+			msg = msg[8:]
+			continue
+
+		case wireFixed32:
+			if len(msg) < 4 {
+				return errTruncated
+			}
+			msg = msg[4:]
+			continue
+
+		default:
+			return fmt.Errorf("unsupported wire type %d of field %d", tag&7, fld.num)
+		}
+
+		if err := f(fld); err != nil {
+			return fmt.Errorf("field %d: %w", fld.num, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeClaim decodes a Claim message.
+func decodeClaim(msg []byte) (*Metadata, error) {
+
+	meta := &Metadata{}
+	err := parseFields(msg, func(fld field) error {
+		switch fld.num {
+		case 1:
+			meta.Type = Stream
+			return decodeStream(fld.bytes, meta)
+		case 2:
+			meta.Type = Channel
+			return decodeChannel(fld.bytes, meta)
+		case 3:
+			meta.Type = Collection
+			return decodeClaimList(fld.bytes, meta)
+		case 4:
+			meta.Type = Repost
+			return decodeClaimReference(fld.bytes, meta)
+		case 8:
+			meta.Title = string(fld.bytes)
+		case 9:
+			meta.Description = string(fld.bytes)
+		case 10:
+			source, err := decodeSource(fld.bytes)
+			if err != nil {
+				return err
+			}
+			meta.ThumbnailURL = source.URL
+		case 11:
+			meta.Tags = append(meta.Tags, string(fld.bytes))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if meta.Type == 0 {
+		return nil, errors.New("claim of unknown type")
+	}
+
+	return meta, nil
+}
+
+// streamTypes are the names of the oneof type fields of a Stream message.
+var streamTypes = map[uint64]string{
+	10: "image",
+	11: "video",
+	12: "audio",
+	13: "software",
+}
+
+// decodeStream decodes a Stream message into meta.
+func decodeStream(msg []byte, meta *Metadata) error {
+
+	return parseFields(msg, func(fld field) error {
+		switch fld.num {
+		case 1:
+			source, err := decodeSource(fld.bytes)
+			if err != nil {
+				return err
+			}
+			meta.Source = source
+		case 2:
+			meta.Author = string(fld.bytes)
+		case 3:
+			meta.License = string(fld.bytes)
+		case 4:
+			meta.LicenseURL = string(fld.bytes)
+		case 5:
+			meta.ReleaseTime = int64(fld.varint)
+		case 6:
+			fee, err := decodeFee(fld.bytes)
+			if err != nil {
+				return err
+			}
+			meta.Fee = fee
+		default:
+			if typ, ok := streamTypes[fld.num]; ok {
+				meta.StreamType = typ
+			}
+		}
+		return nil
+	})
+}
+
+// decodeChannel decodes a Channel message into meta.
+func decodeChannel(msg []byte, meta *Metadata) error {
+
+	return parseFields(msg, func(fld field) error {
+		switch fld.num {
+		case 1:
+			meta.PublicKey = fld.bytes
+		case 2:
+			meta.Email = string(fld.bytes)
+		case 3:
+			meta.WebsiteURL = string(fld.bytes)
+		case 4:
+			source, err := decodeSource(fld.bytes)
+			if err != nil {
+				return err
+			}
+			meta.CoverURL = source.URL
+		case 5:
+			return decodeClaimList(fld.bytes, meta)
+		}
+		return nil
+	})
+}
+
+// decodeClaimList decodes the references of a ClaimList message into meta.
+func decodeClaimList(msg []byte, meta *Metadata) error {
+
+	return parseFields(msg, func(fld field) error {
+		if fld.num == 2 {
+			return decodeClaimReference(fld.bytes, meta)
+		}
+		return nil
+	})
+}
+
+// decodeClaimReference decodes a ClaimReference message into meta.
+func decodeClaimReference(msg []byte, meta *Metadata) error {
+
+	return parseFields(msg, func(fld field) error {
+		if fld.num != 1 {
+			return nil
+		}
+		if len(fld.bytes) != channelIDSize {
+			return fmt.Errorf("claim hash of %d bytes", len(fld.bytes))
+		}
+		var id [channelIDSize]byte
+		copy(id[:], fld.bytes)
+		meta.ClaimIDs = append(meta.ClaimIDs, id)
+		return nil
+	})
+}
+
+// decodeSource decodes a Source message.
+func decodeSource(msg []byte) (*Source, error) {
+
+	source := &Source{}
+	err := parseFields(msg, func(fld field) error {
+		switch fld.num {
+		case 1:
+			source.Hash = fld.bytes
+		case 2:
+			source.Name = string(fld.bytes)
+		case 3:
+			source.Size = fld.varint
+		case 4:
+			source.MediaType = string(fld.bytes)
+		case 5:
+			source.URL = string(fld.bytes)
+		case 6:
+			source.SDHash = fld.bytes
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return source, nil
+}
+
+// currencies are the names of the values of the Fee.Currency enum.
+var currencies = map[uint64]string{
+	1: "LBC",
+	2: "BTC",
+	3: "USD",
+}
+
+// decodeFee decodes a Fee message.
+func decodeFee(msg []byte) (*Fee, error) {
+
+	fee := &Fee{}
+	err := parseFields(msg, func(fld field) error {
+		switch fld.num {
+		case 1:
+			fee.Currency = currencies[fld.varint]
+		case 2:
+			fee.Address = fld.bytes
+		case 3:
+			fee.Amount = fld.varint
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fee, nil
+}
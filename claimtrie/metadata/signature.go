@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// subjectPublicKeyInfo is the DER structure holding the public key of a channel.
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// ParsePublicKey parses the DER encoded secp256k1 public key of a channel.
+func ParsePublicKey(der []byte) (*btcec.PublicKey, error) {
+
+	var info subjectPublicKeyInfo
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return nil, fmt.Errorf("asn1 unmarshal: %w", err)
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data after the public key")
+	}
+
+	return btcec.ParsePubKey(info.PublicKey.RightAlign(), btcec.S256())
+}
+
+// SignatureDigest returns the digest signed by the channel: the SHA-256 of the
+// first input of the transaction holding the claim, the channel claim ID and
+// the payload. The first input ties the signature to the transaction.
+func (v *Value) SignatureDigest(firstInput wire.OutPoint) []byte {
+
+	h := sha256.New()
+	h.Write(firstInput.Hash[:])
+	var index [4]byte
+	binary.LittleEndian.PutUint32(index[:], firstInput.Index)
+	h.Write(index[:])
+	h.Write(v.SigningChannelID[:])
+	h.Write(v.Payload)
+
+	return h.Sum(nil)
+}
+
+// VerifySignature tells whether the value is signed by the channel with the
+// DER encoded public key, for a claim in a transaction with the first input.
+func (v *Value) VerifySignature(firstInput wire.OutPoint, publicKey []byte) bool {
+
+	if !v.Signed || len(v.Signature) != signatureSize {
+		return false
+	}
+
+	key, err := ParsePublicKey(publicKey)
+	if err != nil {
+		return false
+	}
+
+	sig := btcec.Signature{
+		R: new(big.Int).SetBytes(v.Signature[:signatureSize/2]),
+		S: new(big.Int).SetBytes(v.Signature[signatureSize/2:]),
+	}
+
+	return sig.Verify(v.SignatureDigest(firstInput), key)
+}
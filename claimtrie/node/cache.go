@@ -0,0 +1,107 @@
+package node
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+)
+
+// CacheStats reports the usage of the node cache of a Manager.
+type CacheStats struct {
+	Size      int
+	Capacity  int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// HitRatio returns the fraction of lookups served from the cache.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+func (s CacheStats) String() string {
+	return fmt.Sprintf("size: %d/%d, hits: %d, misses: %d (%.1f%% hit), evictions: %d",
+		s.Size, s.Capacity, s.Hits, s.Misses, 100*s.HitRatio(), s.Evictions)
+}
+
+type cacheEntry struct {
+	name string
+	node *Node
+}
+
+// nodeCache is a least recently used cache of nodes, bounded by the number of nodes.
+// It is safe for concurrent use.
+type nodeCache struct {
+	mtx     sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // front is the most recently used
+	stats   CacheStats
+}
+
+func newNodeCache(capacity int) *nodeCache {
+	return &nodeCache{
+		entries: map[string]*list.Element{},
+		order:   list.New(),
+		stats:   CacheStats{Capacity: capacity},
+	}
+}
+
+func (c *nodeCache) get(name string) *Node {
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	e, ok := c.entries[name]
+	if !ok {
+		c.stats.Misses++
+		return nil
+	}
+	c.stats.Hits++
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).node
+}
+
+func (c *nodeCache) put(name string, n *Node) {
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.entries[name]; ok {
+		e.Value.(*cacheEntry).node = n
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[name] = c.order.PushFront(&cacheEntry{name: name, node: n})
+	for c.order.Len() > c.stats.Capacity {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).name)
+		c.stats.Evictions++
+	}
+}
+
+func (c *nodeCache) delete(name string) {
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if e, ok := c.entries[name]; ok {
+		c.order.Remove(e)
+		delete(c.entries, name)
+	}
+}
+
+func (c *nodeCache) Stats() CacheStats {
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	return stats
+}
@@ -0,0 +1,102 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/node/noderepo"
+	"github.com/btcsuite/btcd/claimtrie/param"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeCache(t *testing.T) {
+
+	r := require.New(t)
+
+	c := newNodeCache(2)
+	a, b, d := New(), New(), New()
+
+	r.Nil(c.get("a"))
+	c.put("a", a)
+	c.put("b", b)
+	r.Same(a, c.get("a")) // "b" is now the least recently used
+
+	c.put("d", d)
+	r.Nil(c.get("b"))
+	r.Same(a, c.get("a"))
+	r.Same(d, c.get("d"))
+
+	r.Equal(CacheStats{Size: 2, Capacity: 2, Hits: 3, Misses: 2, Evictions: 1}, c.Stats())
+	r.Equal(0.6, c.Stats().HitRatio())
+
+	c.delete("a")
+	r.Nil(c.get("a"))
+	r.Equal(1, c.Stats().Size)
+}
+
+func TestManagerCache(t *testing.T) {
+
+	r := require.New(t)
+
+	param.SetNetwork(wire.TestNet)
+	repo, err := noderepo.NewPebble(t.TempDir())
+	r.NoError(err)
+
+	m, err := NewBaseManager(repo, 1)
+	r.NoError(err)
+
+	_, err = m.IncrementHeightTo(10)
+	r.NoError(err)
+
+	chg := change.New(change.AddClaim).SetName(name1).SetOutPoint(out1.String()).SetHeight(11)
+	r.NoError(m.AppendChange(chg))
+	chg = chg.SetName(name2).SetOutPoint(out2.String())
+	r.NoError(m.AppendChange(chg))
+	_, err = m.IncrementHeightTo(11)
+	r.NoError(err)
+
+	// Both names fit in the cache one at a time.
+	for i := 0; i < 2; i++ {
+		for _, name := range [][]byte{name1, name2} {
+			n, err := m.Node(name)
+			r.NoError(err)
+			r.Len(n.Claims, 1)
+		}
+	}
+	stats := m.CacheStats()
+	r.Equal(1, stats.Size)
+	r.Equal(uint64(4), stats.Misses)
+	r.Equal(uint64(3), stats.Evictions)
+
+	// A node read in the middle of a block must not hide the change once it's applied.
+	chg = change.New(change.AddClaim).SetName(name2).SetOutPoint(out3.String()).SetHeight(12)
+	r.NoError(m.AppendChange(chg))
+	n, err := m.Node(name2)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	_, err = m.IncrementHeightTo(12)
+	r.NoError(err)
+	n, err = m.Node(name2)
+	r.NoError(err)
+	r.Len(n.Claims, 2)
+
+	err = m.DecrementHeightTo([][]byte{name2}, 11)
+	r.NoError(err)
+	n, err = m.Node(name2)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	r.Equal(1, m.CacheStats().Size)
+
+	// Only the affected names are dropped from the cache.
+	_, err = m.IncrementHeightTo(12)
+	r.NoError(err)
+	err = m.DecrementHeightTo([][]byte{name1}, 11)
+	r.NoError(err)
+	hits := m.CacheStats().Hits
+	n, err = m.Node(name2)
+	r.NoError(err)
+	r.Len(n.Claims, 1)
+	r.Equal(hits+1, m.CacheStats().Hits)
+}
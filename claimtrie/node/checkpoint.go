@@ -0,0 +1,81 @@
+package node
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// checkpoint is the persisted state of a node, as built by its changes up to
+// the height of the last one, before it's adjusted to any later height.
+type checkpoint struct {
+	Claims      ClaimList
+	Supports    ClaimList
+	TakenOverAt int32
+
+	// BestClaim is the index of the best claim in Claims, or -1 if there is none.
+	// A best claim which is no longer in Claims is kept as Detached.
+	BestClaim int
+	Detached  *Claim
+
+	// The controlling claims as of the latest height adjusted to, and before
+	// the takeover at TakenOverAt, which are missing from older checkpoints.
+	Settled   controller
+	Overtaken controller
+}
+
+func marshalCheckpoint(n *Node) ([]byte, error) {
+
+	cp := checkpoint{
+		Claims:      n.Claims,
+		Supports:    n.Supports,
+		TakenOverAt: n.TakenOverAt,
+		BestClaim:   -1,
+		Settled:     n.settled,
+		Overtaken:   n.overtaken,
+	}
+
+	if n.BestClaim != nil {
+		cp.Detached = n.BestClaim
+		for i, c := range n.Claims {
+			if c == n.BestClaim {
+				cp.BestClaim = i
+				cp.Detached = nil
+				break
+			}
+		}
+	}
+
+	value, err := msgpack.Marshal(cp)
+	if err != nil {
+		return nil, fmt.Errorf("msgpack marshal checkpoint: %w", err)
+	}
+
+	return value, nil
+}
+
+func unmarshalCheckpoint(value []byte) (*Node, error) {
+
+	var cp checkpoint
+	err := msgpack.Unmarshal(value, &cp)
+	if err != nil {
+		return nil, fmt.Errorf("msgpack unmarshal checkpoint: %w", err)
+	}
+
+	n := &Node{
+		Claims:      cp.Claims,
+		Supports:    cp.Supports,
+		TakenOverAt: cp.TakenOverAt,
+		BestClaim:   cp.Detached,
+		settled:     cp.Settled,
+		overtaken:   cp.Overtaken,
+	}
+	if cp.BestClaim >= 0 {
+		if cp.BestClaim >= len(n.Claims) {
+			return nil, fmt.Errorf("best claim %d out of %d claims", cp.BestClaim, len(n.Claims))
+		}
+		n.BestClaim = n.Claims[cp.BestClaim]
+	}
+
+	return n, nil
+}
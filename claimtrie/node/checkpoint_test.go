@@ -0,0 +1,225 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/node/noderepo"
+	"github.com/btcsuite/btcd/claimtrie/param"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpointRoundTrip(t *testing.T) {
+
+	r := require.New(t)
+
+	n := New()
+	n.Claims = append(n.Claims, &Claim{OutPoint: *out1, ClaimID: "a", Amount: 3, AcceptedAt: 3, ActiveAt: 5, Status: Activated, Value: []byte("x")})
+	n.Claims = append(n.Claims, &Claim{OutPoint: *out2, ClaimID: "b", Amount: 4, AcceptedAt: 4, ActiveAt: 6, VisibleAt: 7})
+	n.Supports = append(n.Supports, &Claim{OutPoint: *out3, ClaimID: "b", Amount: 1, Status: Deactivated})
+	n.BestClaim = n.Claims[1]
+	n.TakenOverAt = 6
+
+	value, err := marshalCheckpoint(n)
+	r.NoError(err)
+	n2, err := unmarshalCheckpoint(value)
+	r.NoError(err)
+	r.Equal(n, n2)
+	r.True(n2.BestClaim == n2.Claims[1], "the best claim must be one of the claims")
+
+	// A best claim which has been removed from the claims stays apart from them.
+	n.BestClaim = &Claim{OutPoint: *out3, ClaimID: "c"}
+	value, err = marshalCheckpoint(n)
+	r.NoError(err)
+	n2, err = unmarshalCheckpoint(value)
+	r.NoError(err)
+	r.Equal(n, n2)
+
+	n.BestClaim = nil
+	value, err = marshalCheckpoint(n)
+	r.NoError(err)
+	n2, err = unmarshalCheckpoint(value)
+	r.NoError(err)
+	r.Nil(n2.BestClaim)
+}
+
+func TestPrune(t *testing.T) {
+
+	r := require.New(t)
+
+	param.SetNetwork(wire.TestNet)
+	rand.Seed(7)
+
+	full, err := NewBaseManager(noderepo.NewMemory(), 0)
+	r.NoError(err)
+	repo := noderepo.NewMemory()
+	pruned, err := NewBaseManager(repo, 0)
+	r.NoError(err)
+
+	names := [][]byte{[]byte("prune"), []byte("prunea"), []byte("pruneb")}
+	claims := map[string]map[string]string{} // name -> outpoint -> claim ID
+	supports := map[string][]string{}        // name -> outpoints
+	for _, name := range names {
+		claims[string(name)] = map[string]string{}
+	}
+
+	count := 0
+	outPoint := func() string {
+		count++
+		return fmt.Sprintf("%064x:%d", count, count%3)
+	}
+
+	prunedNames := 0
+	for height := int32(1); height <= 800; height++ {
+
+		for _, name := range names {
+			var chgs []change.Change
+			live := claims[string(name)]
+			switch rand.Intn(8) {
+			case 0, 1:
+				op := outPoint()
+				live[op] = fmt.Sprintf("%040x", count)
+				chgs = append(chgs, change.Change{Type: change.AddClaim, OutPoint: op, ClaimID: live[op], Amount: rand.Int63n(100) + 1})
+			case 2:
+				for op := range live {
+					chgs = append(chgs, change.Change{Type: change.SpendClaim, OutPoint: op, ClaimID: live[op]})
+					delete(live, op)
+					break
+				}
+			case 3:
+				for op, id := range live {
+					chgs = append(chgs, change.Change{Type: change.SpendClaim, OutPoint: op, ClaimID: id})
+					delete(live, op)
+					op = outPoint()
+					live[op] = id
+					chgs = append(chgs, change.Change{Type: change.UpdateClaim, OutPoint: op, ClaimID: id, Amount: rand.Int63n(100) + 1})
+					break
+				}
+			case 4:
+				for _, id := range live {
+					op := outPoint()
+					supports[string(name)] = append(supports[string(name)], op)
+					chgs = append(chgs, change.Change{Type: change.AddSupport, OutPoint: op, ClaimID: id, Amount: rand.Int63n(100) + 1})
+					break
+				}
+			case 5:
+				if ops := supports[string(name)]; len(ops) > 0 {
+					i := rand.Intn(len(ops))
+					chgs = append(chgs, change.Change{Type: change.SpendSupport, OutPoint: ops[i]})
+					supports[string(name)] = append(ops[:i], ops[i+1:]...)
+				}
+			}
+			for _, chg := range chgs {
+				chg.Name = name
+				chg.Height = height
+				r.NoError(full.AppendChange(chg))
+				r.NoError(pruned.AppendChange(chg))
+			}
+		}
+
+		_, err = full.IncrementHeightTo(height)
+		r.NoError(err)
+		_, err = pruned.IncrementHeightTo(height)
+		r.NoError(err)
+
+		if height%100 == 0 {
+			for _, name := range names {
+				ok, err := pruned.Prune(name, height-50)
+				r.NoError(err)
+				if ok {
+					prunedNames++
+				}
+			}
+		}
+
+		for _, name := range names {
+			n, err := full.Node(name)
+			r.NoError(err)
+			n2, err := pruned.Node(name)
+			r.NoError(err)
+			r.Equal(n, n2, "%s at %d", name, height)
+
+			if height%10 != 0 {
+				continue
+			}
+			// Bypass the cache.
+			for _, h := range []int32{height, height - 40} {
+				n, err = full.NodeAt(h, name)
+				r.NoError(err)
+				n2, err = pruned.NodeAt(h, name)
+				r.NoError(err)
+				r.Equal(n, n2, "%s at %d from %d", name, h, height)
+			}
+		}
+	}
+	r.Greater(prunedNames, 15)
+
+	changes, err := repo.LoadChanges(names[0])
+	r.NoError(err)
+	r.Equal(change.Checkpoint, changes[0].Type)
+	r.LessOrEqual(changes[0].Height, int32(750))
+	r.Greater(changes[0].Height, int32(700))
+
+	// The history below the checkpoint is gone.
+	_, err = pruned.NodeAt(changes[0].Height-1, names[0])
+	r.True(errors.Is(err, ErrPruned))
+	err = pruned.DecrementHeightTo(names, changes[0].Height-1)
+	r.True(errors.Is(err, ErrPruned))
+
+	// But the recent one can still be reset.
+	r.NoError(full.DecrementHeightTo(names, 760))
+	r.NoError(pruned.DecrementHeightTo(names, 760))
+	for _, name := range names {
+		n, err := full.Node(name)
+		r.NoError(err)
+		n2, err := pruned.Node(name)
+		r.NoError(err)
+		r.Equal(n, n2, "%s", name)
+	}
+
+	// Nothing is left to collapse.
+	ok, err := pruned.Prune(names[0], 750)
+	r.NoError(err)
+	r.False(ok)
+}
+
+func TestPrunedChildren(t *testing.T) {
+
+	r := require.New(t)
+
+	param.SetNetwork(wire.TestNet)
+
+	m, err := NewBaseManager(noderepo.NewMemory(), 0)
+	r.NoError(err)
+	nm := m.(*BaseManager)
+
+	children := [][]byte{[]byte("ab"), []byte("ac")}
+	for height := int32(1); height <= 10; height++ {
+		for i, name := range children {
+			op := fmt.Sprintf("%064x:%d", height, i)
+			chg := change.Change{Type: change.AddClaim, Name: name, Height: height, OutPoint: op, ClaimID: fmt.Sprintf("%040x", 2*height+int32(i)), Amount: 1}
+			r.NoError(nm.AppendChange(chg))
+		}
+		_, err = nm.IncrementHeightTo(height)
+		r.NoError(err)
+	}
+
+	has, err := nm.hasChildrenButNoSelf([]byte("a"), 5, 2)
+	r.NoError(err)
+	r.True(has)
+
+	// A child pruned above the height can't be taken for an empty one.
+	ok, err := nm.Prune(children[0], 8)
+	r.NoError(err)
+	r.True(ok)
+	_, err = nm.hasChildrenButNoSelf([]byte("a"), 5, 2)
+	r.True(errors.Is(err, ErrPruned))
+	has, err = nm.hasChildrenButNoSelf([]byte("a"), 9, 2)
+	r.NoError(err)
+	r.True(has)
+}
@@ -59,6 +59,18 @@ const (
 	Deactivated
 )
 
+func (s Status) String() string {
+	switch s {
+	case Accepted:
+		return "accepted"
+	case Activated:
+		return "activated"
+	case Deactivated:
+		return "deactivated"
+	}
+	return "unknown"
+}
+
 // Claim defines a structure of stake, which could be a Claim or Support.
 type Claim struct {
 	OutPoint   wire.OutPoint
@@ -0,0 +1,145 @@
+// +build ignore
+
+// This program generates normalizer_tables.go from the ICU library it is linked
+// with, which must be version 63 or later. The tables are restricted to the
+// characters assigned in Unicode 11.0, the version of ICU 63.2 used by lbrycrd.
+// As the decompositions, combining classes and case foldings of assigned
+// characters are stable across versions, they match those of ICU 63.2.
+//
+// Run it with "go generate" in the node package.
+package main
+
+// #cgo LDFLAGS: -licuuc -licudata
+// #include <unicode/uchar.h>
+// #include <unicode/unorm2.h>
+// #include <unicode/ustring.h>
+// #include <unicode/utf16.h>
+// #include <unicode/uversion.h>
+// int icu_version() {
+//    UVersionInfo info;
+//    u_getVersion(info);
+//    return ((int)(info[0]) << 16) + info[1];
+// }
+// int char_age(UChar32 c) {
+//    UVersionInfo info;
+//    u_charAge(c, info);
+//    return ((int)(info[0]) << 16) + info[1];
+// }
+// int is_assigned(UChar32 c) {
+//    return u_charType(c) != U_UNASSIGNED;
+// }
+// int combining_class(UChar32 c) {
+//    return u_getCombiningClass(c);
+// }
+// int decompose(UChar32 c, UChar* dest, int capacity) {
+//   UErrorCode ec = U_ZERO_ERROR;
+//   const UNormalizer2* normalizer = unorm2_getNFDInstance(&ec);
+//   if (U_FAILURE(ec)) return -1;
+//   int len = unorm2_getDecomposition(normalizer, c, dest, capacity, &ec);
+//   if (U_FAILURE(ec)) return -1;
+//   return len;
+// }
+// int fold(UChar32 c, UChar* dest, int capacity) {
+//   UChar src[2];
+//   int32_t len = 0;
+//   UBool err = 0;
+//   U16_APPEND(src, len, 2, c, err);
+//   UErrorCode ec = U_ZERO_ERROR;
+//   len = u_strFoldCase(dest, capacity, src, len, U_FOLD_CASE_DEFAULT, &ec);
+//   if (U_FAILURE(ec)) return -1;
+//   return len;
+// }
+import "C"
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"unicode/utf16"
+	"unsafe"
+)
+
+const unicodeVersion = 11<<16 | 0
+
+func main() {
+
+	version := C.icu_version()
+	if version < 63<<16 {
+		log.Fatalf("ICU %d.%d is older than 63", version>>16, version&0xffff)
+	}
+
+	var decompositions, foldings bytes.Buffer
+	var classes bytes.Buffer
+
+	for c := rune(0); c <= 0x10FFFF; c++ {
+		if c >= 0xD800 && c <= 0xDFFF {
+			continue // surrogates aren't valid in UTF-8
+		}
+		if C.is_assigned(C.UChar32(c)) == 0 || C.char_age(C.UChar32(c)) > unicodeVersion {
+			continue // unassigned in ICU 63.2
+		}
+
+		if ccc := C.combining_class(C.UChar32(c)); ccc != 0 {
+			fmt.Fprintf(&classes, "%#04x: %d,\n", c, ccc)
+		}
+
+		// Hangul syllables are decomposed algorithmically.
+		if c < hangulBase || c >= hangulBase+hangulCount {
+			if d, ok := icuString(c, decompose); ok {
+				fmt.Fprintf(&decompositions, "%#04x: %+q,\n", c, d)
+			}
+		}
+
+		if f, ok := icuString(c, fold); ok && f != string(c) {
+			fmt.Fprintf(&foldings, "%#04x: %+q,\n", c, f)
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by gen_normalizer_tables.go with ICU %d.%d. DO NOT EDIT.\n\n", version>>16, version&0xffff)
+	fmt.Fprintf(&b, "package node\n\n")
+	fmt.Fprintf(&b, "// nfdDecompositions maps the characters of Unicode 11.0 to their full canonical decompositions,\n")
+	fmt.Fprintf(&b, "// except for the Hangul syllables.\n")
+	fmt.Fprintf(&b, "var nfdDecompositions = map[rune]string{\n%s}\n\n", decompositions.Bytes())
+	fmt.Fprintf(&b, "// combiningClasses maps the characters of Unicode 11.0 to their non-zero canonical combining classes.\n")
+	fmt.Fprintf(&b, "var combiningClasses = map[rune]uint8{\n%s}\n\n", classes.Bytes())
+	fmt.Fprintf(&b, "// caseFoldings maps the characters of Unicode 11.0 to their full default case foldings.\n")
+	fmt.Fprintf(&b, "var caseFoldings = map[rune]string{\n%s}\n", foldings.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatalf("format: %s", err)
+	}
+
+	err = ioutil.WriteFile("normalizer_tables.go", src, 0644)
+	if err != nil {
+		log.Fatalf("write: %s", err)
+	}
+}
+
+const (
+	hangulBase  = 0xAC00
+	hangulCount = 11172
+)
+
+func decompose(c C.UChar32, dest *C.UChar, capacity C.int) C.int {
+	return C.decompose(c, dest, capacity)
+}
+
+func fold(c C.UChar32, dest *C.UChar, capacity C.int) C.int {
+	return C.fold(c, dest, capacity)
+}
+
+// icuString returns the string computed by f for the character, if any.
+func icuString(c rune, f func(C.UChar32, *C.UChar, C.int) C.int) (string, bool) {
+
+	var dest [32]uint16
+	n := f(C.UChar32(c), (*C.UChar)(unsafe.Pointer(&dest[0])), C.int(len(dest)))
+	if n < 0 {
+		return "", false
+	}
+
+	return string(utf16.Decode(dest[:n])), true
+}
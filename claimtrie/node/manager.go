@@ -1,12 +1,16 @@
 package node
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
 	"github.com/btcsuite/btcd/claimtrie/change"
 	"github.com/btcsuite/btcd/claimtrie/param"
 	"github.com/btcsuite/btcd/wire"
@@ -19,25 +23,65 @@ type Manager interface {
 	Height() int32
 	Close() error
 	Node(name []byte) (*Node, error)
+	NodeAt(height int32, name []byte) (*Node, error)
 	NextUpdateHeightOfNode(name []byte) ([]byte, int32)
 	IterateNames(predicate func(name []byte) bool)
 	ClaimHashes(name []byte) []*chainhash.Hash
 	Hash(name []byte) *chainhash.Hash
+	CacheStats() CacheStats
+	Anomalies() []anomaly.Anomaly
+	Prune(name []byte, height int32) (bool, error)
+	Simulate(chg change.Change) (*Simulation, error)
 }
 
+// ErrPruned is returned when a node is requested at a height whose changes
+// have been collapsed into a checkpoint.
+var ErrPruned = errors.New("node history is pruned")
+
 type BaseManager struct {
 	repo Repo
 
+	// repoMtx serializes the writes to the repo, as nodes may be pruned in the background.
+	repoMtx sync.Mutex
+
 	height  int32
-	cache   map[string]*Node
+	cache   *nodeCache
 	changes []change.Change
+
+	// anomalies holds those found at the current height until they are collected.
+	anomalies    []anomaly.Anomaly
+	anomaliesMtx sync.Mutex
 }
 
-func NewBaseManager(repo Repo) (Manager, error) {
+// NewBaseManager returns a Manager which keeps up to cacheSize nodes in memory.
+// A cacheSize of zero selects param.MaxNodeManagerCacheSize.
+func NewBaseManager(repo Repo, cacheSize int) (Manager, error) {
+
+	if cacheSize <= 0 {
+		cacheSize = param.MaxNodeManagerCacheSize
+	}
 
 	nm := &BaseManager{
 		repo:  repo,
-		cache: map[string]*Node{},
+		cache: newNodeCache(cacheSize),
+	}
+
+	return nm, nil
+}
+
+// NewBaseManagerAt returns a Manager of the nodes of the repo as of the height,
+// which the repo must have reached, such as a snapshot of the repo taken at that
+// height. It keeps up to cacheSize nodes in memory, as NewBaseManager.
+func NewBaseManagerAt(repo Repo, height int32, cacheSize int) (Manager, error) {
+
+	if cacheSize <= 0 {
+		cacheSize = param.MaxNodeManagerCacheSize
+	}
+
+	nm := &BaseManager{
+		repo:   repo,
+		cache:  newNodeCache(cacheSize),
+		height: height,
 	}
 
 	return nm, nil
@@ -45,11 +89,12 @@ func NewBaseManager(repo Repo) (Manager, error) {
 
 // Node returns a node at the current height.
 // The returned node may have pending changes.
+// It is safe to call concurrently for different names, as long as no changes are made.
 func (nm *BaseManager) Node(name []byte) (*Node, error) {
 
 	nameStr := string(name)
-	n, ok := nm.cache[nameStr]
-	if ok && n != nil {
+	n := nm.cache.get(nameStr)
+	if n != nil {
 		return n.AdjustTo(nm.height, -1, name), nil
 	}
 
@@ -58,7 +103,7 @@ func (nm *BaseManager) Node(name []byte) (*Node, error) {
 		return nil, fmt.Errorf("load changes from node repo: %w", err)
 	}
 
-	n, err = nm.newNodeFromChanges(changes, nm.height)
+	n, err = nm.newNodeFromChanges(changes, nm.height, true)
 	if err != nil {
 		return nil, fmt.Errorf("create node from changes: %w", err)
 	}
@@ -67,25 +112,78 @@ func (nm *BaseManager) Node(name []byte) (*Node, error) {
 		return nil, nil
 	}
 
-	nm.cache[nameStr] = n
+	nm.cache.put(nameStr, n)
+	return n, nil
+}
+
+// NodeAt returns a node as it was at the specified height.
+// Neither the cache nor the state of the manager is altered, and the anomalies
+// are left to the nodes built by Node.
+func (nm *BaseManager) NodeAt(height int32, name []byte) (*Node, error) {
+
+	if height > nm.height {
+		return nil, fmt.Errorf("height %d is beyond the current height %d", height, nm.height)
+	}
+
+	changes, err := nm.repo.LoadChanges(name)
+	if err != nil {
+		return nil, fmt.Errorf("load changes from node repo: %w", err)
+	}
+
+	n, err := nm.newNodeFromChanges(changes, height, false)
+	if err != nil {
+		return nil, fmt.Errorf("create node from changes: %w", err)
+	}
+
 	return n, nil
 }
 
 // newNodeFromChanges returns a new Node constructed from the changes.
 // The changes must preserve their order received.
-func (nm *BaseManager) newNodeFromChanges(changes []change.Change, height int32) (*Node, error) {
+// The anomalies of the current height are reported if requested.
+func (nm *BaseManager) newNodeFromChanges(changes []change.Change, height int32, report bool) (*Node, error) {
+
+	n, last, err := nm.replayChanges(changes, height, report)
+	if err != nil || n == nil {
+		return nil, err
+	}
+
+	return n.AdjustTo(last.Height, height, last.Name), nil
+}
+
+// replayChanges applies the changes up to the height to a new node, and returns
+// it with the last change applied, without adjusting it to the height.
+// The anomalies of the current height are reported if requested.
+func (nm *BaseManager) replayChanges(changes []change.Change, height int32, report bool) (*Node, *change.Change, error) {
 
 	if len(changes) == 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	n := New()
+	if changes[0].Type == change.Checkpoint {
+		if changes[0].Height > height {
+			return nil, nil, fmt.Errorf("%w up to height %d of %q", ErrPruned, changes[0].Height, changes[0].Name)
+		}
+		var err error
+		n, err = unmarshalCheckpoint(changes[0].Value)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	previous := changes[0].Height
 	count := len(changes)
 
 	for i, chg := range changes {
+		if chg.Type == change.Checkpoint {
+			if i > 0 {
+				return nil, nil, fmt.Errorf("expected the checkpoint to be the first change")
+			}
+			continue
+		}
 		if chg.Height < previous {
-			return nil, fmt.Errorf("expected the changes to be in order by height")
+			return nil, nil, fmt.Errorf("expected the changes to be in order by height")
 		}
 		if chg.Height > height {
 			count = i
@@ -96,33 +194,29 @@ func (nm *BaseManager) newNodeFromChanges(changes []change.Change, height int32)
 			previous = chg.Height
 		}
 
-		delay := nm.getDelayForName(n, chg)
-		err := n.ApplyChange(chg, delay)
+		delay, err := nm.getDelayForName(n, chg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("delay of change: %w", err)
+		}
+		typ, err := n.ApplyChange(chg, delay)
 		if err != nil {
-			return nil, fmt.Errorf("append change: %w", err)
+			return nil, nil, fmt.Errorf("append change: %w", err)
+		}
+		if typ != 0 && report && chg.Height == nm.height {
+			// The changes of previous heights were reported when their nodes were first built.
+			nm.addAnomaly(anomaly.Anomaly{Type: typ, Height: chg.Height, Name: chg.Name, ClaimID: chg.ClaimID, OutPoint: chg.OutPoint})
 		}
 	}
 
 	if count <= 0 {
-		return nil, nil
+		return nil, nil, nil
 	}
-	lastChange := changes[count-1]
-	return n.AdjustTo(lastChange.Height, height, lastChange.Name), nil
+	return n, &changes[count-1], nil
 }
 
 func (nm *BaseManager) AppendChange(chg change.Change) error {
 
-	if len(nm.changes) <= 0 {
-		// this little code block is acting as a "block complete" method
-		// that could be called after the merkle hash is complete
-		if len(nm.cache) > param.MaxNodeManagerCacheSize {
-			// TODO: use a better cache model?
-			fmt.Printf("Clearing manager cache at height %d\n", nm.height)
-			nm.cache = map[string]*Node{}
-		}
-	}
-
-	delete(nm.cache, string(chg.Name))
+	nm.cache.delete(string(chg.Name))
 	nm.changes = append(nm.changes, chg)
 
 	return nil
@@ -137,9 +231,14 @@ func (nm *BaseManager) IncrementHeightTo(height int32) ([][]byte, error) {
 	names := make([][]byte, 0, len(nm.changes))
 	for i := range nm.changes {
 		names = append(names, nm.changes[i].Name)
+		// The node may have been cached without the change, if it was read after AppendChange.
+		nm.cache.delete(string(nm.changes[i].Name))
 	}
 
-	if err := nm.repo.AppendChanges(nm.changes); err != nil {
+	nm.repoMtx.Lock()
+	err := nm.repo.AppendChanges(nm.changes)
+	nm.repoMtx.Unlock()
+	if err != nil {
 		return nil, fmt.Errorf("save changes to node repo: %w", err)
 	}
 
@@ -159,8 +258,23 @@ func (nm *BaseManager) DecrementHeightTo(affectedNames [][]byte, height int32) e
 		return fmt.Errorf("invalid height")
 	}
 
+	nm.repoMtx.Lock()
+	defer nm.repoMtx.Unlock()
+
 	for _, name := range affectedNames {
-		delete(nm.cache, string(name))
+		changes, err := nm.repo.LoadChanges(name)
+		if err != nil {
+			return fmt.Errorf("load changes from node repo: %w", err)
+		}
+		if len(changes) > 0 && changes[0].Type == change.Checkpoint && changes[0].Height > height {
+			return fmt.Errorf("%w up to height %d of %q", ErrPruned, changes[0].Height, name)
+		}
+	}
+
+	nm.Anomalies() // they belong to the blocks being removed
+
+	for _, name := range affectedNames {
+		nm.cache.delete(string(name))
 		if err := nm.repo.DropChanges(name, height); err != nil {
 			return err
 		}
@@ -171,31 +285,82 @@ func (nm *BaseManager) DecrementHeightTo(affectedNames [][]byte, height int32) e
 	return nil
 }
 
-func (nm *BaseManager) getDelayForName(n *Node, chg change.Change) int32 {
+// Prune collapses the changes of the node up to the height into a checkpoint,
+// which holds the state they built. The node can't be built, nor reset, below
+// the height of the last collapsed change afterwards. The height must be below
+// the current height, and past the normalization fork, which appends changes of
+// previous heights. It returns whether the node had changes to collapse.
+//
+// It's safe to call concurrently with the other methods, but not with itself
+// for the same name.
+func (nm *BaseManager) Prune(name []byte, height int32) (bool, error) {
+
+	nm.repoMtx.Lock()
+	defer nm.repoMtx.Unlock()
+
+	changes, err := nm.repo.LoadChanges(name)
+	if err != nil {
+		return false, fmt.Errorf("load changes from node repo: %w", err)
+	}
+
+	count := 0
+	for count < len(changes) && changes[count].Height <= height {
+		count++
+	}
+	if count < 2 {
+		return false, nil // nothing to collapse
+	}
+
+	n, last, err := nm.replayChanges(changes[:count], height, false)
+	if err != nil {
+		return false, fmt.Errorf("replay changes of %q: %w", name, err)
+	}
+
+	value, err := marshalCheckpoint(n)
+	if err != nil {
+		return false, err
+	}
+
+	pruned := make([]change.Change, 0, len(changes)-count+1)
+	pruned = append(pruned, change.Change{Type: change.Checkpoint, Height: last.Height, Name: last.Name, Value: value})
+	pruned = append(pruned, changes[count:]...)
+
+	err = nm.repo.SetChanges(name, pruned)
+	if err != nil {
+		return false, fmt.Errorf("set changes to node repo: %w", err)
+	}
+
+	return true, nil
+}
+
+func (nm *BaseManager) getDelayForName(n *Node, chg change.Change) (int32, error) {
 	hasBest := n.BestClaim != nil // && n.BestClaim.Status == Activated
 	if hasBest && n.BestClaim.ClaimID == chg.ClaimID {
-		return 0
+		return 0, nil
 	}
 	if chg.ActiveHeight >= chg.Height { // ActiveHeight is usually unset (aka, zero)
-		return chg.ActiveHeight - chg.Height
+		return chg.ActiveHeight - chg.Height, nil
 	}
 	if !hasBest {
-		return 0
+		return 0, nil
 	}
 
-	needsWorkaround := nm.decideIfWorkaroundNeeded(n, chg)
+	needsWorkaround, err := nm.decideIfWorkaroundNeeded(n, chg)
+	if err != nil {
+		return 0, err
+	}
 
 	delay := calculateDelay(chg.Height, n.TakenOverAt)
 	if delay > 0 && needsWorkaround {
 		// TODO: log this (but only once per name-height combo)
 		//fmt.Printf("Delay workaround applies to %s at %d\n", chg.Name, chg.Height)
-		return 0
+		return 0, nil
 	}
-	return delay
+	return delay, nil
 }
 
 // decideIfWorkaroundNeeded handles bugs that existed in previous versions
-func (nm *BaseManager) decideIfWorkaroundNeeded(n *Node, chg change.Change) bool {
+func (nm *BaseManager) decideIfWorkaroundNeeded(n *Node, chg change.Change) (bool, error) {
 
 	if chg.Height >= param.MaxRemovalWorkaroundHeight {
 		// TODO: hard fork this out; it's a bug from previous versions:
@@ -208,15 +373,13 @@ func (nm *BaseManager) decideIfWorkaroundNeeded(n *Node, chg change.Change) bool
 						//hc := nm.hasChildrenButNoSelf(chg.Name, chg.Height, 2)
 						hc := true
 						fmt.Printf("HC: %s: %t\n", chg.Name, hc)
-						return true
+						return true, nil
 					}
 				}
 			}
 		} else {
 			// Known hits:
-			if nm.hasChildrenButNoSelf(chg.Name, chg.Height, 2) {
-				return true
-			}
+			return nm.hasChildrenButNoSelf(chg.Name, chg.Height, 2)
 		}
 	} else if len(n.Claims) > 0 {
 		// NOTE: old code had a bug in it where nodes with no claims but with children would get left in the cache after removal.
@@ -225,12 +388,12 @@ func (nm *BaseManager) decideIfWorkaroundNeeded(n *Node, chg change.Change) bool
 		if ok {
 			for _, h := range w {
 				if chg.Height == h {
-					return true
+					return true, nil
 				}
 			}
 		}
 	}
-	return false
+	return false, nil
 }
 
 func calculateDelay(curr, tookOver int32) int32 {
@@ -243,7 +406,7 @@ func calculateDelay(curr, tookOver int32) int32 {
 	return delay
 }
 
-func (nm BaseManager) NextUpdateHeightOfNode(name []byte) ([]byte, int32) {
+func (nm *BaseManager) NextUpdateHeightOfNode(name []byte) ([]byte, int32) {
 
 	n, err := nm.Node(name)
 	if err != nil || n == nil {
@@ -253,6 +416,38 @@ func (nm BaseManager) NextUpdateHeightOfNode(name []byte) ([]byte, int32) {
 	return name, n.NextUpdate()
 }
 
+func (nm *BaseManager) addAnomaly(a anomaly.Anomaly) {
+
+	nm.anomaliesMtx.Lock()
+	defer nm.anomaliesMtx.Unlock()
+
+	// The node may be built more than once at the same height.
+	for _, b := range nm.anomalies {
+		if a.Type == b.Type && a.Height == b.Height && a.ClaimID == b.ClaimID &&
+			a.OutPoint == b.OutPoint && bytes.Equal(a.Name, b.Name) {
+			return
+		}
+	}
+	nm.anomalies = append(nm.anomalies, a)
+}
+
+// Anomalies returns the anomalies found since the previous call, when the
+// changes of the current height were applied to the nodes.
+func (nm *BaseManager) Anomalies() []anomaly.Anomaly {
+
+	nm.anomaliesMtx.Lock()
+	defer nm.anomaliesMtx.Unlock()
+
+	anomalies := nm.anomalies
+	nm.anomalies = nil
+	return anomalies
+}
+
+// CacheStats returns the usage of the node cache.
+func (nm *BaseManager) CacheStats() CacheStats {
+	return nm.cache.Stats()
+}
+
 func (nm *BaseManager) Height() int32 {
 	return nm.height
 }
@@ -267,16 +462,27 @@ func (nm *BaseManager) Close() error {
 	return nil
 }
 
-func (nm *BaseManager) hasChildrenButNoSelf(name []byte, height int32, required int) bool {
+// hasChildrenButNoSelf returns whether the children of the name hold activated
+// claims at the height under the required number of distinct next bytes. The
+// children must be buildable at the height, so an error, including ErrPruned,
+// is returned rather than treating them as empty.
+func (nm *BaseManager) hasChildrenButNoSelf(name []byte, height int32, required int) (bool, error) {
 	c := map[byte]bool{}
 
+	var err error
 	nm.repo.IterateChildren(name, func(changes []change.Change) bool {
 		// if the key is unseen, generate a node for it to height
 		// if that node is active then increase the count
 		if len(changes) == 0 {
 			return true
 		}
-		n, _ := nm.newNodeFromChanges(changes, height)
+		// The anomalies of the children are reported when they're built for themselves.
+		var n *Node
+		n, err = nm.newNodeFromChanges(changes, height, false)
+		if err != nil {
+			err = fmt.Errorf("child %q: %w", changes[0].Name, err)
+			return false
+		}
 		if n != nil && n.BestClaim != nil && n.BestClaim.Status == Activated {
 			if len(name) >= len(changes[0].Name) {
 				return false // hit self
@@ -288,7 +494,10 @@ func (nm *BaseManager) hasChildrenButNoSelf(name []byte, height int32, required
 		}
 		return true
 	})
-	return len(c) >= required
+	if err != nil {
+		return false, err
+	}
+	return len(c) >= required, nil
 }
 
 func (nm *BaseManager) IterateNames(predicate func(name []byte) bool) {
@@ -301,31 +510,20 @@ func (nm *BaseManager) ClaimHashes(name []byte) []*chainhash.Hash {
 	if err != nil || n == nil {
 		return nil
 	}
-	n.SortClaims()
-	claimHashes := make([]*chainhash.Hash, 0, len(n.Claims))
-	for _, c := range n.Claims {
-		if c.Status == Activated { // TODO: unit test this line
-			claimHashes = append(claimHashes, calculateNodeHash(c.OutPoint, n.TakenOverAt))
-		}
-	}
-	return claimHashes
+	return n.ClaimHashes()
 }
 
 func (nm *BaseManager) Hash(name []byte) *chainhash.Hash {
 
 	n, err := nm.Node(name)
-	if err != nil {
+	if err != nil || n == nil {
 		return nil
 	}
-	if n != nil && len(n.Claims) > 0 {
-		if n.BestClaim != nil && n.BestClaim.Status == Activated {
-			return calculateNodeHash(n.BestClaim.OutPoint, n.TakenOverAt)
-		}
-	}
-	return nil
+	return n.Hash()
 }
 
-func calculateNodeHash(op wire.OutPoint, takeover int32) *chainhash.Hash {
+// CalculateNodeHash returns the hash of a claim as committed to the MerkleTrie.
+func CalculateNodeHash(op wire.OutPoint, takeover int32) *chainhash.Hash {
 
 	txHash := chainhash.DoubleHashH(op.Hash[:])
 
@@ -49,7 +49,7 @@ func TestSimpleAddClaim(t *testing.T) {
 	repo, err := noderepo.NewPebble(t.TempDir())
 	r.NoError(err)
 
-	m, err := NewBaseManager(repo)
+	m, err := NewBaseManager(repo, 0)
 	r.NoError(err)
 
 	_, err = m.IncrementHeightTo(10)
@@ -90,6 +90,62 @@ func TestSimpleAddClaim(t *testing.T) {
 	r.Nil(n2)
 }
 
+func TestOvertaken(t *testing.T) {
+
+	r := require.New(t)
+
+	param.SetNetwork(wire.TestNet)
+	repo, err := noderepo.NewPebble(t.TempDir())
+	r.NoError(err)
+
+	m, err := NewBaseManager(repo, 0)
+	r.NoError(err)
+
+	_, err = m.IncrementHeightTo(10)
+	r.NoError(err)
+
+	id1 := NewClaimID(*out1).String()
+	id2 := NewClaimID(*out2).String()
+
+	chg := change.New(change.AddClaim).SetName(name1).SetOutPoint(out1.String()).SetClaimID(id1).
+		SetAmount(10).SetHeight(11)
+	r.NoError(m.AppendChange(chg))
+	_, err = m.IncrementHeightTo(11)
+	r.NoError(err)
+
+	n, err := m.Node(name1)
+	r.NoError(err)
+	id, amount := n.Overtaken()
+	r.Equal("", id)
+	r.Equal(int64(0), amount)
+
+	// The spent claim is overtaken with its amount from before the spend.
+	chg = change.New(change.SpendClaim).SetName(name1).SetOutPoint(out1.String()).SetHeight(12)
+	r.NoError(m.AppendChange(chg))
+	chg = change.New(change.AddClaim).SetName(name1).SetOutPoint(out2.String()).SetClaimID(id2).
+		SetAmount(20).SetHeight(12)
+	r.NoError(m.AppendChange(chg))
+	_, err = m.IncrementHeightTo(12)
+	r.NoError(err)
+
+	n, err = m.Node(name1)
+	r.NoError(err)
+	r.Equal(int32(12), n.TakenOverAt)
+	r.Equal(id2, n.BestClaim.ClaimID)
+	id, amount = n.Overtaken()
+	r.Equal(id1, id)
+	r.Equal(int64(10), amount)
+
+	// It's kept as the cached node is adjusted to later heights.
+	_, err = m.IncrementHeightTo(13)
+	r.NoError(err)
+	n, err = m.Node(name1)
+	r.NoError(err)
+	id, amount = n.Overtaken()
+	r.Equal(id1, id)
+	r.Equal(int64(10), amount)
+}
+
 func TestNodeSort(t *testing.T) {
 
 	r := require.New(t)
@@ -5,18 +5,28 @@ import (
 	"math"
 	"sort"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
 	"github.com/btcsuite/btcd/claimtrie/change"
 	"github.com/btcsuite/btcd/claimtrie/param"
 )
 
-// ErrNotFound is returned when a claim or support is not found.
-var mispents = map[string]bool{}
-
 type Node struct {
 	BestClaim   *Claim    // The claim that has most effective amount at the current height.
 	TakenOverAt int32     // The height at when the current BestClaim took over.
 	Claims      ClaimList // List of all Claims.
 	Supports    ClaimList // List of all Supports, including orphaned ones.
+
+	settled   controller // The controlling claim as of the latest height the node was adjusted to.
+	prior     controller // The controlling claim as of the height adjusted to before that one.
+	overtaken controller // The controlling claim replaced by the current BestClaim.
+}
+
+// controller is a claim controlling a node, with its effective amount, as of a height.
+type controller struct {
+	ClaimID string
+	Amount  int64
+	Height  int32
 }
 
 // New returns a new node.
@@ -24,7 +34,12 @@ func New() *Node {
 	return &Node{}
 }
 
-func (n *Node) ApplyChange(chg change.Change, delay int32) error {
+// ApplyChange applies the change to the node. Inconsistencies such as spending a
+// missing claim are tolerated for compatibility, and reported by the returned
+// anomaly type, which is zero if there are none.
+func (n *Node) ApplyChange(chg change.Change, delay int32) (anomaly.Type, error) {
+
+	var found anomaly.Type
 
 	out := NewOutPointFromString(chg.OutPoint)
 
@@ -46,7 +61,7 @@ func (n *Node) ApplyChange(chg change.Change, delay int32) error {
 		}
 		old := n.Claims.find(byOut(*out)) // TODO: remove this after proving ResetHeight works
 		if old != nil {
-			fmt.Printf("CONFLICT WITH EXISTING TXO! Name: %s, Height: %d\n", chg.Name, chg.Height)
+			found = anomaly.ConflictingOutPoint
 		}
 		n.Claims = append(n.Claims, c)
 
@@ -54,10 +69,8 @@ func (n *Node) ApplyChange(chg change.Change, delay int32) error {
 		c := n.Claims.find(byOut(*out))
 		if c != nil {
 			c.setStatus(Deactivated)
-		} else if !mispents[fmt.Sprintf("%d_%s", chg.Height, chg.ClaimID)] {
-			mispents[fmt.Sprintf("%d_%s", chg.Height, chg.ClaimID)] = true
-			fmt.Printf("Spending claim but missing existing claim with TXO %s\n   "+
-				"Name: %s, ID: %s\n", chg.OutPoint, chg.Name, chg.ClaimID)
+		} else {
+			found = anomaly.MissingSpentClaim
 		}
 		// apparently it's legit to be absent in the map:
 		// 'two' at 481100, 36a719a156a1df178531f3c712b8b37f8e7cc3b36eea532df961229d936272a1:0
@@ -78,7 +91,7 @@ func (n *Node) ApplyChange(chg change.Change, delay int32) error {
 			c.setActiveAt(chg.Height + delay) // TODO: Fork this out
 
 		} else {
-			fmt.Printf("Updating claim but missing existing claim with ID %s", chg.ClaimID)
+			found = anomaly.MissingUpdatedClaim
 		}
 	case change.AddSupport:
 		n.Supports = append(n.Supports, &Claim{
@@ -96,11 +109,10 @@ func (n *Node) ApplyChange(chg change.Change, delay int32) error {
 		if s != nil {
 			s.setStatus(Deactivated)
 		} else {
-			fmt.Printf("Spending support but missing existing support with TXO %s\n   "+
-				"Name: %s, ID: %s\n", chg.OutPoint, chg.Name, chg.ClaimID)
+			found = anomaly.MissingSpentSupport
 		}
 	}
-	return nil
+	return found, nil
 }
 
 // AdjustTo activates claims and computes takeovers until it reaches the specified height.
@@ -119,6 +131,11 @@ func (n *Node) AdjustTo(height, maxHeight int32, name []byte) *Node {
 
 func (n *Node) updateTakeoverHeight(height int32, name []byte, refindBest bool) {
 
+	if height != n.settled.Height {
+		// The changes applied since don't alter the claim which controlled before them.
+		n.prior = n.settled
+	}
+
 	candidate := n.BestClaim
 	if refindBest {
 		candidate = n.findBestClaim() // so expensive...
@@ -145,9 +162,23 @@ func (n *Node) updateTakeoverHeight(height int32, name []byte, refindBest bool)
 	if takeoverHappening {
 		n.TakenOverAt = height
 		n.BestClaim = candidate
+		n.overtaken = n.prior
+	}
+
+	n.settled = controller{Height: height}
+	if n.BestClaim != nil && n.BestClaim.Status == Activated {
+		n.settled.ClaimID = n.BestClaim.ClaimID
+		n.settled.Amount = n.BestClaim.EffectiveAmount(n.Supports)
 	}
 }
 
+// Overtaken returns the ID and the effective amount of the claim which controlled
+// the node before the takeover at TakenOverAt, as of the height before it, or an
+// empty ID if there was none.
+func (n *Node) Overtaken() (string, int64) {
+	return n.overtaken.ClaimID, n.overtaken.Amount
+}
+
 func (n *Node) handleExpiredAndActivated(height int32) int {
 
 	changes := 0
@@ -298,3 +329,29 @@ func (n *Node) SortClaims() {
 		return OutPointLess(n.Claims[j].OutPoint, n.Claims[i].OutPoint)
 	})
 }
+
+// ClaimHashes returns the hashes of the activated claims, ordered by their
+// effective amounts, as committed to the MerkleTrie after the AllClaimsInMerkle fork.
+func (n *Node) ClaimHashes() []*chainhash.Hash {
+
+	n.SortClaims()
+	claimHashes := make([]*chainhash.Hash, 0, len(n.Claims))
+	for _, c := range n.Claims {
+		if c.Status == Activated { // TODO: unit test this line
+			claimHashes = append(claimHashes, CalculateNodeHash(c.OutPoint, n.TakenOverAt))
+		}
+	}
+	return claimHashes
+}
+
+// Hash returns the hash of the best claim, as committed to the MerkleTrie
+// before the AllClaimsInMerkle fork, or nil if there is no activated best claim.
+func (n *Node) Hash() *chainhash.Hash {
+
+	if len(n.Claims) > 0 {
+		if n.BestClaim != nil && n.BestClaim.Status == Activated {
+			return CalculateNodeHash(n.BestClaim.OutPoint, n.TakenOverAt)
+		}
+	}
+	return nil
+}
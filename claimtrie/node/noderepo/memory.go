@@ -0,0 +1,172 @@
+package noderepo
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/btcsuite/btcd/claimtrie/change"
+)
+
+type Memory struct {
+	// The nodes may be pruned in the background.
+	mu      sync.RWMutex
+	changes map[string][]change.Change
+
+	// readOnly is set on the snapshots of the repo.
+	readOnly bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		changes: map[string][]change.Change{},
+	}
+}
+
+// AppendChanges makes an assumption that anything you pass to it is newer than what was saved before.
+func (repo *Memory) AppendChanges(changes []change.Change) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, chg := range changes {
+		name := string(chg.Name)
+		repo.changes[name] = append(repo.changes[name], chg)
+	}
+
+	return nil
+}
+
+func (repo *Memory) LoadChanges(name []byte) ([]change.Change, error) {
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return sortedChanges(repo.changes[string(name)]), nil
+}
+
+// sortedChanges returns a copy of the changes in the order of the Pebble repo.
+func sortedChanges(stored []change.Change) []change.Change {
+
+	if len(stored) == 0 {
+		return nil
+	}
+
+	changes := append([]change.Change(nil), stored...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Height < changes[j].Height
+	})
+
+	return changes
+}
+
+func (repo *Memory) DropChanges(name []byte, finalHeight int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	changes := sortedChanges(repo.changes[string(name)])
+
+	i := 0
+	for ; i < len(changes); i++ {
+		if changes[i].Height > finalHeight {
+			break
+		}
+	}
+
+	// Like the Pebble repo, the name is kept even if no changes remain.
+	repo.changes[string(name)] = changes[:i:i]
+
+	return nil
+}
+
+func (repo *Memory) SetChanges(name []byte, changes []change.Change) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.changes[string(name)] = append([]change.Change(nil), changes...)
+
+	return nil
+}
+
+// sortedNames returns the names in the byte order of the Pebble repo.
+func (repo *Memory) sortedNames() []string {
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	names := make([]string, 0, len(repo.changes))
+	for name := range repo.changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (repo *Memory) IterateChildren(name []byte, f func(changes []change.Change) bool) {
+
+	for _, key := range repo.sortedNames() {
+		if !bytes.HasPrefix([]byte(key), name) {
+			continue
+		}
+		changes, _ := repo.LoadChanges([]byte(key))
+		if !f(changes) {
+			return
+		}
+	}
+}
+
+func (repo *Memory) IterateAll(predicate func(name []byte) bool) {
+
+	for _, key := range repo.sortedNames() {
+		if !predicate([]byte(key)) {
+			break
+		}
+	}
+}
+
+func (repo *Memory) IterateFrom(start []byte, predicate func(name []byte) bool) {
+
+	for _, key := range repo.sortedNames() {
+		if key < string(start) {
+			continue
+		}
+		if !predicate([]byte(key)) {
+			break
+		}
+	}
+}
+
+// Snapshot returns a read-only copy of the repo as it is now.
+// It isn't part of node.Repo, as the tests of package node import this package.
+func (repo *Memory) Snapshot() (*Memory, error) {
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	// The changes are replaced or appended to, so their slices are shared up to their length.
+	snap := &Memory{changes: make(map[string][]change.Change, len(repo.changes)), readOnly: true}
+	for name, changes := range repo.changes {
+		snap.changes[name] = changes[:len(changes):len(changes)]
+	}
+
+	return snap, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
@@ -35,6 +35,17 @@ func TestPebble(t *testing.T) {
 	testNodeRepo(t, repo, func() {}, cleanup)
 }
 
+func TestMemory(t *testing.T) {
+
+	repo := NewMemory()
+
+	cleanup := func() {
+		delete(repo.changes, string(testNodeName1))
+	}
+
+	testNodeRepo(t, repo, func() {}, cleanup)
+}
+
 func testNodeRepo(t *testing.T, repo node.Repo, setup, cleanup func()) {
 
 	r := require.New(t)
@@ -156,6 +167,23 @@ func testNodeRepo(t *testing.T, repo node.Repo, setup, cleanup func()) {
 
 		cleanup()
 	}
+
+	// The changes set replace the previous ones, and can be appended to.
+	setup()
+
+	err := repo.AppendChanges([]change.Change{chg.SetHeight(1), chg.SetHeight(3), chg.SetHeight(5)})
+	r.NoError(err)
+	checkpoint := change.New(change.Checkpoint).SetName(testNodeName1).SetHeight(3).SetValue([]byte{1, 2})
+	err = repo.SetChanges(testNodeName1, []change.Change{checkpoint, chg.SetHeight(5)})
+	r.NoError(err)
+	err = repo.AppendChanges([]change.Change{chg.SetHeight(6)})
+	r.NoError(err)
+
+	changes, err := repo.LoadChanges(testNodeName1)
+	r.NoError(err)
+	r.Equal([]change.Change{checkpoint, chg.SetHeight(5), chg.SetHeight(6)}, changes)
+
+	cleanup()
 }
 
 func TestIterator(t *testing.T) {
@@ -169,6 +197,17 @@ func TestIterator(t *testing.T) {
 		r.NoError(err)
 	}()
 
+	testIterator(t, repo)
+}
+
+func TestMemoryIterator(t *testing.T) {
+	testIterator(t, NewMemory())
+}
+
+func testIterator(t *testing.T, repo node.Repo) {
+
+	r := require.New(t)
+
 	creation := []change.Change{
 		{Name: []byte("test\x00"), Height: 5},
 		{Name: []byte("test\x00\x00"), Height: 5},
@@ -176,7 +215,7 @@ func TestIterator(t *testing.T) {
 		{Name: []byte("test\x00\xFF"), Height: 5},
 		{Name: []byte("testa"), Height: 5},
 	}
-	err = repo.AppendChanges(creation)
+	err := repo.AppendChanges(creation)
 	r.NoError(err)
 
 	var received []change.Change
@@ -185,4 +224,59 @@ func TestIterator(t *testing.T) {
 		return true
 	})
 	r.Equal(creation, received)
+
+	var names []string
+	repo.IterateFrom([]byte("test\x00b"), func(name []byte) bool {
+		names = append(names, string(name))
+		return len(names) < 2
+	})
+	r.Equal([]string{"test\x00b", "test\x00\xFF"}, names)
+}
+
+func TestSnapshot(t *testing.T) {
+
+	r := require.New(t)
+
+	repo, err := NewPebble(t.TempDir())
+	r.NoError(err)
+	defer func() {
+		err := repo.Close()
+		r.NoError(err)
+	}()
+
+	testSnapshot(t, repo, func() (node.Repo, error) { return repo.Snapshot() })
+}
+
+func TestMemorySnapshot(t *testing.T) {
+	repo := NewMemory()
+	testSnapshot(t, repo, func() (node.Repo, error) { return repo.Snapshot() })
+}
+
+func testSnapshot(t *testing.T, repo node.Repo, snapshot func() (node.Repo, error)) {
+
+	r := require.New(t)
+
+	chg := change.New(change.AddClaim).SetName(testNodeName1).SetOutPoint(opStr1)
+	r.NoError(repo.AppendChanges([]change.Change{chg.SetHeight(1)}))
+
+	// A snapshot keeps the changes as they were, and can't be written to.
+	snap, err := snapshot()
+	r.NoError(err)
+	r.NoError(repo.AppendChanges([]change.Change{chg.SetHeight(2), chg.SetName([]byte("name2")).SetHeight(2)}))
+	r.NoError(repo.SetChanges(testNodeName1, nil))
+	r.Error(snap.AppendChanges([]change.Change{chg.SetHeight(2)}))
+	r.Error(snap.DropChanges(testNodeName1, 0))
+	r.Error(snap.SetChanges(testNodeName1, nil))
+
+	changes, err := snap.LoadChanges(testNodeName1)
+	r.NoError(err)
+	r.Equal([]change.Change{chg.SetHeight(1)}, changes)
+
+	var names []string
+	snap.IterateAll(func(name []byte) bool {
+		names = append(names, string(name))
+		return true
+	})
+	r.Equal([]string{string(testNodeName1)}, names)
+	r.NoError(snap.Close())
 }
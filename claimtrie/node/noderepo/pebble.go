@@ -2,6 +2,7 @@ package noderepo
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"github.com/btcsuite/btcd/claimtrie/change"
 	"github.com/cockroachdb/pebble"
@@ -9,8 +10,14 @@ import (
 	"sort"
 )
 
+// errReadOnly is returned by the writes to a snapshot of the repo.
+var errReadOnly = errors.New("node repo snapshot is read-only")
+
 type Pebble struct {
 	db *pebble.DB
+
+	// snap is set on the snapshots of the repo, which read from it instead of db.
+	snap *pebble.Snapshot
 }
 
 func NewPebble(path string) (*Pebble, error) {
@@ -28,6 +35,10 @@ func NewPebble(path string) (*Pebble, error) {
 // AppendChanges makes an assumption that anything you pass to it is newer than what was saved before.
 func (repo *Pebble) AppendChanges(changes []change.Change) error {
 
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
 	batch := repo.db.NewBatch()
 
 	// TODO: switch to buffer pool and reuse encoder
@@ -52,7 +63,7 @@ func (repo *Pebble) AppendChanges(changes []change.Change) error {
 
 func (repo *Pebble) LoadChanges(name []byte) ([]change.Change, error) {
 
-	data, closer, err := repo.db.Get(name)
+	data, closer, err := repo.reader().Get(name)
 	if err != nil && err != pebble.ErrNotFound {
 		return nil, fmt.Errorf("pebble get: %w", err)
 	}
@@ -88,6 +99,10 @@ func unmarshalChanges(data []byte) ([]change.Change, error) {
 }
 
 func (repo *Pebble) DropChanges(name []byte, finalHeight int32) error {
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
 	changes, err := repo.LoadChanges(name)
 	i := 0
 	for ; i < len(changes); i++ {
@@ -106,6 +121,29 @@ func (repo *Pebble) DropChanges(name []byte, finalHeight int32) error {
 	return repo.AppendChanges(changes[:i])
 }
 
+func (repo *Pebble) SetChanges(name []byte, changes []change.Change) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	var value []byte
+	for _, chg := range changes {
+		b, err := msgpack.Marshal(chg)
+		if err != nil {
+			return fmt.Errorf("msgpack marshal value: %w", err)
+		}
+		value = append(value, b...)
+	}
+
+	err := repo.db.Set(name, value, pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble set: %w", err)
+	}
+
+	return nil
+}
+
 func (repo *Pebble) IterateChildren(name []byte, f func(changes []change.Change) bool) {
 	end := bytes.NewBuffer(nil)
 	end.Write(name)
@@ -116,7 +154,7 @@ func (repo *Pebble) IterateChildren(name []byte, f func(changes []change.Change)
 		UpperBound: end.Bytes(),
 	}
 
-	iter := repo.db.NewIter(prefixIterOptions)
+	iter := repo.reader().NewIter(prefixIterOptions)
 	defer iter.Close()
 
 	for iter.First(); iter.Valid(); iter.Next() {
@@ -131,7 +169,18 @@ func (repo *Pebble) IterateChildren(name []byte, f func(changes []change.Change)
 }
 
 func (repo *Pebble) IterateAll(predicate func(name []byte) bool) {
-	iter := repo.db.NewIter(nil)
+	iter := repo.reader().NewIter(nil)
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		if !predicate(iter.Key()) {
+			break
+		}
+	}
+}
+
+func (repo *Pebble) IterateFrom(start []byte, predicate func(name []byte) bool) {
+	iter := repo.reader().NewIter(&pebble.IterOptions{LowerBound: start})
 	defer iter.Close()
 
 	for iter.First(); iter.Valid(); iter.Next() {
@@ -141,8 +190,31 @@ func (repo *Pebble) IterateAll(predicate func(name []byte) bool) {
 	}
 }
 
+// Snapshot returns a read-only copy of the repo as it is now, which must be closed.
+// It isn't part of node.Repo, as the tests of package node import this package.
+func (repo *Pebble) Snapshot() (*Pebble, error) {
+
+	if repo.snap != nil {
+		return nil, errReadOnly
+	}
+
+	return &Pebble{snap: repo.db.NewSnapshot()}, nil
+}
+
+// reader returns the snapshot of a snapshot repo, or the DB.
+func (repo *Pebble) reader() pebble.Reader {
+	if repo.snap != nil {
+		return repo.snap
+	}
+	return repo.db
+}
+
 func (repo *Pebble) Close() error {
 
+	if repo.snap != nil {
+		return repo.snap.Close()
+	}
+
 	err := repo.db.Flush()
 	if err != nil {
 		return fmt.Errorf("pebble flush: %w", err)
@@ -1,16 +1,12 @@
 package node
 
 import (
+	"unicode/utf8"
+
 	"github.com/btcsuite/btcd/claimtrie/param"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/unicode/norm"
 )
 
-//func init() {
-//	if cases.UnicodeVersion[:2] != "11" {
-//		panic("Wrong unicode version!")
-//	}
-//}
+//go:generate go run gen_normalizer_tables.go
 
 var Normalize = normalizeGo
 
@@ -21,10 +17,109 @@ func NormalizeIfNecessary(name []byte, height int32) []byte {
 	return Normalize(name)
 }
 
-var folder = cases.Fold()
+// The buffer capacities of the ICU normalization, in UTF-16 units and bytes.
+// A name whose conversion overflows one of them is left as is.
+const (
+	maxNormalizedUnits = 256
+	maxNormalizedBytes = 512
+)
+
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulTCount = 28
+	hangulNCount = 21 * hangulTCount
+	hangulSCount = 19 * hangulNCount
+)
 
+// normalizeGo applies the NFD normalization followed by the default full case
+// folding, as normalizeICU does with ICU 63.2. The tables are generated from ICU
+// and restricted to Unicode 11.0, so the characters assigned by later versions
+// are left as is, and the conversions exceeding the ICU buffers leave the name
+// unchanged, as do the invalid UTF-8 sequences.
 func normalizeGo(value []byte) []byte {
 
-	normalized := norm.NFD.Bytes(value)
-	return folder.Bytes(normalized)
+	if len(value) == 0 || !utf8.Valid(value) {
+		return value
+	}
+
+	runes := []rune(string(value))
+	if utf16Len(runes) > maxNormalizedUnits {
+		return value
+	}
+
+	runes = decompose(runes)
+	if utf16Len(runes) > maxNormalizedUnits {
+		return value
+	}
+
+	folded := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if f, ok := caseFoldings[r]; ok {
+			folded = append(folded, []rune(f)...)
+			continue
+		}
+		folded = append(folded, r)
+	}
+	if utf16Len(folded) > maxNormalizedUnits {
+		return value
+	}
+
+	normalized := []byte(string(folded))
+	if len(normalized) > maxNormalizedBytes {
+		return value
+	}
+
+	return normalized
+}
+
+// decompose returns the canonical decomposition of the runes, in canonical order.
+func decompose(runes []rune) []rune {
+
+	decomposed := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if r >= hangulSBase && r < hangulSBase+hangulSCount {
+			s := r - hangulSBase
+			decomposed = append(decomposed, hangulLBase+s/hangulNCount, hangulVBase+(s%hangulNCount)/hangulTCount)
+			if t := s % hangulTCount; t != 0 {
+				decomposed = append(decomposed, hangulTBase+t)
+			}
+			continue
+		}
+		if d, ok := nfdDecompositions[r]; ok {
+			decomposed = append(decomposed, []rune(d)...)
+			continue
+		}
+		decomposed = append(decomposed, r)
+	}
+
+	// Stable insertion sort of each run of combining marks by combining class.
+	for i := 1; i < len(decomposed); i++ {
+		ccc := combiningClasses[decomposed[i]]
+		if ccc == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			prev := combiningClasses[decomposed[j-1]]
+			if prev <= ccc {
+				break
+			}
+			decomposed[j-1], decomposed[j] = decomposed[j], decomposed[j-1]
+		}
+	}
+
+	return decomposed
+}
+
+func utf16Len(runes []rune) int {
+	n := 0
+	for _, r := range runes {
+		n++
+		if r >= 0x10000 {
+			n++ // surrogate pair
+		}
+	}
+	return n
 }
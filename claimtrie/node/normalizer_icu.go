@@ -4,6 +4,7 @@ package node
 
 // #cgo CFLAGS: -O2
 // #cgo LDFLAGS: -licuio -licui18n -licuuc -licudata
+// #include <unicode/uchar.h>
 // #include <unicode/unorm2.h>
 // #include <unicode/ustring.h>
 // #include <unicode/uversion.h>
@@ -12,6 +13,11 @@ package node
 //    u_getVersion(info);
 //    return ((int)(info[0]) << 16) + info[1];
 // }
+// int char_age(int c) {
+//    UVersionInfo info;
+//    u_charAge(c, info);
+//    return ((int)(info[0]) << 16) + info[1];
+// }
 // int normalize(char* name, int length, char* result) {
 //   UErrorCode ec = U_ZERO_ERROR;
 //   static const UNormalizer2* normalizer = NULL;
@@ -26,6 +32,7 @@ package node
 //   dest_len = u_strFoldCase(dest, 256, normalized, dest_len, U_FOLD_CASE_DEFAULT, &ec);
 //   if (U_FAILURE(ec) || dest_len == 0) return 0;
 //   u_strToUTF8(result, 512, &dest_len, dest, dest_len, &ec);
+//   if (U_FAILURE(ec)) return 0;
 //   return dest_len;
 // }
 import "C"
@@ -44,6 +51,12 @@ func IcuVersion() string {
 	return fmt.Sprintf("%d.%d", result>>16, result&0xffff)
 }
 
+// icuCharAge returns the Unicode version, as major << 16 + minor, which assigned the character.
+// It's 0 for the unassigned characters.
+func icuCharAge(r rune) int {
+	return int(C.char_age(C.int(r)))
+}
+
 func normalizeICU(value []byte) []byte {
 	if len(value) <= 0 {
 		return value
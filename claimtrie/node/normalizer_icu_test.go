@@ -3,8 +3,16 @@
 package node
 
 import (
-	"github.com/stretchr/testify/assert"
+	"bufio"
+	"encoding/hex"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNormalizationICU(t *testing.T) {
@@ -20,4 +28,106 @@ func TestBlock760150(t *testing.T) {
 	a := normalizeGo([]byte(test))
 	b := normalizeICU([]byte(test))
 	assert.Equal(t, a, b)
-}
\ No newline at end of file
+}
+
+// unicode11Runes returns the characters assigned in Unicode 11.0, the version of ICU 63.2.
+func unicode11Runes() []rune {
+	var runes []rune
+	for r := rune(0); r <= 0x10FFFF; r++ {
+		if r >= 0xD800 && r <= 0xDFFF {
+			continue
+		}
+		if age := icuCharAge(r); age > 0 && age <= 11<<16 {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+func TestNormalizationGoMatchesICU(t *testing.T) {
+
+	r := require.New(t)
+
+	runes := unicode11Runes()
+	r.Greater(len(runes), 130000)
+
+	for _, c := range runes {
+		for _, s := range []string{string(c), "A" + string(c), string(c) + "̣́"} {
+			r.Equal(normalizeICU([]byte(s)), normalizeGo([]byte(s)), "%U in %+q", c, s)
+		}
+	}
+}
+
+func TestNormalizationGoMatchesICURandom(t *testing.T) {
+
+	r := require.New(t)
+
+	runes := unicode11Runes()
+	marks := []rune("゙ְุ̧〪̖̣̀́̈ͅ")
+
+	rand.Seed(42)
+	for i := 0; i < 100000; i++ {
+		var b strings.Builder
+		for j := rand.Intn(40); j >= 0; j-- {
+			switch rand.Intn(4) {
+			case 0:
+				b.WriteRune(marks[rand.Intn(len(marks))])
+			case 1:
+				b.WriteRune(rune('A' + rand.Intn(26)))
+			default:
+				b.WriteRune(runes[rand.Intn(len(runes))])
+			}
+		}
+		s := []byte(b.String())
+		if rand.Intn(100) == 0 {
+			s[rand.Intn(len(s))] = byte(rand.Intn(256))
+		}
+		r.Equal(normalizeICU(s), normalizeGo(s), "%+q", s)
+	}
+
+	// The buffer limits.
+	for _, c := range []string{"A", "ß", "À", "ΐ", "가", "\U0001E900"} {
+		for n := 60; n <= 270; n++ {
+			s := []byte(strings.Repeat(c, n))
+			r.Equal(normalizeICU(s), normalizeGo(s), "%d x %+q", n, c)
+		}
+	}
+}
+
+// TestNormalizationGoMatchesICUCorpus compares the normalizations of the names
+// listed in testdata/names.txt, one hex encoded name per line, which cover the
+// case folding and decompositions of many scripts. A larger corpus, such as the
+// names of mainnet output by "claimtrie node names", can be listed in the file
+// named by CLAIMTRIE_NAME_CORPUS too.
+func TestNormalizationGoMatchesICUCorpus(t *testing.T) {
+
+	paths := []string{filepath.Join("testdata", "names.txt")}
+	if path := os.Getenv("CLAIMTRIE_NAME_CORPUS"); path != "" {
+		paths = append(paths, path)
+	}
+
+	for _, path := range paths {
+		compareCorpus(t, path)
+	}
+}
+
+func compareCorpus(t *testing.T, path string) {
+
+	r := require.New(t)
+
+	f, err := os.Open(path)
+	r.NoError(err)
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, err := hex.DecodeString(strings.TrimSpace(scanner.Text()))
+		r.NoError(err)
+		r.Equal(normalizeICU(name), normalizeGo(name), "%x", name)
+		count++
+	}
+	r.NoError(scanner.Err())
+	r.NotZero(count, path)
+	t.Logf("compared %d names of %s", count, path)
+}
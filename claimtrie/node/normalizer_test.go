@@ -2,6 +2,7 @@ package node
 
 import (
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -24,6 +25,38 @@ func testNormalization(t *testing.T, normalize func(value []byte) []byte) {
 	r.Equal("\xD5\xA2", string(normalize([]byte("\xD4\xB2"))))
 	r.Equal("\xE3\x81\xB5\xE3\x82\x99", string(normalize([]byte("\xE3\x81\xB6"))))
 	r.Equal("\xE1\x84\x81\xE1\x85\xAA\xE1\x86\xB0", string(normalize([]byte("\xEA\xBD\x91"))))
+
+	// Invalid UTF-8 leaves the whole name unchanged.
+	r.Equal("ABC\xFF", string(normalize([]byte("ABC\xFF"))))
+
+	// Marks are put in canonical order: dot below (220) before acute (230).
+	r.Equal("a\u0323\u0301", string(normalize([]byte("A\u0301\u0323"))))
+	r.Equal("ss", string(normalize([]byte("\u00DF"))))
+
+	// Uppercase Cherokee is the case folding of lowercase Cherokee.
+	r.Equal("\u13C6", string(normalize([]byte("\u13C6"))))
+	r.Equal("\u13C6", string(normalize([]byte("\uAB96"))))
+
+	// Names exceeding the buffers of ICU are left unchanged.
+	long := strings.Repeat("A", 257)
+	r.Equal(long, string(normalize([]byte(long))))
+	r.Equal(strings.Repeat("a", 256), string(normalize([]byte(strings.Repeat("A", 256)))))
+	long = strings.Repeat("\u00DF", 200) // folds to 400 units
+	r.Equal(long, string(normalize([]byte(long))))
+	long = strings.Repeat("\u00C0", 200) // decomposes to 400 units
+	r.Equal(long, string(normalize([]byte(long))))
+}
+
+func TestNormalizationUnicode11(t *testing.T) {
+
+	r := require.New(t)
+
+	// Characters assigned after Unicode 11.0 are unknown to ICU 63.2.
+	r.Equal("\uA7C2", string(normalizeGo([]byte("\uA7C2"))))
+	r.Equal("\uA7C4", string(normalizeGo([]byte("\uA7C4"))))
+
+	// Whereas those of earlier versions, such as Adlam, are folded.
+	r.Equal("\U0001E922", string(normalizeGo([]byte("\U0001E900"))))
 }
 
 func randSeq(n int) []byte {
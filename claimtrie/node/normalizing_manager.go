@@ -25,6 +25,11 @@ func (nm *NormalizingManager) AppendChange(chg change.Change) error {
 	return nm.Manager.AppendChange(chg)
 }
 
+func (nm *NormalizingManager) Simulate(chg change.Change) (*Simulation, error) {
+	chg.Name = NormalizeIfNecessary(chg.Name, chg.Height)
+	return nm.Manager.Simulate(chg)
+}
+
 func (nm *NormalizingManager) IncrementHeightTo(height int32) ([][]byte, error) {
 	nm.addNormalizationForkChangesIfNecessary(height)
 	return nm.Manager.IncrementHeightTo(height)
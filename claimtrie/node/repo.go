@@ -17,6 +17,9 @@ type Repo interface {
 
 	DropChanges(name []byte, finalHeight int32) error
 
+	// SetChanges replaces all the changes of a node at once.
+	SetChanges(name []byte, changes []change.Change) error
+
 	// Close closes the repo.
 	Close() error
 
@@ -26,4 +29,8 @@ type Repo interface {
 
 	// IterateAll iterates keys until the predicate function returns false
 	IterateAll(predicate func(name []byte) bool)
+
+	// IterateFrom iterates keys from start, included, in order until the
+	// predicate function returns false.
+	IterateFrom(start []byte, predicate func(name []byte) bool)
 }
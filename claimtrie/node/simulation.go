@@ -0,0 +1,94 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/btcsuite/btcd/claimtrie/change"
+)
+
+// ErrClaimNotFound is returned when a simulated support targets a claim which
+// the node doesn't hold.
+var ErrClaimNotFound = errors.New("claim not found")
+
+// Simulation is the outcome of a hypothetical claim or support.
+type Simulation struct {
+	ClaimID  string // The hypothetical claim, or the supported one.
+	ActiveAt int32  // The height at which the hypothetical claim or support activates.
+	BestAt   int32  // The height at which the claim becomes best, or zero if it doesn't.
+	Steps    []SimulationStep
+}
+
+// SimulationStep is the state of a simulated node at a height it was updated.
+type SimulationStep struct {
+	Height          int32
+	BestClaimID     string // Empty if the node has no activated best claim.
+	BestAmount      int64  // The effective amount of the best claim.
+	EffectiveAmount int64  // The effective amount of the claim of the simulation.
+}
+
+// Simulate applies the hypothetical change, a claim or a support made at the
+// next height, to a node of its name built aside, and follows the node through
+// its updates until the claim becomes best or expires. The manager and its
+// cache are left untouched.
+func (nm *BaseManager) Simulate(chg change.Change) (*Simulation, error) {
+
+	if chg.Type != change.AddClaim && chg.Type != change.AddSupport {
+		return nil, fmt.Errorf("can't simulate a change of type %d", chg.Type)
+	}
+	if chg.Height != nm.height+1 {
+		return nil, fmt.Errorf("can't simulate a change at height %d, expected %d", chg.Height, nm.height+1)
+	}
+
+	n, err := nm.NodeAt(nm.height, chg.Name)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		n = New()
+	}
+	if chg.Type == change.AddSupport && n.Claims.find(byID(chg.ClaimID)) == nil {
+		return nil, fmt.Errorf("%w: %s", ErrClaimNotFound, chg.ClaimID)
+	}
+
+	delay, err := nm.getDelayForName(n, chg)
+	if err != nil {
+		return nil, fmt.Errorf("delay of change: %w", err)
+	}
+	_, err = n.ApplyChange(chg, delay)
+	if err != nil {
+		return nil, fmt.Errorf("apply change: %w", err)
+	}
+	added := n.Claims[len(n.Claims)-1]
+	if chg.Type == change.AddSupport {
+		added = n.Supports[len(n.Supports)-1]
+	}
+	claim := n.Claims.find(byID(chg.ClaimID))
+
+	sim := &Simulation{ClaimID: chg.ClaimID}
+	for height := chg.Height; height < claim.ExpireAt(); height = n.NextUpdate() {
+		n.AdjustTo(height, height, chg.Name)
+
+		step := SimulationStep{
+			Height:          height,
+			EffectiveAmount: claim.EffectiveAmount(n.Supports),
+		}
+		if n.BestClaim != nil && n.BestClaim.Status == Activated {
+			step.BestClaimID = n.BestClaim.ClaimID
+			step.BestAmount = n.BestClaim.EffectiveAmount(n.Supports)
+		}
+		sim.Steps = append(sim.Steps, step)
+
+		if step.BestClaimID == claim.ClaimID {
+			sim.BestAt = height
+			break
+		}
+		if n.NextUpdate() == math.MaxInt32 {
+			break
+		}
+	}
+	sim.ActiveAt = added.ActiveAt
+
+	return sim, nil
+}
@@ -5,6 +5,8 @@ import (
 )
 
 var (
+	ActiveNet wire.BitcoinNet
+
 	MaxActiveDelay    int32
 	ActiveDelayFactor int32
 
@@ -21,6 +23,7 @@ var (
 )
 
 func SetNetwork(net wire.BitcoinNet) {
+	ActiveNet = net
 	MaxActiveDelay = 4032
 	ActiveDelayFactor = 32
 	MaxNodeManagerCacheSize = 16000
@@ -0,0 +1,117 @@
+package claimtrie
+
+import (
+	"sync"
+
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/param"
+)
+
+// pruneInterval is the number of blocks between the passes of the pruner.
+const pruneInterval = 1000
+
+// pruner collapses in the background the changes of the nodes which are deeper
+// than a retention depth, one name at a time, so the nodes are loaded faster and
+// the node repo stops growing with their history.
+type pruner struct {
+	nodeManager node.Manager
+	depth       int32
+
+	heights chan int32
+	quit    chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newPruner(nodeManager node.Manager, depth int32) *pruner {
+
+	p := &pruner{
+		nodeManager: nodeManager,
+		depth:       depth,
+		heights:     make(chan int32, 1),
+		quit:        make(chan struct{}),
+	}
+
+	p.wg.Add(1)
+	go p.run()
+
+	return p
+}
+
+// notify tells the pruner the height of the last appended block.
+// The heights notified during a pass are coalesced.
+func (p *pruner) notify(height int32) {
+
+	select {
+	case <-p.heights: // replace a stale height
+	default:
+	}
+	p.heights <- height
+}
+
+func (p *pruner) run() {
+
+	defer p.wg.Done()
+
+	var pruned int32 // the height of the last pass
+	for {
+		select {
+		case <-p.quit:
+			return
+		case height := <-p.heights:
+			cutoff := height - p.depth
+			if cutoff <= param.NormalizedNameForkHeight || cutoff < pruned+pruneInterval {
+				continue
+			}
+			if p.prune(cutoff) {
+				pruned = cutoff
+			}
+		}
+	}
+}
+
+// prune collapses the changes up to the cutoff of all the names. The names are
+// iterated in order, so a name is collapsed before its children, whose nodes
+// are built when it's replayed. The pass stops at the first name which fails
+// to be collapsed, so its children are left for the next pass too.
+// It returns false if the pass was interrupted or failed.
+func (p *pruner) prune(cutoff int32) bool {
+
+	log.Infof("Pruning the node history up to height %d", cutoff)
+
+	count := 0
+	completed := true
+	p.nodeManager.IterateNames(func(name []byte) bool {
+		select {
+		case <-p.quit:
+			completed = false
+			return false
+		default:
+		}
+
+		// The iteration buffer of the name is reused.
+		ok, err := p.nodeManager.Prune(append([]byte(nil), name...), cutoff)
+		if err != nil {
+			log.Errorf("Pruning %q: %s", name, err)
+			completed = false
+			return false
+		}
+		if ok {
+			count++
+		}
+		return true
+	})
+
+	if completed {
+		log.Infof("Pruned the node history of %d names up to height %d", count, cutoff)
+	}
+	return completed
+}
+
+// stop interrupts the pruning, and waits for the pruner to exit.
+func (p *pruner) stop() error {
+
+	close(p.quit)
+	p.wg.Wait()
+
+	return nil
+}
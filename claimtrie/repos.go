@@ -0,0 +1,122 @@
+package claimtrie
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
+	"github.com/btcsuite/btcd/claimtrie/anomaly/anomalyrepo"
+	"github.com/btcsuite/btcd/claimtrie/block"
+	"github.com/btcsuite/btcd/claimtrie/block/blockrepo"
+	"github.com/btcsuite/btcd/claimtrie/chain"
+	"github.com/btcsuite/btcd/claimtrie/chain/chainrepo"
+	"github.com/btcsuite/btcd/claimtrie/channel"
+	"github.com/btcsuite/btcd/claimtrie/channel/channelrepo"
+	"github.com/btcsuite/btcd/claimtrie/claimid"
+	"github.com/btcsuite/btcd/claimtrie/claimid/claimidrepo"
+	"github.com/btcsuite/btcd/claimtrie/config"
+	"github.com/btcsuite/btcd/claimtrie/merkletrie"
+	"github.com/btcsuite/btcd/claimtrie/merkletrie/merkletrierepo"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/node/noderepo"
+	"github.com/btcsuite/btcd/claimtrie/takeover"
+	"github.com/btcsuite/btcd/claimtrie/takeover/takeoverrepo"
+	"github.com/btcsuite/btcd/claimtrie/temporal"
+	"github.com/btcsuite/btcd/claimtrie/temporal/temporalrepo"
+)
+
+// The constructors below create the repositories of the configured backend.
+// The path is relative to the DataDir, and ignored by the memory backend.
+
+func checkBackend(cfg config.Config) error {
+	switch cfg.Backend {
+	case "", config.PebbleBackend, config.MemoryBackend:
+		return nil
+	default:
+		return fmt.Errorf("unknown backend %q", cfg.Backend)
+	}
+}
+
+func newBlockRepo(cfg config.Config, path string) (block.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return blockrepo.NewMemory(), nil
+	}
+	return blockrepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newTemporalRepo(cfg config.Config, path string) (temporal.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return temporalrepo.NewMemory(), nil
+	}
+	return temporalrepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newNodeRepo(cfg config.Config, path string) (node.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return noderepo.NewMemory(), nil
+	}
+	return noderepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newTrieRepo(cfg config.Config, path string) (merkletrie.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return merkletrierepo.NewMemory(), nil
+	}
+	return merkletrierepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newChainRepo(cfg config.Config, path string) (chain.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return chainrepo.NewMemory(), nil
+	}
+	return chainrepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newClaimIDRepo(cfg config.Config, path string) (claimid.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return claimidrepo.NewMemory(), nil
+	}
+	return claimidrepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newChannelRepo(cfg config.Config, path string) (channel.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return channelrepo.NewMemory(), nil
+	}
+	return channelrepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newAnomalyRepo(cfg config.Config, path string) (anomaly.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return anomalyrepo.NewMemory(), nil
+	}
+	return anomalyrepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+func newTakeoverRepo(cfg config.Config, path string) (takeover.Repo, error) {
+	if cfg.Backend == config.MemoryBackend {
+		return takeoverrepo.NewMemory(), nil
+	}
+	return takeoverrepo.NewPebble(filepath.Join(cfg.DataDir, path))
+}
+
+// snapshotNodeRepo returns a read-only copy of the node repo. Unlike the other
+// repos, its snapshots aren't part of its interface, so they're reached here.
+func snapshotNodeRepo(repo node.Repo) (node.Repo, error) {
+	switch repo := repo.(type) {
+	case *noderepo.Pebble:
+		snap, err := repo.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		return snap, nil
+	case *noderepo.Memory:
+		snap, err := repo.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		return snap, nil
+	default:
+		return nil, fmt.Errorf("can't snapshot a node repo of type %T", repo)
+	}
+}
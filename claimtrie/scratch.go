@@ -0,0 +1,113 @@
+package claimtrie
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/claimtrie/anomaly/anomalyrepo"
+	"github.com/btcsuite/btcd/claimtrie/block/blockrepo"
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/takeover/takeoverrepo"
+	"github.com/btcsuite/btcd/claimtrie/temporal/temporalrepo"
+)
+
+// Scratch returns a ClaimTrie at the current height, to which the next block can
+// be appended speculatively, such as to compute the Merkle Hash of a block
+// template. It reads from a view of the ClaimTrie, and keeps its own changes in
+// memory, so the ClaimTrie is left untouched. It doesn't maintain the optional
+// indexes, nor record the changes. Its merkle trie is forked from that of the
+// ClaimTrie, whose vertices it may share, so the ClaimTrie must not be appended
+// to nor reset until the scratch is closed.
+func (ct *ClaimTrie) Scratch() (*ClaimTrie, error) {
+
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	v, err := ct.view()
+	if err != nil {
+		return nil, err
+	}
+
+	st := &ClaimTrie{
+		blockRepo:    blockrepo.NewMemory(),
+		temporalRepo: temporalrepo.NewMemory(),
+		anomalyRepo:  anomalyrepo.NewMemory(),
+		takeoverRepo: takeoverrepo.NewMemory(),
+
+		height:   ct.height,
+		cleanups: []func() error{v.Close},
+	}
+
+	// Only the names to be refreshed at the next height are read by AppendBlock.
+	names, err := v.ct.temporalRepo.NodesAt(ct.height + 1)
+	if err != nil {
+		st.Close()
+		return nil, fmt.Errorf("temporal repo nodes at: %w", err)
+	}
+	heights := make([]int32, len(names))
+	for i := range heights {
+		heights[i] = ct.height + 1
+	}
+	err = st.temporalRepo.SetNodesAt(names, heights)
+	if err != nil {
+		st.Close()
+		return nil, fmt.Errorf("temporal repo set at: %w", err)
+	}
+
+	st.nodeRepo = &scratchNodeRepo{Repo: v.ct.nodeRepo, changes: map[string][]change.Change{}}
+	baseManager, err := node.NewBaseManagerAt(st.nodeRepo, ct.height, 0)
+	if err != nil {
+		st.Close()
+		return nil, fmt.Errorf("new node manager: %w", err)
+	}
+	st.nodeManager = node.NewNormalizingManager(baseManager)
+	st.cleanups = append(st.cleanups, st.nodeManager.Close)
+
+	st.merkleTrie = ct.merkleTrie.Fork(st.nodeManager)
+	st.cleanups = append(st.cleanups, st.merkleTrie.Close)
+
+	return st, nil
+}
+
+// scratchNodeRepo reads the changes from a node repo, and keeps those appended
+// in memory, so the repo is left untouched. The repo isn't closed with it.
+type scratchNodeRepo struct {
+	node.Repo
+	changes map[string][]change.Change
+}
+
+func (repo *scratchNodeRepo) AppendChanges(changes []change.Change) error {
+
+	for _, chg := range changes {
+		name := string(chg.Name)
+		repo.changes[name] = append(repo.changes[name], chg)
+	}
+
+	return nil
+}
+
+func (repo *scratchNodeRepo) LoadChanges(name []byte) ([]change.Change, error) {
+
+	changes, err := repo.Repo.LoadChanges(name)
+	if err != nil {
+		return nil, err
+	}
+
+	appended := repo.changes[string(name)]
+	if len(appended) == 0 {
+		return changes, nil
+	}
+
+	// The changes of the normalization fork are appended with previous heights.
+	changes = append(changes, appended...)
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Height < changes[j].Height
+	})
+
+	return changes, nil
+}
+
+func (repo *scratchNodeRepo) Close() error {
+	return nil
+}
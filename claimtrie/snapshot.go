@@ -0,0 +1,332 @@
+package claimtrie
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/claimtrie/block"
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/config"
+	"github.com/btcsuite/btcd/claimtrie/merkletrie"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/param"
+	"github.com/btcsuite/btcd/claimtrie/snapshot"
+	"github.com/btcsuite/btcd/claimtrie/temporal"
+)
+
+// ErrNotEmpty is returned by ImportSnapshot when the ClaimTrie already has blocks.
+var ErrNotEmpty = errors.New("claimtrie is not empty")
+
+// ErrBelowSnapshot is returned by ResetHeight when the ClaimTrie was imported
+// from a snapshot above the height.
+var ErrBelowSnapshot = errors.New("height is below the snapshot the claimtrie was imported from")
+
+// ExportSnapshot writes a snapshot of the ClaimTrie at its current height to w.
+// Changes made since the last AppendBlock are not included.
+func (ct *ClaimTrie) ExportSnapshot(w io.Writer) (*snapshot.Header, error) {
+
+	// The root is the recorded one, which a view can read.
+	root, err := ct.merkleHashAt(ct.height)
+	if err != nil {
+		return nil, fmt.Errorf("block repo get %d: %w", ct.height, err)
+	}
+
+	hdr := snapshot.Header{
+		Version: snapshot.Version,
+		Net:     param.ActiveNet,
+		Height:  ct.height,
+		Root:    *root,
+	}
+
+	sw, err := snapshot.NewWriter(w, hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	for height := int32(1); height <= ct.height; height++ {
+		hash, err := ct.blockRepo.Get(height)
+		if err != nil {
+			return nil, fmt.Errorf("block repo get %d: %w", height, err)
+		}
+		err = sw.WriteBlock(height, hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var writeErr error
+	err = ct.temporalRepo.IterateAll(func(height int32, name []byte) bool {
+		writeErr = sw.WriteTemporal(height, name)
+		return writeErr == nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("temporal repo iterate: %w", err)
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	ct.nodeRepo.IterateAll(func(name []byte) bool {
+		var changes []change.Change
+		changes, writeErr = ct.nodeRepo.LoadChanges(name)
+		if writeErr != nil {
+			writeErr = fmt.Errorf("node repo load %q: %w", name, writeErr)
+			return false
+		}
+		for i := range changes {
+			if changes[i].Height > ct.height {
+				changes = changes[:i]
+				break
+			}
+		}
+		if len(changes) == 0 {
+			return true
+		}
+		writeErr = sw.WriteChanges(changes)
+		return writeErr == nil
+	})
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	err = sw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return &hdr, nil
+}
+
+// ImportSnapshot loads a snapshot into the repositories of the configuration,
+// which must not have any blocks, and builds the Merkle Trie from it.
+// The Merkle Hash computed from the imported nodes must match the root of the
+// snapshot, but it is up to the caller to check the root against the chain.
+// The repositories are left untouched if the snapshot can't be imported.
+//
+// The ClaimTrie can't be reset below the height of the snapshot afterwards,
+// as the Merkle Trie of the previous heights is not part of the snapshot;
+// ResetHeight returns ErrBelowSnapshot.
+func ImportSnapshot(cfg config.Config, r io.Reader) (*snapshot.Header, error) {
+
+	err := checkBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Backend == config.MemoryBackend {
+		return nil, fmt.Errorf("can't import a snapshot into the memory backend")
+	}
+
+	blockRepo, err := newBlockRepo(cfg, cfg.BlockRepoPebble.Path)
+	if err != nil {
+		return nil, fmt.Errorf("new block repo: %w", err)
+	}
+	previousHeight, err := blockRepo.Load()
+	if err != nil {
+		blockRepo.Close()
+		return nil, fmt.Errorf("load blocks: %w", err)
+	}
+	err = blockRepo.Close()
+	if err != nil {
+		return nil, fmt.Errorf("close block repo: %w", err)
+	}
+	if previousHeight > 0 {
+		return nil, ErrNotEmpty
+	}
+
+	// The checksum is only verified at the end of the snapshot, so it's
+	// imported into a temporary directory, which is moved into place once
+	// the import completes.
+	tmpDir, err := os.MkdirTemp(cfg.DataDir, "snapshot")
+	if err != nil {
+		return nil, fmt.Errorf("make temporary dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tmpCfg := cfg
+	tmpCfg.DataDir = tmpDir
+	hdr, err := importSnapshot(tmpCfg, r)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := []string{cfg.TemporalRepoPebble.Path, cfg.NodeRepoPebble.Path}
+	if !cfg.RamTrie {
+		paths = append(paths, cfg.MerkleTrieRepoPebble.Path)
+	}
+	// The block repo is moved last, as the ClaimTrie is empty until then.
+	paths = append(paths, cfg.BlockRepoPebble.Path)
+
+	for _, path := range paths {
+		dst := filepath.Join(cfg.DataDir, path)
+		err = os.RemoveAll(dst)
+		if err != nil {
+			return nil, fmt.Errorf("remove %s: %w", dst, err)
+		}
+		err = os.Rename(filepath.Join(tmpDir, path), dst)
+		if err != nil {
+			return nil, fmt.Errorf("move %s into place: %w", path, err)
+		}
+	}
+
+	return hdr, nil
+}
+
+// importSnapshot loads a snapshot into the empty repositories of the configuration.
+func importSnapshot(cfg config.Config, r io.Reader) (hdr *snapshot.Header, err error) {
+
+	sr, err := snapshot.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	h := sr.Header()
+	if h.Net != param.ActiveNet {
+		return nil, fmt.Errorf("snapshot of network %s, expected %s", h.Net, param.ActiveNet)
+	}
+
+	var cleanups []func() error
+	defer func() {
+		for i := len(cleanups) - 1; i >= 0; i-- {
+			cerr := cleanups[i]()
+			if cerr != nil && err == nil {
+				err = fmt.Errorf("cleanup: %w", cerr)
+			}
+		}
+	}()
+
+	blockRepo, err := newBlockRepo(cfg, cfg.BlockRepoPebble.Path)
+	if err != nil {
+		return nil, fmt.Errorf("new block repo: %w", err)
+	}
+	cleanups = append(cleanups, blockRepo.Close)
+
+	temporalRepo, err := newTemporalRepo(cfg, cfg.TemporalRepoPebble.Path)
+	if err != nil {
+		return nil, fmt.Errorf("new temporal repo: %w", err)
+	}
+	cleanups = append(cleanups, temporalRepo.Close)
+
+	// The cleanup is delegated to the Node Manager.
+	nodeRepo, err := newNodeRepo(cfg, cfg.NodeRepoPebble.Path)
+	if err != nil {
+		return nil, fmt.Errorf("new node repo: %w", err)
+	}
+	baseManager, err := node.NewBaseManager(nodeRepo, cfg.NodeCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("new node manager: %w", err)
+	}
+	nodeManager := node.NewNormalizingManager(baseManager)
+	cleanups = append(cleanups, nodeManager.Close)
+
+	log.Infof("Importing snapshot at height %d", h.Height)
+
+	err = importRecords(sr, blockRepo, temporalRepo, nodeRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.Height > 0 {
+		hash, err := blockRepo.Get(h.Height)
+		if err != nil {
+			return nil, fmt.Errorf("get hash: %w", err)
+		}
+		if *hash != h.Root {
+			return nil, fmt.Errorf("block hash %s at height %d doesn't match the root %s", hash, h.Height, h.Root)
+		}
+		err = blockRepo.SetBaseHeight(h.Height)
+		if err != nil {
+			return nil, fmt.Errorf("block repo set base height: %w", err)
+		}
+	}
+
+	_, err = nodeManager.IncrementHeightTo(h.Height)
+	if err != nil {
+		return nil, fmt.Errorf("node manager init: %w", err)
+	}
+
+	var trie merkletrie.Trie
+	if cfg.RamTrie {
+		// The RAM trie is built on New, which verifies it again.
+		trie = merkletrie.NewRamTrie(nodeManager)
+	} else {
+		trieRepo, err := newTrieRepo(cfg, cfg.MerkleTrieRepoPebble.Path)
+		if err != nil {
+			return nil, fmt.Errorf("new trie repo: %w", err)
+		}
+		trie = merkletrie.New(nodeManager, trieRepo)
+	}
+	cleanups = append(cleanups, trie.Close)
+
+	err = buildTrie(trie, nodeManager, &h.Root, h.Height)
+	if err != nil {
+		return nil, fmt.Errorf("build trie: %w", err)
+	}
+
+	log.Infof("Completed importing snapshot")
+	return &h, nil
+}
+
+// importRecords writes the records of the snapshot to the repos.
+func importRecords(sr *snapshot.Reader, blockRepo block.Repo, temporalRepo temporal.Repo, nodeRepo node.Repo) error {
+
+	height := sr.Header().Height
+
+	var names [][]byte
+	var heights []int32
+	flush := func() error {
+		err := temporalRepo.SetNodesAt(names, heights)
+		names, heights = names[:0], heights[:0]
+		return err
+	}
+
+	for {
+		rec, err := sr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read snapshot: %w", err)
+		}
+
+		switch rec.Type {
+		case snapshot.BlockRecord:
+			if rec.Height > height {
+				return fmt.Errorf("block at height %d above the snapshot", rec.Height)
+			}
+			err = blockRepo.Set(rec.Height, &rec.Hash)
+			if err != nil {
+				return fmt.Errorf("block repo set: %w", err)
+			}
+
+		case snapshot.TemporalRecord:
+			names = append(names, rec.Name)
+			heights = append(heights, rec.Height)
+			if len(names) >= 10000 {
+				err = flush()
+				if err != nil {
+					return fmt.Errorf("temporal repo set: %w", err)
+				}
+			}
+
+		case snapshot.ChangesRecord:
+			for _, chg := range rec.Changes {
+				if chg.Height > height {
+					return fmt.Errorf("change of %q at height %d above the snapshot", chg.Name, chg.Height)
+				}
+			}
+			err = nodeRepo.AppendChanges(rec.Changes)
+			if err != nil {
+				return fmt.Errorf("node repo append: %w", err)
+			}
+		}
+	}
+
+	err := flush()
+	if err != nil {
+		return fmt.Errorf("temporal repo set: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,277 @@
+// Package snapshot implements the format of ClaimTrie snapshots, which carry
+// the state of a ClaimTrie at a height, so a node can be bootstrapped without
+// replaying the blocks below it.
+//
+// A snapshot is laid out as follows, with integers in big endian:
+//
+//	header:  magic(4B) + version(4B) + net(4B) + height(4B) + root(32B)
+//	record:  type(1B) + length(4B) + payload
+//	trailer: 'e' + length(4B) + sha256 of all the preceding bytes
+//
+// The payload of each record type:
+//
+//	'b': height(4B) + hash(32B)     the Merkle Hash recorded for a block
+//	't': height(4B) + name          a name scheduled for an update at the height
+//	'n': msgpack of []change.Change the change history of a name
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Version is the version of the snapshot format written by Writer.
+const Version = 1
+
+var magic = [4]byte{'c', 'l', 'm', 't'}
+
+// maxRecordSize limits the memory allocated for a record of a corrupted snapshot.
+const maxRecordSize = 1 << 28
+
+// RecordType identifies the kind of a Record.
+type RecordType byte
+
+const (
+	BlockRecord    RecordType = 'b'
+	TemporalRecord RecordType = 't'
+	ChangesRecord  RecordType = 'n'
+
+	endRecord RecordType = 'e'
+)
+
+// Header describes the ClaimTrie captured by a snapshot.
+type Header struct {
+	Version uint32
+	Net     wire.BitcoinNet
+	Height  int32
+	Root    chainhash.Hash
+}
+
+// Record is an entry of a snapshot. The fields used depend on the Type.
+type Record struct {
+	Type RecordType
+
+	// Height is set for BlockRecord and TemporalRecord.
+	Height int32
+
+	// Hash is set for BlockRecord.
+	Hash chainhash.Hash
+
+	// Name is set for TemporalRecord.
+	Name []byte
+
+	// Changes is set for ChangesRecord, and all belong to the same name.
+	Changes []change.Change
+}
+
+// Writer writes a snapshot.
+type Writer struct {
+	w   *bufio.Writer
+	sum hash.Hash
+	out io.Writer
+	buf bytes.Buffer
+}
+
+// NewWriter writes the header to w, and returns a Writer for the records.
+func NewWriter(w io.Writer, hdr Header) (*Writer, error) {
+
+	sw := &Writer{w: bufio.NewWriterSize(w, 1<<20), sum: sha256.New()}
+	sw.out = io.MultiWriter(sw.w, sw.sum)
+
+	b := make([]byte, 0, 48)
+	b = append(b, magic[:]...)
+	b = appendUint32(b, Version)
+	b = appendUint32(b, uint32(hdr.Net))
+	b = appendUint32(b, uint32(hdr.Height))
+	b = append(b, hdr.Root[:]...)
+
+	_, err := sw.out.Write(b)
+	if err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	return sw, nil
+}
+
+// WriteBlock writes the Merkle Hash recorded for the block at height.
+func (sw *Writer) WriteBlock(height int32, hash *chainhash.Hash) error {
+	sw.buf.Reset()
+	sw.buf.Write(appendUint32(nil, uint32(height)))
+	sw.buf.Write(hash[:])
+	return sw.writeRecord(BlockRecord, sw.buf.Bytes())
+}
+
+// WriteTemporal writes a name scheduled for an update at height.
+func (sw *Writer) WriteTemporal(height int32, name []byte) error {
+	sw.buf.Reset()
+	sw.buf.Write(appendUint32(nil, uint32(height)))
+	sw.buf.Write(name)
+	return sw.writeRecord(TemporalRecord, sw.buf.Bytes())
+}
+
+// WriteChanges writes the change history of a name.
+func (sw *Writer) WriteChanges(changes []change.Change) error {
+	value, err := msgpack.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("msgpack marshal: %w", err)
+	}
+	return sw.writeRecord(ChangesRecord, value)
+}
+
+func (sw *Writer) writeRecord(typ RecordType, payload []byte) error {
+
+	if len(payload) > maxRecordSize {
+		return fmt.Errorf("record of %d bytes exceeds the limit", len(payload))
+	}
+
+	b := appendUint32([]byte{byte(typ)}, uint32(len(payload)))
+	_, err := sw.out.Write(b)
+	if err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+	_, err = sw.out.Write(payload)
+	if err != nil {
+		return fmt.Errorf("write record: %w", err)
+	}
+
+	return nil
+}
+
+// Close writes the trailer and flushes the snapshot. It doesn't close the underlying writer.
+func (sw *Writer) Close() error {
+
+	err := sw.writeRecord(endRecord, sw.sum.Sum(nil))
+	if err != nil {
+		return err
+	}
+
+	return sw.w.Flush()
+}
+
+// ErrChecksum is returned by Reader when the checksum of a snapshot doesn't match.
+var ErrChecksum = errors.New("snapshot checksum mismatch")
+
+// Reader reads a snapshot.
+type Reader struct {
+	r   io.Reader
+	sum hash.Hash
+	hdr Header
+	buf []byte
+}
+
+// NewReader reads the header from r, and returns a Reader for the records.
+func NewReader(r io.Reader) (*Reader, error) {
+
+	sr := &Reader{sum: sha256.New()}
+	sr.r = io.TeeReader(bufio.NewReaderSize(r, 1<<20), sr.sum)
+
+	b := make([]byte, 48)
+	_, err := io.ReadFull(sr.r, b)
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	if !bytes.Equal(b[:4], magic[:]) {
+		return nil, fmt.Errorf("not a ClaimTrie snapshot")
+	}
+
+	sr.hdr.Version = binary.BigEndian.Uint32(b[4:])
+	if sr.hdr.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version %d", sr.hdr.Version)
+	}
+	sr.hdr.Net = wire.BitcoinNet(binary.BigEndian.Uint32(b[8:]))
+	sr.hdr.Height = int32(binary.BigEndian.Uint32(b[12:]))
+	copy(sr.hdr.Root[:], b[16:])
+
+	return sr, nil
+}
+
+// Header returns the header of the snapshot.
+func (sr *Reader) Header() Header {
+	return sr.hdr
+}
+
+// Next returns the next record of the snapshot. After the last record, it
+// verifies the checksum and returns io.EOF. A snapshot which ends without the
+// trailer results in io.ErrUnexpectedEOF.
+func (sr *Reader) Next() (*Record, error) {
+
+	// The checksum covers everything before the payload of the trailer.
+	sum := sr.sum.Sum(nil)
+
+	b := make([]byte, 5)
+	_, err := io.ReadFull(sr.r, b)
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read record: %w", err)
+	}
+
+	typ := RecordType(b[0])
+	size := binary.BigEndian.Uint32(b[1:])
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes exceeds the limit", size)
+	}
+	if cap(sr.buf) < int(size) {
+		sr.buf = make([]byte, size)
+	}
+	payload := sr.buf[:size]
+	_, err = io.ReadFull(sr.r, payload)
+	if err != nil {
+		return nil, fmt.Errorf("read record: %w", noEOF(err))
+	}
+
+	rec := &Record{Type: typ}
+	switch typ {
+	case BlockRecord:
+		if len(payload) != 4+chainhash.HashSize {
+			return nil, fmt.Errorf("invalid block record of %d bytes", len(payload))
+		}
+		rec.Height = int32(binary.BigEndian.Uint32(payload))
+		copy(rec.Hash[:], payload[4:])
+	case TemporalRecord:
+		if len(payload) < 4 {
+			return nil, fmt.Errorf("invalid temporal record of %d bytes", len(payload))
+		}
+		rec.Height = int32(binary.BigEndian.Uint32(payload))
+		rec.Name = append([]byte(nil), payload[4:]...)
+	case ChangesRecord:
+		err = msgpack.Unmarshal(payload, &rec.Changes)
+		if err != nil {
+			return nil, fmt.Errorf("msgpack unmarshal: %w", err)
+		}
+	case endRecord:
+		if !bytes.Equal(payload, sum) {
+			return nil, ErrChecksum
+		}
+		return nil, io.EOF
+	default:
+		return nil, fmt.Errorf("unknown record type %q", byte(typ))
+	}
+
+	return rec, nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// noEOF converts io.EOF in the middle of a record to io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
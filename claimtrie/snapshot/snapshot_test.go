@@ -0,0 +1,72 @@
+package snapshot
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/wire"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+
+	r := require.New(t)
+
+	hdr := Header{Version: Version, Net: wire.TestNet, Height: 2, Root: chainhash.Hash{1, 2, 3}}
+	chg := change.New(change.AddClaim).SetName([]byte("a")).SetClaimID("01").SetAmount(5)
+	changes := []change.Change{chg.SetHeight(1), chg.SetHeight(2).SetClaimID("02")}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, hdr)
+	r.NoError(err)
+	r.NoError(w.WriteBlock(1, &chainhash.Hash{1}))
+	r.NoError(w.WriteBlock(2, &hdr.Root))
+	r.NoError(w.WriteTemporal(3, []byte("a")))
+	r.NoError(w.WriteTemporal(4, nil))
+	r.NoError(w.WriteChanges(changes))
+	r.NoError(w.Close())
+
+	expected := []*Record{
+		{Type: BlockRecord, Height: 1, Hash: chainhash.Hash{1}},
+		{Type: BlockRecord, Height: 2, Hash: hdr.Root},
+		{Type: TemporalRecord, Height: 3, Name: []byte("a")},
+		{Type: TemporalRecord, Height: 4},
+		{Type: ChangesRecord, Changes: changes},
+	}
+
+	rd, err := NewReader(bytes.NewReader(buf.Bytes()))
+	r.NoError(err)
+	r.Equal(hdr, rd.Header())
+	for _, rec := range expected {
+		got, err := rd.Next()
+		r.NoError(err)
+		r.Equal(rec, got)
+	}
+	_, err = rd.Next()
+	r.Equal(io.EOF, err)
+
+	// A truncated snapshot is detected.
+	rd, err = NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-37]))
+	r.NoError(err)
+	for err == nil {
+		_, err = rd.Next()
+	}
+	r.ErrorIs(err, io.ErrUnexpectedEOF)
+
+	// So is a corrupted one.
+	corrupted := append([]byte(nil), buf.Bytes()...)
+	corrupted[len(corrupted)-40] ^= 0xff
+	rd, err = NewReader(bytes.NewReader(corrupted))
+	r.NoError(err)
+	for err == nil {
+		_, err = rd.Next()
+	}
+	r.ErrorIs(err, ErrChecksum)
+
+	_, err = NewReader(bytes.NewReader([]byte("not a snapshot at all, but long enough for a header")))
+	r.Error(err)
+}
@@ -0,0 +1,23 @@
+package takeover
+
+// Repo defines APIs for the takeover journal to access persistence layer.
+type Repo interface {
+	// Save saves the takeovers into the repo. A name is taken over at most once per height.
+	Save(takeovers []Takeover) error
+
+	// Load loads the takeovers of the name, ordered by height.
+	Load(name []byte) ([]Takeover, error)
+
+	// LoadAt loads the takeovers at the height, ordered by name.
+	LoadAt(height int32) ([]Takeover, error)
+
+	// Drop removes the takeovers above finalHeight.
+	Drop(finalHeight int32) error
+
+	// Snapshot returns a read-only copy of the repo as it is now, whose writes
+	// fail. It must be closed, and can be read concurrently with the repo.
+	Snapshot() (Repo, error)
+
+	// Close closes the repo.
+	Close() error
+}
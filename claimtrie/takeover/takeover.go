@@ -0,0 +1,26 @@
+// Package takeover journals the takeovers of the names, which are the changes
+// of the claim controlling a name, so the history of a name can be queried
+// without replaying its changes.
+package takeover
+
+import "fmt"
+
+// Takeover is a change of the claim controlling a name at a height. The claim
+// IDs are empty if the name had, or is left with, no controlling claim.
+type Takeover struct {
+	Height     int32
+	Name       []byte
+	OldClaimID string
+	NewClaimID string
+
+	// The effective amounts of the claims, that of the old one before the
+	// takeover, even if it was spent or expired, and that of the new one
+	// after it.
+	OldAmount int64
+	NewAmount int64
+}
+
+func (t Takeover) String() string {
+	return fmt.Sprintf("%q at height %d, from %s (%d) to %s (%d)",
+		t.Name, t.Height, t.OldClaimID, t.OldAmount, t.NewClaimID, t.NewAmount)
+}
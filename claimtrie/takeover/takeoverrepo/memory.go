@@ -0,0 +1,92 @@
+package takeoverrepo
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/btcsuite/btcd/claimtrie/takeover"
+)
+
+type Memory struct {
+	takeovers []takeover.Takeover
+
+	// readOnly is set on the snapshots of the repo.
+	readOnly bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+func (repo *Memory) Save(takeovers []takeover.Takeover) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	for _, t := range takeovers {
+		t.Name = append([]byte(nil), t.Name...)
+		repo.takeovers = append(repo.takeovers, t)
+	}
+
+	return nil
+}
+
+func (repo *Memory) Load(name []byte) ([]takeover.Takeover, error) {
+
+	var takeovers []takeover.Takeover
+	for _, t := range repo.takeovers {
+		if bytes.Equal(t.Name, name) {
+			takeovers = append(takeovers, t)
+		}
+	}
+	sort.SliceStable(takeovers, func(i, j int) bool {
+		return takeovers[i].Height < takeovers[j].Height
+	})
+
+	return takeovers, nil
+}
+
+func (repo *Memory) LoadAt(height int32) ([]takeover.Takeover, error) {
+
+	var takeovers []takeover.Takeover
+	for _, t := range repo.takeovers {
+		if t.Height == height {
+			takeovers = append(takeovers, t)
+		}
+	}
+	sort.SliceStable(takeovers, func(i, j int) bool {
+		return bytes.Compare(takeovers[i].Name, takeovers[j].Name) < 0
+	})
+
+	return takeovers, nil
+}
+
+func (repo *Memory) Drop(finalHeight int32) error {
+
+	if repo.readOnly {
+		return errReadOnly
+	}
+
+	kept := repo.takeovers[:0]
+	for _, t := range repo.takeovers {
+		if t.Height <= finalHeight {
+			kept = append(kept, t)
+		}
+	}
+	repo.takeovers = kept
+
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now.
+func (repo *Memory) Snapshot() (takeover.Repo, error) {
+
+	snap := &Memory{takeovers: append([]takeover.Takeover(nil), repo.takeovers...), readOnly: true}
+
+	return snap, nil
+}
+
+func (repo *Memory) Close() error {
+	return nil
+}
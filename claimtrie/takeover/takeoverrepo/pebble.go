@@ -0,0 +1,211 @@
+package takeoverrepo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/claimtrie/takeover"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// errReadOnly is returned by the writes to a snapshot of the repo.
+var errReadOnly = errors.New("takeover repo snapshot is read-only")
+
+// The takeovers are indexed by name and by height, with the same value:
+//
+//	'n' + len(name)(2B) + name + height(4B) -> msgpack(takeover)
+//	'h' + height(4B) + name -> msgpack(takeover)
+//
+// The length of the name keeps the takeovers of a name apart from those of the
+// names it prefixes.
+type Pebble struct {
+	db *pebble.DB
+
+	// snap is set on the snapshots of the repo, which read from it instead of db.
+	snap *pebble.Snapshot
+}
+
+const (
+	namePrefix   = 'n'
+	heightPrefix = 'h'
+)
+
+func NewPebble(path string) (*Pebble, error) {
+
+	db, err := pebble.Open(path, &pebble.Options{Cache: pebble.NewCache(16 << 20)})
+	if err != nil {
+		return nil, fmt.Errorf("pebble open %s, %w", path, err)
+	}
+
+	repo := &Pebble{db: db}
+
+	return repo, nil
+}
+
+func nameKey(name []byte) []byte {
+	key := make([]byte, 3, 3+len(name)+4)
+	key[0] = namePrefix
+	binary.BigEndian.PutUint16(key[1:], uint16(len(name)))
+	return append(key, name...)
+}
+
+func heightKey(height int32) []byte {
+	key := make([]byte, 5)
+	key[0] = heightPrefix
+	binary.BigEndian.PutUint32(key[1:], uint32(height))
+	return key
+}
+
+func appendHeight(key []byte, height int32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(height))
+	return append(key, b[:]...)
+}
+
+func (repo *Pebble) Save(takeovers []takeover.Takeover) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	for _, t := range takeovers {
+		value, err := msgpack.Marshal(t)
+		if err != nil {
+			return fmt.Errorf("msgpack marshal value: %w", err)
+		}
+		err = batch.Set(appendHeight(nameKey(t.Name), t.Height), value, pebble.NoSync)
+		if err != nil {
+			return fmt.Errorf("pebble set: %w", err)
+		}
+		err = batch.Set(append(heightKey(t.Height), t.Name...), value, pebble.NoSync)
+		if err != nil {
+			return fmt.Errorf("pebble set: %w", err)
+		}
+	}
+
+	err := batch.Commit(pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble save commit: %w", err)
+	}
+	return nil
+}
+
+func (repo *Pebble) Load(name []byte) ([]takeover.Takeover, error) {
+
+	prefix := nameKey(name)
+	return repo.load(prefix, append(prefix, 0xff, 0xff, 0xff, 0xff, 0xff))
+}
+
+func (repo *Pebble) LoadAt(height int32) ([]takeover.Takeover, error) {
+
+	return repo.load(heightKey(height), heightKey(height+1))
+}
+
+func (repo *Pebble) load(lower, upper []byte) ([]takeover.Takeover, error) {
+
+	iter := repo.reader().NewIter(&pebble.IterOptions{LowerBound: lower, UpperBound: upper})
+
+	var takeovers []takeover.Takeover
+	for iter.First(); iter.Valid(); iter.Next() {
+		var t takeover.Takeover
+		err := msgpack.Unmarshal(iter.Value(), &t)
+		if err != nil {
+			iter.Close()
+			return nil, fmt.Errorf("msgpack unmarshal: %w", err)
+		}
+		takeovers = append(takeovers, t)
+	}
+
+	err := iter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("pebble get: %w", err)
+	}
+
+	return takeovers, nil
+}
+
+func (repo *Pebble) Drop(finalHeight int32) error {
+
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
+	lower := heightKey(finalHeight + 1)
+	upper := []byte{heightPrefix, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+	batch := repo.db.NewBatch()
+	defer batch.Close()
+
+	iter := repo.reader().NewIter(&pebble.IterOptions{LowerBound: lower, UpperBound: upper})
+	for iter.First(); iter.Valid(); iter.Next() {
+		var t takeover.Takeover
+		err := msgpack.Unmarshal(iter.Value(), &t)
+		if err != nil {
+			iter.Close()
+			return fmt.Errorf("msgpack unmarshal: %w", err)
+		}
+		err = batch.Delete(appendHeight(nameKey(t.Name), t.Height), pebble.NoSync)
+		if err != nil {
+			iter.Close()
+			return fmt.Errorf("pebble delete: %w", err)
+		}
+	}
+	err := iter.Close()
+	if err != nil {
+		return fmt.Errorf("pebble get: %w", err)
+	}
+
+	err = batch.DeleteRange(lower, upper, pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble delete range: %w", err)
+	}
+
+	err = batch.Commit(pebble.NoSync)
+	if err != nil {
+		return fmt.Errorf("pebble drop commit: %w", err)
+	}
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now, which must be closed.
+func (repo *Pebble) Snapshot() (takeover.Repo, error) {
+
+	if repo.snap != nil {
+		return nil, errReadOnly
+	}
+
+	return &Pebble{snap: repo.db.NewSnapshot()}, nil
+}
+
+// reader returns the snapshot of a snapshot repo, or the DB.
+func (repo *Pebble) reader() pebble.Reader {
+	if repo.snap != nil {
+		return repo.snap
+	}
+	return repo.db
+}
+
+func (repo *Pebble) Close() error {
+
+	if repo.snap != nil {
+		return repo.snap.Close()
+	}
+
+	err := repo.db.Flush()
+	if err != nil {
+		return fmt.Errorf("pebble flush: %w", err)
+	}
+
+	err = repo.db.Close()
+	if err != nil {
+		return fmt.Errorf("pebble close: %w", err)
+	}
+
+	return nil
+}
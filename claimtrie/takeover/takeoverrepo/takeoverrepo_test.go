@@ -0,0 +1,77 @@
+package takeoverrepo
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/claimtrie/takeover"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPebble(t *testing.T) {
+
+	r := require.New(t)
+
+	repo, err := NewPebble(t.TempDir())
+	r.NoError(err)
+	defer func() {
+		err := repo.Close()
+		r.NoError(err)
+	}()
+
+	testTakeoverRepo(t, repo)
+}
+
+func TestMemory(t *testing.T) {
+	testTakeoverRepo(t, NewMemory())
+}
+
+func testTakeoverRepo(t *testing.T, repo takeover.Repo) {
+
+	r := require.New(t)
+
+	t1 := takeover.Takeover{Height: 5, Name: []byte("a"), NewClaimID: "01", NewAmount: 10}
+	t2 := takeover.Takeover{Height: 9, Name: []byte("a"), OldClaimID: "01", NewClaimID: "02", OldAmount: 10, NewAmount: 20}
+	t3 := takeover.Takeover{Height: 9, Name: []byte("ab"), NewClaimID: "03", NewAmount: 30}
+	t4 := takeover.Takeover{Height: 300, Name: []byte("a"), OldClaimID: "02", OldAmount: 20}
+
+	r.NoError(repo.Save([]takeover.Takeover{t3, t4}))
+	r.NoError(repo.Save([]takeover.Takeover{t2, t1}))
+
+	takeovers, err := repo.Load([]byte("a")) // not those of "ab"
+	r.NoError(err)
+	r.Equal([]takeover.Takeover{t1, t2, t4}, takeovers)
+
+	takeovers, err = repo.Load([]byte("b"))
+	r.NoError(err)
+	r.Empty(takeovers)
+
+	takeovers, err = repo.LoadAt(9)
+	r.NoError(err)
+	r.Equal([]takeover.Takeover{t2, t3}, takeovers)
+
+	r.NoError(repo.Drop(5))
+	takeovers, err = repo.Load([]byte("a"))
+	r.NoError(err)
+	r.Equal([]takeover.Takeover{t1}, takeovers)
+
+	takeovers, err = repo.LoadAt(9)
+	r.NoError(err)
+	r.Empty(takeovers)
+
+	takeovers, err = repo.Load([]byte("ab"))
+	r.NoError(err)
+	r.Empty(takeovers)
+
+	// A snapshot keeps the takeovers as they were, and can't be written to.
+	snap, err := repo.Snapshot()
+	r.NoError(err)
+	r.NoError(repo.Drop(0))
+	r.Error(snap.Save([]takeover.Takeover{t2}))
+	r.Error(snap.Drop(0))
+
+	takeovers, err = snap.Load([]byte("a"))
+	r.NoError(err)
+	r.Equal([]takeover.Takeover{t1}, takeovers)
+	r.NoError(snap.Close())
+}
@@ -4,5 +4,16 @@ package temporal
 type Repo interface {
 	SetNodesAt(names [][]byte, heights []int32) error
 	NodesAt(height int32) ([][]byte, error)
+
+	// IterateAll iterates the scheduled names ordered by height until the predicate returns false.
+	IterateAll(predicate func(height int32, name []byte) bool) error
+
+	// IterateFrom iterates the names scheduled at or after the height, ordered by height, until the predicate returns false.
+	IterateFrom(height int32, predicate func(height int32, name []byte) bool) error
+
+	// Snapshot returns a read-only copy of the repo as it is now, whose writes
+	// fail. It must be closed, and can be read concurrently with the repo.
+	Snapshot() (Repo, error)
+
 	Close() error
 }
@@ -1,7 +1,16 @@
 package temporalrepo
 
+import (
+	"sort"
+
+	"github.com/btcsuite/btcd/claimtrie/temporal"
+)
+
 type Memory struct {
 	cache map[int32]map[string]bool
+
+	// readOnly is set on the snapshots of the repo.
+	readOnly bool
 }
 
 func NewMemory() *Memory {
@@ -12,6 +21,10 @@ func NewMemory() *Memory {
 
 func (repo *Memory) SetNodesAt(names [][]byte, heights []int32) error {
 
+	if repo.readOnly {
+		return errReadOnly
+	}
+
 	for i, height := range heights {
 		c, ok := repo.cache[height]
 		if !ok {
@@ -36,6 +49,51 @@ func (repo *Memory) NodesAt(height int32) ([][]byte, error) {
 	return names, nil
 }
 
+func (repo *Memory) IterateAll(predicate func(height int32, name []byte) bool) error {
+	return repo.IterateFrom(0, predicate)
+}
+
+func (repo *Memory) IterateFrom(start int32, predicate func(height int32, name []byte) bool) error {
+
+	heights := make([]int32, 0, len(repo.cache))
+	for height := range repo.cache {
+		if height >= start {
+			heights = append(heights, height)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+
+	for _, height := range heights {
+		names := make([]string, 0, len(repo.cache[height]))
+		for name := range repo.cache[height] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if !predicate(height, []byte(name)) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now.
+func (repo *Memory) Snapshot() (temporal.Repo, error) {
+
+	snap := &Memory{cache: make(map[int32]map[string]bool, len(repo.cache)), readOnly: true}
+	for height, names := range repo.cache {
+		c := make(map[string]bool, len(names))
+		for name := range names {
+			c[name] = true
+		}
+		snap.cache[height] = c
+	}
+
+	return snap, nil
+}
+
 func (repo *Memory) Close() error {
 	return nil
 }
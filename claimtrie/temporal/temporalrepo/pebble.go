@@ -3,13 +3,22 @@ package temporalrepo
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 
+	"github.com/btcsuite/btcd/claimtrie/temporal"
+
 	"github.com/cockroachdb/pebble"
 )
 
+// errReadOnly is returned by the writes to a snapshot of the repo.
+var errReadOnly = errors.New("temporal repo snapshot is read-only")
+
 type Pebble struct {
 	db *pebble.DB
+
+	// snap is set on the snapshots of the repo, which read from it instead of db.
+	snap *pebble.Snapshot
 }
 
 func NewPebble(path string) (*Pebble, error) {
@@ -26,6 +35,10 @@ func NewPebble(path string) (*Pebble, error) {
 
 func (repo *Pebble) SetNodesAt(name [][]byte, heights []int32) error {
 
+	if repo.snap != nil {
+		return errReadOnly
+	}
+
 	// key format: height(4B) + 0(1B) + name(varable length)
 	key := bytes.NewBuffer(nil)
 	batch := repo.db.NewBatch()
@@ -61,7 +74,7 @@ func (repo *Pebble) NodesAt(height int32) ([][]byte, error) {
 
 	var names [][]byte
 
-	iter := repo.db.NewIter(prefixIterOptions)
+	iter := repo.reader().NewIter(prefixIterOptions)
 	for iter.First(); iter.Valid(); iter.Next() {
 		// Skipping the first 5 bytes (height and a null byte), we get the name.
 		name := make([]byte, len(iter.Key())-5)
@@ -77,8 +90,63 @@ func (repo *Pebble) NodesAt(height int32) ([][]byte, error) {
 	return names, nil
 }
 
+func (repo *Pebble) IterateAll(predicate func(height int32, name []byte) bool) error {
+	return repo.iterate(nil, predicate)
+}
+
+func (repo *Pebble) IterateFrom(height int32, predicate func(height int32, name []byte) bool) error {
+
+	start := bytes.NewBuffer(nil)
+	binary.Write(start, binary.BigEndian, height)
+
+	return repo.iterate(&pebble.IterOptions{LowerBound: start.Bytes()}, predicate)
+}
+
+func (repo *Pebble) iterate(opts *pebble.IterOptions, predicate func(height int32, name []byte) bool) error {
+
+	iter := repo.reader().NewIter(opts)
+	for iter.First(); iter.Valid(); iter.Next() {
+		// The key is made of the height, a null byte, and the name.
+		height := int32(binary.BigEndian.Uint32(iter.Key()))
+		name := make([]byte, len(iter.Key())-5)
+		copy(name, iter.Key()[5:]) // iter.Key() reuses its buffer
+		if !predicate(height, name) {
+			break
+		}
+	}
+
+	err := iter.Close()
+	if err != nil {
+		return fmt.Errorf("pebble iter: %w", err)
+	}
+
+	return nil
+}
+
+// Snapshot returns a read-only copy of the repo as it is now, which must be closed.
+func (repo *Pebble) Snapshot() (temporal.Repo, error) {
+
+	if repo.snap != nil {
+		return nil, errReadOnly
+	}
+
+	return &Pebble{snap: repo.db.NewSnapshot()}, nil
+}
+
+// reader returns the snapshot of a snapshot repo, or the DB.
+func (repo *Pebble) reader() pebble.Reader {
+	if repo.snap != nil {
+		return repo.snap
+	}
+	return repo.db
+}
+
 func (repo *Pebble) Close() error {
 
+	if repo.snap != nil {
+		return repo.snap.Close()
+	}
+
 	err := repo.db.Flush()
 	if err != nil {
 		return fmt.Errorf("pebble fludh: %w", err)
@@ -77,4 +77,39 @@ func testTemporalRepo(t *testing.T, repo temporal.Repo) {
 	names, err = repo.NodesAt(3)
 	r.NoError(err)
 	r.ElementsMatch([][]byte{nameA, nameC}, names)
+
+	var heights []int32
+	names = nil
+	err = repo.IterateAll(func(height int32, name []byte) bool {
+		heights = append(heights, height)
+		names = append(names, name)
+		return height < 4
+	})
+	r.NoError(err)
+	r.Equal([]int32{1, 1, 2, 3, 3, 4}, heights)
+	r.Equal([][]byte{nameA, nameB, nameA, nameA, nameC, nameB}, names)
+
+	heights, names = nil, nil
+	err = repo.IterateFrom(4, func(height int32, name []byte) bool {
+		heights = append(heights, height)
+		names = append(names, name)
+		return true
+	})
+	r.NoError(err)
+	r.Equal([]int32{4, 4, 5, 8}, heights)
+	r.Equal([][]byte{nameB, nameC, nameB, nameC}, names)
+
+	// A snapshot keeps the names as they were, and can't be written to.
+	snap, err := repo.Snapshot()
+	r.NoError(err)
+	r.NoError(repo.SetNodesAt([][]byte{nameA}, []int32{9}))
+	r.Error(snap.SetNodesAt([][]byte{nameB}, []int32{9}))
+
+	names, err = snap.NodesAt(9)
+	r.NoError(err)
+	r.Empty(names)
+	names, err = snap.NodesAt(2)
+	r.NoError(err)
+	r.ElementsMatch([][]byte{nameA}, names)
+	r.NoError(snap.Close())
 }
@@ -0,0 +1,219 @@
+package claimtrie
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/anomaly"
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/merkletrie"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/snapshot"
+	"github.com/btcsuite/btcd/claimtrie/takeover"
+)
+
+// ErrNoViewProof is returned when a proof is requested from the view of a
+// ClaimTrie whose trie is kept in RAM, as only its current root can be proven.
+var ErrNoViewProof = errors.New("proofs require a persisted merkle trie")
+
+// View is a read-only view of the ClaimTrie at the height it had when the view
+// was created. It reads from snapshots of the repos, so it stays consistent while
+// blocks are appended to and reset from the ClaimTrie, and it is safe for
+// concurrent use. The changes made since the last AppendBlock aren't included.
+// A view must be closed to release its snapshots.
+type View struct {
+	// A ClaimTrie over the snapshots, whose methods are only read from.
+	ct *ClaimTrie
+}
+
+// View returns a view of the ClaimTrie at its current height. It is safe to
+// call concurrently with AppendBlock and ResetHeight.
+func (ct *ClaimTrie) View() (*View, error) {
+
+	ct.mu.RLock()
+	defer ct.mu.RUnlock()
+
+	return ct.view()
+}
+
+// view returns a view of the ClaimTrie at its current height, which must not
+// be appended to nor reset meanwhile.
+func (ct *ClaimTrie) view() (*View, error) {
+
+	vt := &ClaimTrie{height: ct.height}
+	v := &View{ct: vt}
+
+	err := v.snapshotRepos(ct)
+	if err != nil {
+		v.Close()
+		return nil, err
+	}
+
+	// The vertices of the persisted trie are keyed by their hash, so those of
+	// the recorded roots are never overwritten. The trie isn't closed by the view.
+	if mt, ok := ct.merkleTrie.(*merkletrie.MerkleTrie); ok {
+		vt.merkleTrie = mt
+	}
+
+	return v, nil
+}
+
+// snapshotRepos sets the repos of the view to snapshots of those of ct, which
+// are closed by the view.
+func (v *View) snapshotRepos(ct *ClaimTrie) error {
+
+	vt := v.ct
+
+	blockRepo, err := ct.blockRepo.Snapshot()
+	if err != nil {
+		return fmt.Errorf("block repo snapshot: %w", err)
+	}
+	vt.cleanups = append(vt.cleanups, blockRepo.Close)
+	vt.blockRepo = blockRepo
+
+	temporalRepo, err := ct.temporalRepo.Snapshot()
+	if err != nil {
+		return fmt.Errorf("temporal repo snapshot: %w", err)
+	}
+	vt.cleanups = append(vt.cleanups, temporalRepo.Close)
+	vt.temporalRepo = temporalRepo
+
+	// The cleanup is delegated to the Node Manager.
+	nodeRepo, err := snapshotNodeRepo(ct.nodeRepo)
+	if err != nil {
+		return fmt.Errorf("node repo snapshot: %w", err)
+	}
+	// NodeAt and Simulate don't use the cache, so it's minimal.
+	baseManager, err := node.NewBaseManagerAt(nodeRepo, ct.height, 1)
+	if err != nil {
+		nodeRepo.Close()
+		return fmt.Errorf("new node manager: %w", err)
+	}
+	nodeManager := node.NewNormalizingManager(baseManager)
+	vt.cleanups = append(vt.cleanups, nodeManager.Close)
+	vt.nodeRepo = nodeRepo
+	vt.nodeManager = nodeManager
+
+	anomalyRepo, err := ct.anomalyRepo.Snapshot()
+	if err != nil {
+		return fmt.Errorf("anomaly repo snapshot: %w", err)
+	}
+	vt.cleanups = append(vt.cleanups, anomalyRepo.Close)
+	vt.anomalyRepo = anomalyRepo
+
+	takeoverRepo, err := ct.takeoverRepo.Snapshot()
+	if err != nil {
+		return fmt.Errorf("takeover repo snapshot: %w", err)
+	}
+	vt.cleanups = append(vt.cleanups, takeoverRepo.Close)
+	vt.takeoverRepo = takeoverRepo
+
+	if ct.claimIDRepo != nil {
+		claimIDRepo, err := ct.claimIDRepo.Snapshot()
+		if err != nil {
+			return fmt.Errorf("claim ID repo snapshot: %w", err)
+		}
+		vt.cleanups = append(vt.cleanups, claimIDRepo.Close)
+		vt.claimIDRepo = claimIDRepo
+	}
+
+	if ct.channelRepo != nil {
+		channelRepo, err := ct.channelRepo.Snapshot()
+		if err != nil {
+			return fmt.Errorf("channel repo snapshot: %w", err)
+		}
+		vt.cleanups = append(vt.cleanups, channelRepo.Close)
+		vt.channelRepo = channelRepo
+	}
+
+	return nil
+}
+
+// Close releases the snapshots of the view.
+// Any calls to the View after Close() being called results undefined behaviour.
+func (v *View) Close() error {
+	return v.ct.Close()
+}
+
+// Height returns the height of the view.
+func (v *View) Height() int32 {
+	return v.ct.height
+}
+
+// MerkleHash returns the Merkle Hash of the ClaimTrie at the height of the view.
+func (v *View) MerkleHash() (*chainhash.Hash, error) {
+	return v.ct.merkleHashAt(v.ct.height)
+}
+
+// NodeAt is ClaimTrie.NodeAt up to the height of the view.
+func (v *View) NodeAt(height int32, name []byte) (*node.Node, error) {
+	return v.ct.NodeAt(height, name)
+}
+
+// SimulateTakeover is ClaimTrie.SimulateTakeover at the height after the view.
+func (v *View) SimulateTakeover(name []byte, amount int64, id *node.ClaimID) (*node.Simulation, error) {
+	return v.ct.SimulateTakeover(name, amount, id)
+}
+
+// ListNames is ClaimTrie.ListNames at the height of the view.
+func (v *View) ListNames(prefix, cursor []byte, limit int) (names []ListedName, next []byte, err error) {
+	return v.ct.ListNames(prefix, cursor, limit)
+}
+
+// ClaimsExpiring is ClaimTrie.ClaimsExpiring after the height of the view.
+func (v *View) ClaimsExpiring(from, to int32) ([]ScheduledClaim, error) {
+	return v.ct.ClaimsExpiring(from, to)
+}
+
+// PendingActivations is ClaimTrie.PendingActivations after the height of the view.
+func (v *View) PendingActivations(from, to int32) ([]ScheduledClaim, error) {
+	return v.ct.PendingActivations(from, to)
+}
+
+// ClaimChanges is ClaimTrie.ClaimChanges up to the height of the view.
+func (v *View) ClaimChanges(id node.ClaimID) ([]change.Change, error) {
+	return v.ct.ClaimChanges(id)
+}
+
+// ClaimsInChannel is ClaimTrie.ClaimsInChannel up to the height of the view.
+func (v *View) ClaimsInChannel(channelID node.ClaimID, height int32) ([]ChannelClaim, error) {
+	return v.ct.ClaimsInChannel(channelID, height)
+}
+
+// ClaimSignatureValid is ClaimTrie.ClaimSignatureValid up to the height of the view.
+func (v *View) ClaimSignatureValid(c *node.Claim, height int32) (bool, error) {
+	return v.ct.ClaimSignatureValid(c, height)
+}
+
+// NameProof is ClaimTrie.NameProof up to the height of the view. It returns
+// ErrNoViewProof if the trie is kept in RAM.
+func (v *View) NameProof(name []byte, height int32) (*merkletrie.Proof, *node.Node, error) {
+
+	if v.ct.merkleTrie == nil {
+		return nil, nil, ErrNoViewProof
+	}
+
+	return v.ct.NameProof(name, height)
+}
+
+// Anomalies is ClaimTrie.Anomalies up to the height of the view.
+func (v *View) Anomalies(fromHeight, toHeight int32) ([]anomaly.Anomaly, error) {
+	return v.ct.Anomalies(fromHeight, toHeight)
+}
+
+// Takeovers is ClaimTrie.Takeovers up to the height of the view.
+func (v *View) Takeovers(height int32) ([]takeover.Takeover, error) {
+	return v.ct.Takeovers(height)
+}
+
+// NameHistory is ClaimTrie.NameHistory up to the height of the view.
+func (v *View) NameHistory(name []byte) ([]takeover.Takeover, error) {
+	return v.ct.NameHistory(name)
+}
+
+// ExportSnapshot is ClaimTrie.ExportSnapshot at the height of the view.
+func (v *View) ExportSnapshot(w io.Writer) (*snapshot.Header, error) {
+	return v.ct.ExportSnapshot(w)
+}
@@ -58,6 +58,7 @@ const (
 	blockMaxSizeMax              = blockchain.MaxBlockBaseSize - 1000
 	blockMaxWeightMin            = 4000
 	blockMaxWeightMax            = blockchain.MaxBlockWeight - 4000
+	claimTriePruneDepthMin       = 1000
 	defaultGenerate              = false
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
@@ -108,9 +109,15 @@ type config struct {
 	BlockPrioritySize    uint32        `long:"blockprioritysize" description:"Size in bytes for high-priority/low-fee transactions when creating a block"`
 	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
 	ConfigFile           string        `short:"C" long:"configfile" description:"Path to configuration file"`
-	ClaimTrieImpl        string        `long:"clmtimpl" description:"Implementation of ClaimTrie"`
+	ClaimTrieImpl        string        `long:"clmtimpl" description:"Implementation of ClaimTrie (none, memory)"`
 	ClaimTrieRecord      bool          `long:"clmtrecord" description:"Record claim operations made to ClaimTrie"`
 	ClaimTrieHeight      uint32        `long:"clmtheight" description:"Reset height of ClaimTrie"`
+	ClaimTrieRAM         bool          `long:"clmtram" description:"Keep the merkle trie of the ClaimTrie in memory, which speeds up sync at the cost of RAM"`
+	ClaimTrieCacheSize   int           `long:"clmtcachesize" description:"Number of claim nodes cached in memory by the ClaimTrie (0 selects the default of 16000)"`
+	ClaimTriePruneDepth  int32         `long:"clmtprunedepth" description:"Number of blocks of claim history to keep, collapsing the older changes in the background -- The ClaimTrie can't be reset deeper afterwards (0 keeps the whole history)"`
+	ClaimTrieSnapshot    string        `long:"clmtsnapshot" description:"Bootstrap an empty ClaimTrie from the snapshot file, which is verified against the block header at its height -- The blocks must be synced up to the height of the snapshot"`
+	ClaimIDIndex         bool          `long:"claimidindex" description:"Maintain an index of claims by claim ID which makes the getclaimbyid and getclaimhistory RPCs available"`
+	ChannelIndex         bool          `long:"channelindex" description:"Maintain an index of claims by signing channel which makes the getclaimsinchannel RPC available and the signatures of claims verified"`
 	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
 	DataDir              string        `short:"b" long:"datadir" description:"Directory to store data"`
@@ -689,6 +696,15 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Keep enough claim history for the reorganizations.
+	if cfg.ClaimTriePruneDepth != 0 && cfg.ClaimTriePruneDepth < claimTriePruneDepthMin {
+		str := "%s: The clmtprunedepth option may not be less than %d -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, claimTriePruneDepthMin, cfg.ClaimTriePruneDepth)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Validate any given whitelisted IP addresses and networks.
 	if len(cfg.Whitelists) > 0 {
 		var ip net.IP
@@ -44,10 +44,21 @@ Application Options:
                               transactions when creating a block (default:
                               50000)
       --blocksonly            Do not accept transactions from remote peers.
+      --channelindex          Maintain an index of claims by signing channel
+                              which makes the getclaimsinchannel RPC available
+                              and the signatures of claims verified
+      --claimidindex          Maintain an index of claims by claim ID which
+                              makes the getclaimbyid and getclaimhistory RPCs
+                              available
   -C, --configfile=           Path to configuration file
-	    --clmtimpl=             Implementation of ClaimTrie
+	    --clmtimpl=             Implementation of ClaimTrie (none, memory)
 	    --clmtrecord=           Record claim operations
 	    --clmtheight=           Reset height of ClaimTrie
+	    --clmtram               Keep the merkle trie of the ClaimTrie in memory
+	    --clmtcachesize=        Number of claim nodes cached in memory by the ClaimTrie
+	    --clmtprunedepth=       Number of blocks of claim history to keep (0 keeps
+	                            the whole history)
+	    --clmtsnapshot=         Bootstrap an empty ClaimTrie from the snapshot file
       --connect=              Connect only to the specified peers at startup
       --cpuprofile=           Write CPU profile to the specified file
   -b, --datadir=              Directory to store data
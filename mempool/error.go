@@ -90,6 +90,14 @@ func extractRejectCode(err error) (wire.RejectCode, bool) {
 		case blockchain.ErrForkTooOld:
 			code = wire.RejectCheckpoint
 
+		// Rejected due to a claim update which the ClaimTrie would ignore.
+		case blockchain.ErrInvalidClaimUpdate:
+			code = wire.RejectInvalid
+
+		// Rejected due to the policy on supports of unknown claims.
+		case blockchain.ErrUnknownClaimSupport:
+			code = wire.RejectNonstandard
+
 		// Everything else is due to the block or transaction being invalid.
 		default:
 			code = wire.RejectInvalid
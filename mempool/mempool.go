@@ -17,6 +17,7 @@ import (
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/node"
 	"github.com/btcsuite/btcd/mining"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
@@ -89,6 +90,12 @@ type Config struct {
 	// into the mempool or not.
 	IsDeploymentActive func(deploymentID uint32) (bool, error)
 
+	// ClaimExists defines the function to use to determine whether a claim
+	// with the given claim ID currently exists under the name in the
+	// ClaimTrie.  Supports of unknown claims are not rejected when it is
+	// nil.
+	ClaimExists func(name []byte, claimID node.ClaimID) (bool, error)
+
 	// SigCache defines a signature cache to use.
 	SigCache *txscript.SigCache
 
@@ -182,6 +189,7 @@ type TxPool struct {
 	orphans       map[chainhash.Hash]*orphanTx
 	orphansByPrev map[wire.OutPoint]map[chainhash.Hash]*btcutil.Tx
 	outpoints     map[wire.OutPoint]*btcutil.Tx
+	claims        map[node.ClaimID]int
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
 
@@ -490,6 +498,12 @@ func (mp *TxPool) removeTransaction(tx *btcutil.Tx, removeRedeemers bool) {
 		for _, txIn := range txDesc.Tx.MsgTx().TxIn {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
+		for _, id := range claimIDs(txDesc.Tx) {
+			mp.claims[id]--
+			if mp.claims[id] <= 0 {
+				delete(mp.claims, id)
+			}
+		}
 		delete(mp.pool, *txHash)
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 	}
@@ -551,6 +565,9 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *btcutil
 	for _, txIn := range tx.MsgTx().TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
+	for _, id := range claimIDs(tx) {
+		mp.claims[id]++
+	}
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
 
 	// Add unconfirmed address index entries associated with the transaction
@@ -567,6 +584,67 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *btcutil
 	return txD
 }
 
+// claimIDs returns the IDs of the claims created or updated by the outputs of
+// the transaction.
+func claimIDs(tx *btcutil.Tx) []node.ClaimID {
+	var ids []node.ClaimID
+	for i, txOut := range tx.MsgTx().TxOut {
+		cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+		if err != nil {
+			continue
+		}
+		switch cs.Opcode() {
+		case txscript.OP_CLAIMNAME:
+			op := wire.OutPoint{Hash: *tx.Hash(), Index: uint32(i)}
+			ids = append(ids, node.NewClaimID(op))
+		case txscript.OP_UPDATECLAIM:
+			var id node.ClaimID
+			copy(id[:], cs.ClaimID())
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// checkClaimSupports ensures every OP_SUPPORTCLAIM output of the passed
+// transaction supports a claim which either exists in the ClaimTrie, or is
+// created or updated by a transaction in the pool.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) checkClaimSupports(tx *btcutil.Tx) error {
+	if mp.cfg.ClaimExists == nil {
+		return nil
+	}
+
+	for i, txOut := range tx.MsgTx().TxOut {
+		cs, err := txscript.DecodeClaimScript(txOut.PkScript)
+		if err != nil || cs.Opcode() != txscript.OP_SUPPORTCLAIM {
+			continue
+		}
+
+		var id node.ClaimID
+		copy(id[:], cs.ClaimID())
+		if mp.claims[id] > 0 {
+			continue
+		}
+		exists, err := mp.cfg.ClaimExists(cs.Name(), id)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			str := fmt.Sprintf("output %d of transaction %v supports "+
+				"unknown claim %v of name %q", i, tx.Hash(), id,
+				cs.Name())
+			return chainRuleError(blockchain.RuleError{
+				ErrorCode:   blockchain.ErrUnknownClaimSupport,
+				Description: str,
+			})
+		}
+	}
+
+	return nil
+}
+
 // checkPoolDoubleSpend checks whether or not the passed transaction is
 // attempting to spend coins already spent by other transactions in the pool.
 // If it does, we'll check whether each of those transactions are signaling for
@@ -1093,6 +1171,21 @@ func (mp *TxPool) maybeAcceptTransaction(tx *btcutil.Tx, isNew, rateLimit, rejec
 		return nil, nil, err
 	}
 
+	// Don't allow claim updates which would be ignored by the ClaimTrie,
+	// regardless of whether the soft-fork enforcing this is active, nor
+	// supports of unknown claims.
+	err = blockchain.CheckClaimUpdates(tx, utxoView, nextBlockHeight)
+	if err != nil {
+		if cerr, ok := err.(blockchain.RuleError); ok {
+			return nil, nil, chainRuleError(cerr)
+		}
+		return nil, nil, err
+	}
+	err = mp.checkClaimSupports(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Don't allow transactions with non-standard inputs if the network
 	// parameters forbid their acceptance.
 	if !mp.cfg.Policy.AcceptNonStd {
@@ -1563,5 +1656,6 @@ func New(cfg *Config) *TxPool {
 		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*btcutil.Tx),
 		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
 		outpoints:      make(map[wire.OutPoint]*btcutil.Tx),
+		claims:         make(map[node.ClaimID]int),
 	}
 }
@@ -0,0 +1,266 @@
+package mempool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// fundAmount is the amount of the outputs of the funding transaction, which
+// covers the fees of claims.
+const fundAmount = 100 * btcutil.SatoshiPerBitcoin
+
+// fakeChain is used by the pool harness to provide the utxos and claims of a
+// mock chain to the memory pool.
+type fakeChain struct {
+	utxos  *blockchain.UtxoViewpoint
+	claims map[node.ClaimID]bool
+}
+
+// FetchUtxoView loads the utxos referenced by the passed transaction from the
+// mock chain, as well as its own outputs.
+func (c *fakeChain) FetchUtxoView(tx *btcutil.Tx) (*blockchain.UtxoViewpoint, error) {
+	view := blockchain.NewUtxoViewpoint()
+
+	prevOut := wire.OutPoint{Hash: *tx.Hash()}
+	for i := range tx.MsgTx().TxOut {
+		prevOut.Index = uint32(i)
+		view.Entries()[prevOut] = c.utxos.LookupEntry(prevOut).Clone()
+	}
+	for _, txIn := range tx.MsgTx().TxIn {
+		entry := c.utxos.LookupEntry(txIn.PreviousOutPoint)
+		view.Entries()[txIn.PreviousOutPoint] = entry.Clone()
+	}
+
+	return view, nil
+}
+
+// ClaimExists returns whether the claim was made known to the mock chain.
+func (c *fakeChain) ClaimExists(name []byte, claimID node.ClaimID) (bool, error) {
+	return c.claims[claimID], nil
+}
+
+// poolHarness provides a memory pool over a mock chain, along with spendable
+// outputs of the funding transaction.
+type poolHarness struct {
+	chain *fakeChain
+	pool  *TxPool
+	funds []wire.OutPoint
+}
+
+// newPoolHarness returns a harness whose chain holds a funding transaction
+// with the passed number of anyone-can-spend outputs.
+func newPoolHarness(numOutputs int) *poolHarness {
+	fund := wire.NewMsgTx(wire.TxVersion)
+	fund.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	for i := 0; i < numOutputs; i++ {
+		fund.AddTxOut(wire.NewTxOut(fundAmount, []byte{txscript.OP_TRUE}))
+	}
+	fundTx := btcutil.NewTx(fund)
+
+	chain := &fakeChain{
+		utxos:  blockchain.NewUtxoViewpoint(),
+		claims: map[node.ClaimID]bool{},
+	}
+	chain.utxos.AddTxOuts(fundTx, 1)
+
+	harness := &poolHarness{chain: chain}
+	for i := range fund.TxOut {
+		harness.funds = append(harness.funds, wire.OutPoint{Hash: *fundTx.Hash(), Index: uint32(i)})
+	}
+
+	harness.pool = New(&Config{
+		Policy: Policy{
+			DisableRelayPriority: true,
+			AcceptNonStd:         true,
+			MaxOrphanTxs:         5,
+			MaxOrphanTxSize:      1000,
+			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
+			MinRelayTxFee:        1000,
+			MaxTxVersion:         2,
+		},
+		ChainParams:   &chaincfg.RegressionNetParams,
+		FetchUtxoView: chain.FetchUtxoView,
+		BestHeight:    func() int32 { return 1 },
+		MedianTimePast: func() time.Time {
+			return time.Unix(1600000000, 0)
+		},
+		CalcSequenceLock: func(*btcutil.Tx, *blockchain.UtxoViewpoint) (*blockchain.SequenceLock, error) {
+			return &blockchain.SequenceLock{Seconds: -1, BlockHeight: -1}, nil
+		},
+		IsDeploymentActive: func(uint32) (bool, error) { return false, nil },
+		ClaimExists:        chain.ClaimExists,
+	})
+
+	return harness
+}
+
+// newTx returns a transaction spending the passed funding outpoint with the
+// passed sequence, which pays the passed fee to the passed scripts evenly.
+func newTx(t *testing.T, prevOut wire.OutPoint, sequence uint32, fee int64, scripts ...[]byte) *btcutil.Tx {
+	t.Helper()
+
+	tx := wire.NewMsgTx(wire.TxVersion)
+	txIn := wire.NewTxIn(&prevOut, nil, nil)
+	txIn.Sequence = sequence
+	tx.AddTxIn(txIn)
+	for _, script := range scripts {
+		tx.AddTxOut(wire.NewTxOut((fundAmount-fee)/int64(len(scripts)), script))
+	}
+
+	return btcutil.NewTx(tx)
+}
+
+// claimScript returns an OP_CLAIMNAME script of the passed name.
+func claimScript(t *testing.T, name string) []byte {
+	t.Helper()
+
+	script, err := txscript.ClaimNameScript(name, "value")
+	if err != nil {
+		t.Fatalf("ClaimNameScript: %v", err)
+	}
+	return script
+}
+
+// supportScript returns an OP_SUPPORTCLAIM script of the passed claim.
+func supportScript(t *testing.T, name string, id node.ClaimID) []byte {
+	t.Helper()
+
+	script, err := txscript.SupportClaimScript(name, id[:], nil)
+	if err != nil {
+		t.Fatalf("SupportClaimScript: %v", err)
+	}
+	return script
+}
+
+// checkRuleError ensures the passed error is a rule error of the memory pool
+// wrapping a rule error of the chain with the passed code, which is rejected
+// with the passed reject code.
+func checkRuleError(t *testing.T, err error, code blockchain.ErrorCode, rejectCode wire.RejectCode) {
+	t.Helper()
+
+	rerr, ok := err.(RuleError)
+	if !ok {
+		t.Fatalf("unexpected error %v, want a RuleError of %v", err, code)
+	}
+	cerr, ok := rerr.Err.(blockchain.RuleError)
+	if !ok || cerr.ErrorCode != code {
+		t.Fatalf("unexpected rule error %v, want %v", rerr.Err, code)
+	}
+	if got, found := extractRejectCode(err); !found || got != rejectCode {
+		t.Fatalf("unexpected reject code %v, want %v", got, rejectCode)
+	}
+}
+
+// TestClaimUpdateOfMissingClaim ensures an update of a claim which isn't spent
+// by the transaction is rejected.
+func TestClaimUpdateOfMissingClaim(t *testing.T) {
+	t.Parallel()
+
+	harness := newPoolHarness(1)
+
+	id := node.NewClaimID(wire.OutPoint{Index: 7})
+	script, err := txscript.UpdateClaimScript("a", id[:], "value")
+	if err != nil {
+		t.Fatalf("UpdateClaimScript: %v", err)
+	}
+
+	tx := newTx(t, harness.funds[0], wire.MaxTxInSequenceNum, 1000000, script)
+	_, err = harness.pool.ProcessTransaction(tx, false, false, 0)
+	checkRuleError(t, err, blockchain.ErrInvalidClaimUpdate, wire.RejectInvalid)
+	if harness.pool.HaveTransaction(tx.Hash()) {
+		t.Fatalf("update of missing claim %v accepted", id)
+	}
+}
+
+// TestClaimSupports ensures supports are only accepted for claims which either
+// exist in the chain, or are created by a transaction in the pool.
+func TestClaimSupports(t *testing.T) {
+	t.Parallel()
+
+	harness := newPoolHarness(4)
+
+	missing := node.NewClaimID(wire.OutPoint{Index: 7})
+	tx := newTx(t, harness.funds[0], wire.MaxTxInSequenceNum, 1000000, supportScript(t, "a", missing))
+	_, err := harness.pool.ProcessTransaction(tx, false, false, 0)
+	checkRuleError(t, err, blockchain.ErrUnknownClaimSupport, wire.RejectNonstandard)
+
+	known := node.NewClaimID(wire.OutPoint{Index: 8})
+	harness.chain.claims[known] = true
+	tx = newTx(t, harness.funds[1], wire.MaxTxInSequenceNum, 1000000, supportScript(t, "a", known))
+	_, err = harness.pool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		t.Fatalf("support of claim in the chain: %v", err)
+	}
+
+	claimTx := newTx(t, harness.funds[2], wire.MaxTxInSequenceNum, 1000000, claimScript(t, "b"))
+	_, err = harness.pool.ProcessTransaction(claimTx, false, false, 0)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	pooled := node.NewClaimID(wire.OutPoint{Hash: *claimTx.Hash(), Index: 0})
+	tx = newTx(t, harness.funds[3], wire.MaxTxInSequenceNum, 1000000, supportScript(t, "b", pooled))
+	_, err = harness.pool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		t.Fatalf("support of claim in the pool: %v", err)
+	}
+}
+
+// TestClaimCount ensures the claims created by the transactions of the pool
+// are forgotten as the transactions are removed or replaced.
+func TestClaimCount(t *testing.T) {
+	t.Parallel()
+
+	harness := newPoolHarness(3)
+	pool := harness.pool
+
+	// A claim is counted once per transaction creating it, and forgotten
+	// with its removal.
+	tx := newTx(t, harness.funds[0], wire.MaxTxInSequenceNum, 1000000, claimScript(t, "a"))
+	_, err := pool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		t.Fatalf("claim: %v", err)
+	}
+	id := node.NewClaimID(wire.OutPoint{Hash: *tx.Hash(), Index: 0})
+	if count := pool.claims[id]; count != 1 {
+		t.Fatalf("claim %v counted %d times, want 1", id, count)
+	}
+
+	pool.RemoveTransaction(tx, true)
+	if _, ok := pool.claims[id]; ok {
+		t.Fatalf("claim %v still counted after its removal", id)
+	}
+	support := newTx(t, harness.funds[1], wire.MaxTxInSequenceNum, 1000000, supportScript(t, "a", id))
+	_, err = pool.ProcessTransaction(support, false, false, 0)
+	checkRuleError(t, err, blockchain.ErrUnknownClaimSupport, wire.RejectNonstandard)
+
+	// A claim is forgotten when its transaction is replaced by one which
+	// doesn't create it.
+	tx = newTx(t, harness.funds[2], MaxRBFSequence, 1000000, claimScript(t, "b"))
+	_, err = pool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		t.Fatalf("replaceable claim: %v", err)
+	}
+	id = node.NewClaimID(wire.OutPoint{Hash: *tx.Hash(), Index: 0})
+	if count := pool.claims[id]; count != 1 {
+		t.Fatalf("claim %v counted %d times, want 1", id, count)
+	}
+
+	replacement := newTx(t, harness.funds[2], MaxRBFSequence, 2000000, []byte{txscript.OP_TRUE})
+	_, err = pool.ProcessTransaction(replacement, false, false, 0)
+	if err != nil {
+		t.Fatalf("replacement: %v", err)
+	}
+	if pool.HaveTransaction(tx.Hash()) {
+		t.Fatalf("replaced transaction %v still in the pool", tx.Hash())
+	}
+	if _, ok := pool.claims[id]; ok {
+		t.Fatalf("claim %v still counted after its replacement", id)
+	}
+}
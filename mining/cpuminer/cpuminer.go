@@ -269,12 +269,12 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 				// Non-blocking select to fall through
 			}
 
-			// Update the nonce and hash the block header.  Each
-			// hash is actually a double sha256 (two hashes), so
-			// increment the number of hashes completed for each
-			// attempt accordingly.
+			// Update the nonce and compute the proof of work hash
+			// of the block header, which differs from the block
+			// hash.  Each attempt is counted as two hashes to keep
+			// the reported rate comparable with upstream.
 			header.Nonce = i
-			hash := header.BlockHash()
+			hash := header.BlockPoWHash()
 			hashesCompleted += 2
 
 			// The block is solved when the new block hash is less
@@ -843,6 +843,17 @@ mempoolLoop:
 	// chain with no issues.
 	block := btcutil.NewBlock(&msgBlock)
 	block.SetHeight(nextBlockHeight)
+
+	// Commit to the ClaimTrie root which results from applying the claim
+	// operations of the selected transactions.
+	if g.chain.ClaimTrie() != nil {
+		root, err := g.chain.ClaimTrieRootForBlock(block)
+		if err != nil {
+			return nil, err
+		}
+		msgBlock.Header.ClaimTrie = *root
+	}
+
 	if err := g.chain.CheckConnectBlockTemplate(block); err != nil {
 		return nil, err
 	}
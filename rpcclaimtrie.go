@@ -0,0 +1,1000 @@
+// Copyright (c) 2021 The LBRY developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie"
+	"github.com/btcsuite/btcd/claimtrie/change"
+	"github.com/btcsuite/btcd/claimtrie/metadata"
+	"github.com/btcsuite/btcd/claimtrie/node"
+	"github.com/btcsuite/btcd/claimtrie/param"
+	"github.com/btcsuite/btcd/claimtrie/takeover"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// ErrRPCNoClaimTrie is an error returned to RPC clients when a claim related
+// command is issued to a server running without a ClaimTrie.
+var ErrRPCNoClaimTrie = &btcjson.RPCError{
+	Code:    btcjson.ErrRPCMisc,
+	Message: "ClaimTrie is disabled",
+}
+
+// maxListNamesLimit is the maximum number of names returned by listnames.
+const maxListNamesLimit = 1000
+
+// maxClaimScheduleRange is the maximum number of heights covered by
+// getclaimsexpiring and getpendingactivations.
+const maxClaimScheduleRange = 50000
+
+// claimTrieView returns a view of the ClaimTrie at the best block, which the
+// caller must close, or an RPC error if the server is running without one.
+// The claims are queried from views, as blocks keep being connected meanwhile.
+func (s *rpcServer) claimTrieView() (*claimtrie.View, error) {
+	if s.cfg.Chain.ClaimTrie() == nil {
+		return nil, ErrRPCNoClaimTrie
+	}
+
+	view, err := s.cfg.Chain.ClaimTrieView()
+	if err != nil {
+		context := "Failed to create ClaimTrie view"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return view, nil
+}
+
+// claimTrieBlock returns the hash and the height of the block specified by
+// either its hash or its height, or those of the best block of the view if
+// neither is.  The blocks connected after the view was created are out of range.
+func (s *rpcServer) claimTrieBlock(ct *claimtrie.View, blockHash *string, height *int32) (*chainhash.Hash, int32, error) {
+	if blockHash != nil && height != nil {
+		return nil, 0, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Only one of blockhash and height can be specified",
+		}
+	}
+
+	best := ct.Height()
+	switch {
+	case blockHash != nil:
+		hash, err := chainhash.NewHashFromStr(*blockHash)
+		if err != nil {
+			return nil, 0, rpcDecodeHexError(*blockHash)
+		}
+		h, err := s.cfg.Chain.BlockHeightByHash(hash)
+		if err != nil {
+			return nil, 0, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCBlockNotFound,
+				Message: "Block not found in the main chain",
+			}
+		}
+		if h > best {
+			return nil, 0, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCOutOfRange,
+				Message: fmt.Sprintf("Block height %d out of range", h),
+			}
+		}
+		return hash, h, nil
+
+	case height != nil:
+		if *height < 0 || *height > best {
+			return nil, 0, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCOutOfRange,
+				Message: fmt.Sprintf("Block height %d out of range", *height),
+			}
+		}
+		hash, err := s.cfg.Chain.BlockHashByHeight(*height)
+		if err != nil {
+			context := "Failed to get block hash"
+			return nil, 0, internalRPCError(err.Error(), context)
+		}
+		return hash, *height, nil
+	}
+
+	hash, err := s.cfg.Chain.BlockHashByHeight(best)
+	if err != nil {
+		context := "Failed to get block hash"
+		return nil, 0, internalRPCError(err.Error(), context)
+	}
+	return hash, best, nil
+}
+
+// claimTrieNodeError converts an error loading a node at the height to an RPC
+// error, telling apart the history removed by pruning.
+func claimTrieNodeError(err error, height int32, context string) error {
+	if errors.Is(err, node.ErrPruned) {
+		return &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: fmt.Sprintf("The claim history at height %d "+
+				"is pruned (see --clmtprunedepth)", height),
+		}
+	}
+	return internalRPCError(err.Error(), context)
+}
+
+// handleGetClaimsForName implements the getclaimsforname command.
+func handleGetClaimsForName(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimsForNameCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	_, height, err := s.claimTrieBlock(ct, c.BlockHash, c.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	name := node.NormalizeIfNecessary([]byte(c.Name), height)
+	n, err := ct.NodeAt(height, name)
+	if err != nil {
+		context := "Failed to load claimtrie node"
+		return nil, claimTrieNodeError(err, height, context)
+	}
+
+	result := &btcjson.GetClaimsForNameResult{
+		Name:                 c.Name,
+		NormalizedName:       string(name),
+		Height:               height,
+		Claims:               []btcjson.ClaimResult{},
+		SupportsWithoutClaim: []btcjson.SupportResult{},
+	}
+	if n == nil {
+		return result, nil
+	}
+
+	result.LastTakeoverHeight = n.TakenOverAt
+	if n.BestClaim != nil {
+		result.BestClaimID = n.BestClaim.ClaimID
+	}
+
+	claimed := make(map[string]bool, len(n.Claims))
+	n.SortClaims()
+	for _, claim := range n.Claims {
+		claimed[claim.ClaimID] = true
+		claimResult := toClaimResult(claim, n)
+		err = setSignatureValid(ct, &claimResult, claim, height)
+		if err != nil {
+			context := "Failed to verify claim signature"
+			return nil, claimTrieNodeError(err, height, context)
+		}
+		result.Claims = append(result.Claims, claimResult)
+	}
+	for _, support := range n.Supports {
+		if !claimed[support.ClaimID] {
+			result.SupportsWithoutClaim = append(result.SupportsWithoutClaim,
+				toSupportResult(support))
+		}
+	}
+
+	return result, nil
+}
+
+// claimChanges returns the changes made to the claim with the hex encoded ID,
+// as recorded by the claim ID index of the view.
+func claimChanges(ct *claimtrie.View, claimID string) ([]change.Change, error) {
+	if len(claimID) != 2*len(node.ClaimID{}) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Claim ID must be 40 hex characters",
+		}
+	}
+	id, err := node.NewIDFromString(claimID)
+	if err != nil {
+		return nil, rpcDecodeHexError(claimID)
+	}
+
+	changes, err := ct.ClaimChanges(id)
+	if err == claimtrie.ErrClaimIDIndexDisabled {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "The claim ID index must be enabled " +
+				"(specify --claimidindex)",
+		}
+	}
+	if err != nil {
+		context := "Failed to load claim changes"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if len(changes) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "No information available about claim " + claimID,
+		}
+	}
+
+	return changes, nil
+}
+
+// handleGetClaimByID implements the getclaimbyid command.
+func handleGetClaimByID(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimByIDCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	changes, err := claimChanges(ct, c.ClaimID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, height, err := s.claimTrieBlock(ct, c.BlockHash, c.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the changes made up to the requested height.
+	i := len(changes)
+	for i > 0 && changes[i-1].Height > height {
+		i--
+	}
+	if i == 0 {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidAddressOrKey,
+			Message: fmt.Sprintf("No information available about "+
+				"claim %s at height %d", c.ClaimID, height),
+		}
+	}
+
+	last := changes[i-1]
+	result := &btcjson.GetClaimByIDResult{
+		ClaimID:          last.ClaimID,
+		Name:             string(last.Name),
+		LastChangeHeight: last.Height,
+		Spent:            last.Type == change.SpendClaim,
+	}
+	if op := node.NewOutPointFromString(last.OutPoint); op != nil {
+		result.TxID = op.Hash.String()
+		result.N = op.Index
+	}
+
+	name := node.NormalizeIfNecessary(last.Name, height)
+	result.NormalizedName = string(name)
+	if result.Spent {
+		return result, nil
+	}
+
+	n, err := ct.NodeAt(height, name)
+	if err != nil {
+		context := "Failed to load claimtrie node"
+		return nil, claimTrieNodeError(err, height, context)
+	}
+	if n == nil {
+		return result, nil
+	}
+	for _, claim := range n.Claims {
+		if claim.ClaimID == last.ClaimID {
+			claimResult := toClaimResult(claim, n)
+			err = setSignatureValid(ct, &claimResult, claim, height)
+			if err != nil {
+				context := "Failed to verify claim signature"
+				return nil, claimTrieNodeError(err, height, context)
+			}
+			result.Claim = &claimResult
+			result.IsBest = n.BestClaim == claim
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// handleGetClaimsInChannel implements the getclaimsinchannel command.
+func handleGetClaimsInChannel(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimsInChannelCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	if len(c.ChannelID) != 2*len(node.ClaimID{}) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Channel ID must be 40 hex characters",
+		}
+	}
+	id, err := node.NewIDFromString(c.ChannelID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.ChannelID)
+	}
+
+	_, height, err := s.claimTrieBlock(ct, c.BlockHash, c.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := ct.ClaimsInChannel(id, height)
+	if err == claimtrie.ErrChannelIndexDisabled {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "The channel index must be enabled " +
+				"(specify --channelindex)",
+		}
+	}
+	if err != nil {
+		context := "Failed to load channel claims"
+		return nil, claimTrieNodeError(err, height, context)
+	}
+
+	result := &btcjson.GetClaimsInChannelResult{
+		ChannelID: c.ChannelID,
+		Height:    height,
+		Claims:    make([]btcjson.ChannelClaimResult, 0, len(claims)),
+	}
+	for _, cc := range claims {
+		claimResult := toClaimResult(cc.Claim, cc.Node)
+		valid := cc.SignatureValid
+		claimResult.SignatureValid = &valid
+		result.Claims = append(result.Claims, btcjson.ChannelClaimResult{
+			Name:  string(cc.Name),
+			Claim: claimResult,
+		})
+	}
+
+	return result, nil
+}
+
+// setSignatureValid reports in the result whether the claim is validly signed
+// by its channel at the height, if it is signed and the channel index enabled.
+func setSignatureValid(ct *claimtrie.View, result *btcjson.ClaimResult, c *node.Claim, height int32) error {
+	if result.SigningChannelID == "" {
+		return nil
+	}
+
+	valid, err := ct.ClaimSignatureValid(c, height)
+	if err == claimtrie.ErrChannelIndexDisabled {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	result.SignatureValid = &valid
+
+	return nil
+}
+
+// handleGetClaimHistory implements the getclaimhistory command.
+func handleGetClaimHistory(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimHistoryCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	changes, err := claimChanges(ct, c.ClaimID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]btcjson.ClaimHistoryResult, 0, len(changes))
+	for _, chg := range changes {
+		entry := btcjson.ClaimHistoryResult{
+			Height: chg.Height,
+			Name:   string(chg.Name),
+			Amount: chg.Amount,
+			Value:  hex.EncodeToString(chg.Value),
+		}
+		switch chg.Type {
+		case change.AddClaim:
+			entry.Operation = "claim"
+		case change.UpdateClaim:
+			entry.Operation = "update"
+		case change.SpendClaim:
+			entry.Operation = "spend"
+		}
+		if op := node.NewOutPointFromString(chg.OutPoint); op != nil {
+			entry.TxID = op.Hash.String()
+			entry.N = op.Index
+		}
+		history = append(history, entry)
+	}
+
+	return history, nil
+}
+
+// handleGetClaimAnomalies implements the getclaimanomalies command.
+func handleGetClaimAnomalies(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimAnomaliesCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	fromHeight, toHeight := int32(0), ct.Height()
+	if c.FromHeight != nil {
+		fromHeight = *c.FromHeight
+	}
+	if c.ToHeight != nil {
+		toHeight = *c.ToHeight
+	}
+	if fromHeight < 0 || toHeight < fromHeight {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Invalid height range",
+		}
+	}
+
+	anomalies, err := ct.Anomalies(fromHeight, toHeight)
+	if err != nil {
+		context := "Failed to load claim anomalies"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	results := make([]btcjson.ClaimAnomalyResult, 0, len(anomalies))
+	for _, a := range anomalies {
+		result := btcjson.ClaimAnomalyResult{
+			Type:    a.Type.String(),
+			Height:  a.Height,
+			Name:    string(a.Name),
+			ClaimID: a.ClaimID,
+		}
+		if op := node.NewOutPointFromString(a.OutPoint); op != nil {
+			result.TxID = op.Hash.String()
+			result.N = op.Index
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// handleGetNameHistory implements the getnamehistory command.
+func handleGetNameHistory(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNameHistoryCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	takeovers, err := ct.NameHistory([]byte(c.Name))
+	if err != nil {
+		context := "Failed to load name history"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	results := make([]btcjson.NameTakeoverResult, 0, len(takeovers))
+	for _, t := range takeovers {
+		results = append(results, toNameTakeoverResult(t))
+	}
+
+	return results, nil
+}
+
+// toNameTakeoverResult converts a takeover of the ClaimTrie to its RPC result.
+func toNameTakeoverResult(t takeover.Takeover) btcjson.NameTakeoverResult {
+	return btcjson.NameTakeoverResult{
+		Height:             t.Height,
+		Name:               string(t.Name),
+		OldClaimID:         t.OldClaimID,
+		NewClaimID:         t.NewClaimID,
+		OldEffectiveAmount: t.OldAmount,
+		NewEffectiveAmount: t.NewAmount,
+	}
+}
+
+// handleGetClaimsExpiring implements the getclaimsexpiring command.
+func handleGetClaimsExpiring(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetClaimsExpiringCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	return claimScheduleResult(ct, c.FromHeight, c.ToHeight, ct.ClaimsExpiring)
+}
+
+// handleGetPendingActivations implements the getpendingactivations command.
+func handleGetPendingActivations(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetPendingActivationsCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	// Claims and supports activate at most MaxActiveDelay blocks after
+	// they are accepted.
+	fromHeight, toHeight := ct.Height()+1, ct.Height()+param.MaxActiveDelay
+	if c.FromHeight != nil {
+		fromHeight = *c.FromHeight
+	}
+	if c.ToHeight != nil {
+		toHeight = *c.ToHeight
+	}
+
+	return claimScheduleResult(ct, fromHeight, toHeight, ct.PendingActivations)
+}
+
+// claimScheduleResult returns the claims and supports listed by schedule for
+// the range of heights.
+func claimScheduleResult(ct *claimtrie.View, fromHeight, toHeight int32,
+	schedule func(from, to int32) ([]claimtrie.ScheduledClaim, error)) (*btcjson.ClaimScheduleResult, error) {
+
+	if fromHeight < 0 || toHeight < fromHeight {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Invalid height range",
+		}
+	}
+	if toHeight-fromHeight >= maxClaimScheduleRange {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Height range must span at most %d heights", maxClaimScheduleRange),
+		}
+	}
+
+	height := ct.Height()
+	claims, err := schedule(fromHeight, toHeight)
+	if err != nil {
+		context := "Failed to load scheduled claims"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	result := &btcjson.ClaimScheduleResult{
+		Height:     height,
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+		Claims:     make([]btcjson.ScheduledClaimResult, 0, len(claims)),
+	}
+	for _, sc := range claims {
+		result.Claims = append(result.Claims, btcjson.ScheduledClaimResult{
+			Name:    string(sc.Name),
+			Support: sc.Support,
+			Claim:   toSupportResult(sc.Claim),
+		})
+	}
+
+	return result, nil
+}
+
+// handleGetNameProof implements the getnameproof command.
+func handleGetNameProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNameProofCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	hash, height, err := s.claimTrieBlock(ct, c.BlockHash, c.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := s.cfg.Chain.HeaderByHash(hash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	proof, n, err := ct.NameProof([]byte(c.Name), height)
+	if err == claimtrie.ErrNoViewProof {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "Name proofs require the Merkle Trie to be " +
+				"persisted (don't specify --clmtram)",
+		}
+	}
+	if err != nil {
+		context := "Failed to generate name proof"
+		return nil, claimTrieNodeError(err, height, context)
+	}
+
+	result := &btcjson.GetNameProofResult{
+		Name:              c.Name,
+		NormalizedName:    string(proof.Name),
+		BlockHash:         hash.String(),
+		Height:            height,
+		ClaimTrie:         header.ClaimTrie.String(),
+		AllClaimsInMerkle: proof.AllClaims,
+		Nodes:             make([]btcjson.NameProofNode, 0, len(proof.Nodes)),
+	}
+
+	for _, pn := range proof.Nodes {
+		vertex := btcjson.NameProofNode{
+			Children: make([]btcjson.NameProofChild, 0, len(pn.Children)),
+		}
+		for _, child := range pn.Children {
+			vertex.Children = append(vertex.Children, btcjson.NameProofChild{
+				Char: child.Char,
+				Hash: child.Hash.String(),
+			})
+		}
+		if pn.ValueHash != nil {
+			vertex.ValueHash = pn.ValueHash.String()
+		}
+		result.Nodes = append(result.Nodes, vertex)
+	}
+
+	for _, pair := range proof.Pairs {
+		result.Pairs = append(result.Pairs, btcjson.NameProofPair{
+			Right: pair.Right,
+			Hash:  pair.Hash.String(),
+		})
+	}
+
+	if n != nil && n.BestClaim != nil && n.BestClaim.Status == node.Activated {
+		result.BestClaim = &btcjson.NameProofClaimResult{
+			ClaimID:            n.BestClaim.ClaimID,
+			TxID:               n.BestClaim.OutPoint.Hash.String(),
+			N:                  n.BestClaim.OutPoint.Index,
+			LastTakeoverHeight: n.TakenOverAt,
+		}
+	}
+
+	return result, nil
+}
+
+// handleListNames implements the listnames command.
+func handleListNames(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ListNamesCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	limit := 100
+	if c.Limit != nil {
+		limit = *c.Limit
+	}
+	if limit <= 0 || limit > maxListNamesLimit {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Limit must be between 1 and %d", maxListNamesLimit),
+		}
+	}
+
+	var cursor []byte
+	if c.Cursor != nil && *c.Cursor != "" {
+		cursor = []byte(*c.Cursor)
+	}
+
+	height := ct.Height()
+	prefix := node.NormalizeIfNecessary([]byte(c.Prefix), height)
+	names, next, err := ct.ListNames(prefix, cursor, limit)
+	if err != nil {
+		context := "Failed to list names"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	result := &btcjson.ListNamesResult{
+		Height:     height,
+		Names:      make([]btcjson.NameResult, 0, len(names)),
+		NextCursor: string(next),
+	}
+	for _, ln := range names {
+		best := ln.Node.BestClaim
+		result.Names = append(result.Names, btcjson.NameResult{
+			Name:               string(ln.Name),
+			BestClaimID:        best.ClaimID,
+			EffectiveAmount:    best.EffectiveAmount(ln.Node.Supports),
+			LastTakeoverHeight: ln.Node.TakenOverAt,
+		})
+	}
+
+	return result, nil
+}
+
+// handleSimulateTakeover implements the simulatetakeover command.
+func handleSimulateTakeover(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SimulateTakeoverCmd)
+
+	ct, err := s.claimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+
+	if c.Amount <= 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Amount must be positive",
+		}
+	}
+
+	var id *node.ClaimID
+	if c.ClaimID != nil {
+		if len(*c.ClaimID) != 2*len(node.ClaimID{}) {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Claim ID must be 40 hex characters",
+			}
+		}
+		claimID, err := node.NewIDFromString(*c.ClaimID)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.ClaimID)
+		}
+		id = &claimID
+	}
+
+	height := ct.Height()
+	sim, err := ct.SimulateTakeover([]byte(c.Name), c.Amount, id)
+	if errors.Is(err, node.ErrClaimNotFound) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The name has no claim " + *c.ClaimID,
+		}
+	}
+	if err != nil {
+		context := "Failed to simulate takeover"
+		return nil, claimTrieNodeError(err, height, context)
+	}
+
+	result := &btcjson.SimulateTakeoverResult{
+		Name:             c.Name,
+		NormalizedName:   string(node.NormalizeIfNecessary([]byte(c.Name), height+1)),
+		Height:           height,
+		ClaimID:          sim.ClaimID,
+		ActivationHeight: sim.ActiveAt,
+		TakeoverHeight:   sim.BestAt,
+		Steps:            make([]btcjson.SimulationStepResult, 0, len(sim.Steps)),
+	}
+	for _, step := range sim.Steps {
+		result.Steps = append(result.Steps, btcjson.SimulationStepResult{
+			Height:          step.Height,
+			BestClaimID:     step.BestClaimID,
+			BestAmount:      step.BestAmount,
+			EffectiveAmount: step.EffectiveAmount,
+		})
+	}
+
+	return result, nil
+}
+
+// handleDumpClaimTrie implements the dumpclaimtrie command.
+func handleDumpClaimTrie(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DumpClaimTrieCmd)
+
+	if s.cfg.Chain.ClaimTrie() == nil {
+		return nil, ErrRPCNoClaimTrie
+	}
+
+	filename := c.Filename
+	if !filepath.IsAbs(filename) {
+		filename = filepath.Join(cfg.DataDir, filename)
+	}
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "File " + filename + " already exists",
+		}
+	}
+
+	// Write to a temporary file, so an interrupted dump is never mistaken
+	// for a snapshot.
+	incomplete := filename + ".incomplete"
+	f, err := os.Create(incomplete)
+	if err != nil {
+		context := "Failed to create snapshot"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	hdr, hash, err := s.cfg.Chain.ExportClaimTrie(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(incomplete, filename)
+	}
+	if err != nil {
+		os.Remove(incomplete)
+		context := "Failed to write snapshot"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return &btcjson.DumpClaimTrieResult{
+		Filename:  filename,
+		Height:    hdr.Height,
+		BlockHash: hash.String(),
+		ClaimTrie: hdr.Root.String(),
+	}, nil
+}
+
+// toClaimResult converts a claim of the node n, along with its supports, to
+// the RPC representation.
+func toClaimResult(c *node.Claim, n *node.Node) btcjson.ClaimResult {
+	supports := []btcjson.SupportResult{}
+	for _, s := range n.Supports {
+		if s.ClaimID == c.ClaimID {
+			supports = append(supports, toSupportResult(s))
+		}
+	}
+
+	result := btcjson.ClaimResult{
+		ClaimID:          c.ClaimID,
+		TxID:             c.OutPoint.Hash.String(),
+		N:                c.OutPoint.Index,
+		Amount:           c.Amount,
+		EffectiveAmount:  c.EffectiveAmount(n.Supports),
+		AcceptedHeight:   c.AcceptedAt,
+		ActiveHeight:     c.ActiveAt,
+		ExpirationHeight: c.ExpireAt(),
+		Status:           c.Status.String(),
+		Value:            hex.EncodeToString(c.Value),
+		Supports:         supports,
+	}
+
+	// The values which can't be decoded are only reported raw.
+	if v, err := metadata.Decode(c.Value); err == nil {
+		if v.Signed {
+			result.SigningChannelID = v.SigningChannelID.String()
+			result.Signature = hex.EncodeToString(v.Signature)
+		}
+		result.Meta = toClaimMetaResult(v.Meta)
+	}
+
+	return result
+}
+
+// toClaimMetaResult converts the metadata of a claim to the RPC representation.
+func toClaimMetaResult(meta *metadata.Metadata) *btcjson.ClaimMetaResult {
+	result := &btcjson.ClaimMetaResult{
+		Type:         meta.Type.String(),
+		Legacy:       meta.Legacy,
+		Title:        meta.Title,
+		Description:  meta.Description,
+		ThumbnailURL: meta.ThumbnailURL,
+		Tags:         meta.Tags,
+		Author:       meta.Author,
+		License:      meta.License,
+		LicenseURL:   meta.LicenseURL,
+		ReleaseTime:  meta.ReleaseTime,
+		StreamType:   meta.StreamType,
+		PublicKey:    hex.EncodeToString(meta.PublicKey),
+		Email:        meta.Email,
+		WebsiteURL:   meta.WebsiteURL,
+		CoverURL:     meta.CoverURL,
+	}
+	if s := meta.Source; s != nil {
+		result.SourceName = s.Name
+		result.SourceSize = s.Size
+		result.MediaType = s.MediaType
+		result.SourceHash = hex.EncodeToString(s.Hash)
+		result.SDHash = hex.EncodeToString(s.SDHash)
+	}
+	if fee := meta.Fee; fee != nil {
+		result.FeeCurrency = fee.Currency
+		result.FeeAmount = fee.Amount
+		result.FeeAddress = base58.Encode(fee.Address)
+	}
+	for _, id := range meta.ClaimIDs {
+		result.ClaimIDs = append(result.ClaimIDs, id.String())
+	}
+	return result
+}
+
+// toSupportResult converts a support to the RPC representation.
+func toSupportResult(s *node.Claim) btcjson.SupportResult {
+	return btcjson.SupportResult{
+		TxID:             s.OutPoint.Hash.String(),
+		N:                s.OutPoint.Index,
+		ClaimID:          s.ClaimID,
+		Amount:           s.Amount,
+		AcceptedHeight:   s.AcceptedAt,
+		ActiveHeight:     s.ActiveAt,
+		ExpirationHeight: s.ExpireAt(),
+		Status:           s.Status.String(),
+		Value:            hex.EncodeToString(s.Value),
+	}
+}
+
+// claimScriptResult returns the claim, support or update made by the public key
+// script of the output at the outpoint, with its name normalized at the height,
+// or nil if the script isn't a claim script.
+func claimScriptResult(pkScript []byte, op wire.OutPoint, height int32) *btcjson.ClaimScriptResult {
+	cs, err := txscript.DecodeClaimScript(pkScript)
+	if err != nil {
+		return nil
+	}
+
+	result := &btcjson.ClaimScriptResult{
+		Name:           string(cs.Name()),
+		NormalizedName: string(node.NormalizeIfNecessary(cs.Name(), height)),
+		Value:          hex.EncodeToString(cs.Value()),
+	}
+
+	var id node.ClaimID
+	switch cs.Opcode() {
+	case txscript.OP_CLAIMNAME:
+		result.Operation = txscript.ClaimNameTy.String()
+		id = node.NewClaimID(op)
+	case txscript.OP_SUPPORTCLAIM:
+		result.Operation = txscript.SupportClaimTy.String()
+		copy(id[:], cs.ClaimID())
+	default:
+		result.Operation = txscript.UpdateClaimTy.String()
+		copy(id[:], cs.ClaimID())
+	}
+	result.ClaimID = id.String()
+
+	return result
+}
+
+// setSpentClaims sets the claims, supports and updates spent by the inputs of
+// the transaction, given the public key scripts of the outputs they spend.  The
+// inputs whose outputs aren't given are left as is.
+func setSpentClaims(vinList []btcjson.Vin, mtx *wire.MsgTx, pkScripts map[wire.OutPoint][]byte, height int32) {
+	if blockchain.IsCoinBaseTx(mtx) {
+		return
+	}
+	for i, txIn := range mtx.TxIn {
+		if pkScript, ok := pkScripts[txIn.PreviousOutPoint]; ok {
+			vinList[i].Claim = claimScriptResult(pkScript,
+				txIn.PreviousOutPoint, height)
+		}
+	}
+}
+
+// fetchSpentPkScripts returns the public key scripts of the outputs spent by the
+// transaction, which are looked up in the memory pool, the utxo set and, when
+// it's enabled, the transaction index.  The outputs which can't be found are
+// omitted.
+func fetchSpentPkScripts(s *rpcServer, mtx *wire.MsgTx) map[wire.OutPoint][]byte {
+	pkScripts := make(map[wire.OutPoint][]byte)
+	if blockchain.IsCoinBaseTx(mtx) {
+		return pkScripts
+	}
+
+	for _, txIn := range mtx.TxIn {
+		op := txIn.PreviousOutPoint
+		if tx, err := s.cfg.TxMemPool.FetchTransaction(&op.Hash); err == nil {
+			if txOuts := tx.MsgTx().TxOut; op.Index < uint32(len(txOuts)) {
+				pkScripts[op] = txOuts[op.Index].PkScript
+			}
+			continue
+		}
+
+		entry, err := s.cfg.Chain.FetchUtxoEntry(op)
+		if err == nil && entry != nil && !entry.IsSpent() {
+			pkScripts[op] = entry.PkScript()
+			continue
+		}
+
+		// The outputs spent by confirmed transactions are only found in
+		// the transaction index.
+		if s.cfg.TxIndex == nil {
+			continue
+		}
+		txOuts, err := fetchInputTxos(s, &wire.MsgTx{TxIn: []*wire.TxIn{txIn}})
+		if err == nil {
+			pkScripts[op] = txOuts[op].PkScript
+		}
+	}
+
+	return pkScripts
+}
@@ -0,0 +1,504 @@
+// Copyright (c) 2021 The LBRY developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package rpcclient
+
+import (
+	"encoding/json"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+// FutureGetClaimByIDResult is a future promise to deliver the result of a
+// GetClaimByIDAsync RPC invocation (or an applicable error).
+type FutureGetClaimByIDResult chan *response
+
+// Receive waits for the response promised by the future and returns the latest
+// state of the requested claim.
+func (r FutureGetClaimByIDResult) Receive() (*btcjson.GetClaimByIDResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getclaimbyid result object.
+	var result btcjson.GetClaimByIDResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetClaimByIDAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetClaimByID for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimByIDAsync(claimID string, blockHash *chainhash.Hash, height *int32) FutureGetClaimByIDResult {
+	cmd := btcjson.NewGetClaimByIDCmd(claimID, hashString(blockHash), height)
+	return c.sendCmd(cmd)
+}
+
+// GetClaimByID returns the state of the claim with the hex encoded ID as of
+// the block specified by either its hash or its height, or as of the best
+// block if both are nil.  The server must be running with the claim ID index
+// enabled.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimByID(claimID string, blockHash *chainhash.Hash, height *int32) (*btcjson.GetClaimByIDResult, error) {
+	return c.GetClaimByIDAsync(claimID, blockHash, height).Receive()
+}
+
+// FutureGetClaimAnomaliesResult is a future promise to deliver the result of a
+// GetClaimAnomaliesAsync RPC invocation (or an applicable error).
+type FutureGetClaimAnomaliesResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// anomalies found in the requested range of heights.
+func (r FutureGetClaimAnomaliesResult) Receive() ([]btcjson.ClaimAnomalyResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of claim anomaly result objects.
+	var result []btcjson.ClaimAnomalyResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetClaimAnomaliesAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetClaimAnomalies for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimAnomaliesAsync(fromHeight, toHeight *int32) FutureGetClaimAnomaliesResult {
+	cmd := btcjson.NewGetClaimAnomaliesCmd(fromHeight, toHeight)
+	return c.sendCmd(cmd)
+}
+
+// GetClaimAnomalies returns the anomalies found while applying the claim
+// operations of the blocks from fromHeight to toHeight, ordered by height.
+// Nil heights select the whole chain.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimAnomalies(fromHeight, toHeight *int32) ([]btcjson.ClaimAnomalyResult, error) {
+	return c.GetClaimAnomaliesAsync(fromHeight, toHeight).Receive()
+}
+
+// FutureGetClaimHistoryResult is a future promise to deliver the result of a
+// GetClaimHistoryAsync RPC invocation (or an applicable error).
+type FutureGetClaimHistoryResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// changes made to the requested claim.
+func (r FutureGetClaimHistoryResult) Receive() ([]btcjson.ClaimHistoryResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of claim history result objects.
+	var result []btcjson.ClaimHistoryResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetClaimHistoryAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetClaimHistory for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimHistoryAsync(claimID string) FutureGetClaimHistoryResult {
+	cmd := btcjson.NewGetClaimHistoryCmd(claimID)
+	return c.sendCmd(cmd)
+}
+
+// GetClaimHistory returns the changes made to the claim with the hex encoded
+// ID, ordered by height.  The server must be running with the claim ID index
+// enabled.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimHistory(claimID string) ([]btcjson.ClaimHistoryResult, error) {
+	return c.GetClaimHistoryAsync(claimID).Receive()
+}
+
+// FutureGetNameHistoryResult is a future promise to deliver the result of a
+// GetNameHistoryAsync RPC invocation (or an applicable error).
+type FutureGetNameHistoryResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// takeovers of the requested name.
+func (r FutureGetNameHistoryResult) Receive() ([]btcjson.NameTakeoverResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as an array of name takeover result objects.
+	var result []btcjson.NameTakeoverResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetNameHistoryAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetNameHistory for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetNameHistoryAsync(name string) FutureGetNameHistoryResult {
+	cmd := btcjson.NewGetNameHistoryCmd(name)
+	return c.sendCmd(cmd)
+}
+
+// GetNameHistory returns the takeovers of the name, which are the changes of
+// the claim controlling it, ordered by height.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetNameHistory(name string) ([]btcjson.NameTakeoverResult, error) {
+	return c.GetNameHistoryAsync(name).Receive()
+}
+
+// FutureGetClaimsForNameResult is a future promise to deliver the result of a
+// GetClaimsForNameAsync RPC invocation (or an applicable error).
+type FutureGetClaimsForNameResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// claims and supports of the requested name.
+func (r FutureGetClaimsForNameResult) Receive() (*btcjson.GetClaimsForNameResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getclaimsforname result object.
+	var result btcjson.GetClaimsForNameResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetClaimsForNameAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See GetClaimsForName for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimsForNameAsync(name string, blockHash *chainhash.Hash, height *int32) FutureGetClaimsForNameResult {
+	cmd := btcjson.NewGetClaimsForNameCmd(name, hashString(blockHash), height)
+	return c.sendCmd(cmd)
+}
+
+// GetClaimsForName returns all claims and supports of the name, along with
+// its best claim and last takeover height, as of the block specified by either
+// its hash or its height, or as of the best block if both are nil.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimsForName(name string, blockHash *chainhash.Hash, height *int32) (*btcjson.GetClaimsForNameResult, error) {
+	return c.GetClaimsForNameAsync(name, blockHash, height).Receive()
+}
+
+// FutureGetClaimsInChannelResult is a future promise to deliver the result of
+// a GetClaimsInChannelAsync RPC invocation (or an applicable error).
+type FutureGetClaimsInChannelResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// claims signed by the requested channel.
+func (r FutureGetClaimsInChannelResult) Receive() (*btcjson.GetClaimsInChannelResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getclaimsinchannel result object.
+	var result btcjson.GetClaimsInChannelResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetClaimsInChannelAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetClaimsInChannel for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimsInChannelAsync(channelID string, blockHash *chainhash.Hash, height *int32) FutureGetClaimsInChannelResult {
+	cmd := btcjson.NewGetClaimsInChannelCmd(channelID, hashString(blockHash), height)
+	return c.sendCmd(cmd)
+}
+
+// GetClaimsInChannel returns the claims signed by the channel with the hex
+// encoded claim ID, along with the validity of their signatures, as of the
+// block specified by either its hash or its height, or as of the best block if
+// both are nil.  The server must be running with the channel index enabled.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimsInChannel(channelID string, blockHash *chainhash.Hash, height *int32) (*btcjson.GetClaimsInChannelResult, error) {
+	return c.GetClaimsInChannelAsync(channelID, blockHash, height).Receive()
+}
+
+// FutureGetNameProofResult is a future promise to deliver the result of a
+// GetNameProofAsync RPC invocation (or an applicable error).
+type FutureGetNameProofResult chan *response
+
+// Receive waits for the response promised by the future and returns the proof
+// of the requested name.
+func (r FutureGetNameProofResult) Receive() (*btcjson.GetNameProofResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a getnameproof result object.
+	var result btcjson.GetNameProofResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetNameProofAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See GetNameProof for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetNameProofAsync(name string, blockHash *chainhash.Hash, height *int32) FutureGetNameProofResult {
+	cmd := btcjson.NewGetNameProofCmd(name, hashString(blockHash), height)
+	return c.sendCmd(cmd)
+}
+
+// GetNameProof returns a proof of the best claim of the name, or of its
+// absence, against the ClaimTrie hash of the block specified by either its hash
+// or its height.  The best block is used if both are nil.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetNameProof(name string, blockHash *chainhash.Hash, height *int32) (*btcjson.GetNameProofResult, error) {
+	return c.GetNameProofAsync(name, blockHash, height).Receive()
+}
+
+// hashString returns the string form of the optional block hash.
+func hashString(blockHash *chainhash.Hash) *string {
+	if blockHash == nil {
+		return nil
+	}
+	return btcjson.String(blockHash.String())
+}
+
+// FutureDumpClaimTrieResult is a future promise to deliver the result of a
+// DumpClaimTrieAsync RPC invocation (or an applicable error).
+type FutureDumpClaimTrieResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// description of the written snapshot.
+func (r FutureDumpClaimTrieResult) Receive() (*btcjson.DumpClaimTrieResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a dumpclaimtrie result object.
+	var result btcjson.DumpClaimTrieResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DumpClaimTrieAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on the
+// returned instance.
+//
+// See DumpClaimTrie for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) DumpClaimTrieAsync(filename string) FutureDumpClaimTrieResult {
+	cmd := btcjson.NewDumpClaimTrieCmd(filename)
+	return c.sendCmd(cmd)
+}
+
+// DumpClaimTrie writes a snapshot of the ClaimTrie at the best block to a file
+// on the server.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) DumpClaimTrie(filename string) (*btcjson.DumpClaimTrieResult, error) {
+	return c.DumpClaimTrieAsync(filename).Receive()
+}
+
+// FutureListNamesResult is a future promise to deliver the result of a
+// ListNamesAsync RPC invocation (or an applicable error).
+type FutureListNamesResult chan *response
+
+// Receive waits for the response promised by the future and returns a page of
+// the names starting with the requested prefix.
+func (r FutureListNamesResult) Receive() (*btcjson.ListNamesResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a listnames result object.
+	var result btcjson.ListNamesResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListNamesAsync returns an instance of a type that can be used to get the
+// result of the RPC at some future time by invoking the Receive function on
+// the returned instance.
+//
+// See ListNames for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) ListNamesAsync(prefix string, limit *int, cursor *string) FutureListNamesResult {
+	cmd := btcjson.NewListNamesCmd(prefix, limit, cursor)
+	return c.sendCmd(cmd)
+}
+
+// ListNames returns up to limit names starting with the prefix which have a
+// best claim, along with that claim, in byte order.  The listing resumes after
+// the cursor, which is the NextCursor of the previous page, if not nil.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) ListNames(prefix string, limit *int, cursor *string) (*btcjson.ListNamesResult, error) {
+	return c.ListNamesAsync(prefix, limit, cursor).Receive()
+}
+
+// FutureClaimScheduleResult is a future promise to deliver the result of a
+// GetClaimsExpiringAsync or GetPendingActivationsAsync RPC invocation (or an
+// applicable error).
+type FutureClaimScheduleResult chan *response
+
+// Receive waits for the response promised by the future and returns the claims
+// and supports scheduled in the requested range of heights.
+func (r FutureClaimScheduleResult) Receive() (*btcjson.ClaimScheduleResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a claim schedule result object.
+	var result btcjson.ClaimScheduleResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetClaimsExpiringAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See GetClaimsExpiring for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimsExpiringAsync(fromHeight, toHeight int32) FutureClaimScheduleResult {
+	cmd := btcjson.NewGetClaimsExpiringCmd(fromHeight, toHeight)
+	return c.sendCmd(cmd)
+}
+
+// GetClaimsExpiring returns the claims and supports which expire from
+// fromHeight to toHeight, inclusively, ordered by expiration height.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetClaimsExpiring(fromHeight, toHeight int32) (*btcjson.ClaimScheduleResult, error) {
+	return c.GetClaimsExpiringAsync(fromHeight, toHeight).Receive()
+}
+
+// GetPendingActivationsAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See GetPendingActivations for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetPendingActivationsAsync(fromHeight, toHeight *int32) FutureClaimScheduleResult {
+	cmd := btcjson.NewGetPendingActivationsCmd(fromHeight, toHeight)
+	return c.sendCmd(cmd)
+}
+
+// GetPendingActivations returns the claims and supports which are accepted but
+// not active yet, and activate from fromHeight to toHeight, inclusively,
+// ordered by activation height.  The range defaults to the heights up to the
+// maximum activation delay after the best block.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) GetPendingActivations(fromHeight, toHeight *int32) (*btcjson.ClaimScheduleResult, error) {
+	return c.GetPendingActivationsAsync(fromHeight, toHeight).Receive()
+}
+
+// FutureSimulateTakeoverResult is a future promise to deliver the result of a
+// SimulateTakeoverAsync RPC invocation (or an applicable error).
+type FutureSimulateTakeoverResult chan *response
+
+// Receive waits for the response promised by the future and returns the
+// outcome of the simulated claim or support.
+func (r FutureSimulateTakeoverResult) Receive() (*btcjson.SimulateTakeoverResult, error) {
+	res, err := receiveFuture(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal result as a simulatetakeover result object.
+	var result btcjson.SimulateTakeoverResult
+	err = json.Unmarshal(res, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SimulateTakeoverAsync returns an instance of a type that can be used to get
+// the result of the RPC at some future time by invoking the Receive function
+// on the returned instance.
+//
+// See SimulateTakeover for the blocking version and more details.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) SimulateTakeoverAsync(name string, amount int64, claimID *string) FutureSimulateTakeoverResult {
+	cmd := btcjson.NewSimulateTakeoverCmd(name, amount, claimID)
+	return c.sendCmd(cmd)
+}
+
+// SimulateTakeover simulates a claim of the amount on the name made in the
+// next block, or a support of the claim with the hex encoded ID if not nil,
+// and returns when it would activate and take over the name.
+//
+// NOTE: This is a LBRY extension.
+func (c *Client) SimulateTakeover(name string, amount int64, claimID *string) (*btcjson.SimulateTakeoverResult, error) {
+	return c.SimulateTakeoverAsync(name, amount, claimID).Receive()
+}
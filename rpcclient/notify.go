@@ -179,6 +179,14 @@ type NotificationHandlers struct {
 	// made to register for the notification and the function is non-nil.
 	OnTxAcceptedVerbose func(txDetails *btcjson.TxRawResult)
 
+	// OnNameTakeover is invoked when a block connected to the longest
+	// (best) chain changes the claim controlling a name.  It will only be
+	// invoked if a preceding call to NotifyBlocks has been made to register
+	// for the notification and the function is non-nil.
+	//
+	// NOTE: This is a LBRY extension.
+	OnNameTakeover func(hash *chainhash.Hash, takeover *btcjson.NameTakeoverResult)
+
 	// OnBtcdConnected is invoked when a wallet connects or disconnects from
 	// btcd.
 	//
@@ -409,6 +417,23 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnTxAcceptedVerbose(rawTx)
 
+	// OnNameTakeover
+	case btcjson.NameTakeoverNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnNameTakeover == nil {
+			return
+		}
+
+		hash, takeover, err := parseNameTakeoverNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid name takeover "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnNameTakeover(hash, takeover)
+
 	// OnBtcdConnected
 	case btcjson.BtcdConnectedNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -775,6 +800,38 @@ func parseTxAcceptedVerboseNtfnParams(params []json.RawMessage) (*btcjson.TxRawR
 	return &rawTx, nil
 }
 
+// parseNameTakeoverNtfnParams parses out the block hash and the takeover
+// from the parameters of a nametakeover notification.
+func parseNameTakeoverNtfnParams(params []json.RawMessage) (*chainhash.Hash,
+	*btcjson.NameTakeoverResult, error) {
+
+	if len(params) != 2 {
+		return nil, nil, wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var blockHashStr string
+	err := json.Unmarshal(params[0], &blockHashStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Unmarshal second parameter as a name takeover result object.
+	var takeover btcjson.NameTakeoverResult
+	err = json.Unmarshal(params[1], &takeover)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create hash from block hash string.
+	blockHash, err := chainhash.NewHashFromStr(blockHashStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return blockHash, &takeover, nil
+}
+
 // parseBtcdConnectedNtfnParams parses out the connection status of btcd
 // and btcwallet from the parameters of a btcdconnected notification.
 func parseBtcdConnectedNtfnParams(params []json.RawMessage) (bool, error) {
@@ -895,7 +952,8 @@ func (c *Client) NotifyBlocksAsync() FutureNotifyBlocksResult {
 // result in an error if the client is configured to run in HTTP POST mode.
 //
 // The notifications delivered as a result of this call will be via one of
-// OnBlockConnected or OnBlockDisconnected.
+// OnBlockConnected or OnBlockDisconnected, and OnNameTakeover for the
+// takeovers made by the connected blocks.
 //
 // NOTE: This is a btcd extension and requires a websocket connection.
 func (c *Client) NotifyBlocks() error {
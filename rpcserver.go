@@ -33,6 +33,7 @@ import (
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/takeover"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/mining"
@@ -135,6 +136,7 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"debuglevel":             handleDebugLevel,
 	"decoderawtransaction":   handleDecodeRawTransaction,
 	"decodescript":           handleDecodeScript,
+	"dumpclaimtrie":          handleDumpClaimTrie,
 	"estimatefee":            handleEstimateFee,
 	"generate":               handleGenerate,
 	"getaddednodeinfo":       handleGetAddedNodeInfo,
@@ -148,6 +150,12 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getblocktemplate":       handleGetBlockTemplate,
 	"getcfilter":             handleGetCFilter,
 	"getcfilterheader":       handleGetCFilterHeader,
+	"getclaimbyid":           handleGetClaimByID,
+	"getclaimanomalies":      handleGetClaimAnomalies,
+	"getclaimhistory":        handleGetClaimHistory,
+	"getclaimsforname":       handleGetClaimsForName,
+	"getclaimsexpiring":      handleGetClaimsExpiring,
+	"getclaimsinchannel":     handleGetClaimsInChannel,
 	"getconnectioncount":     handleGetConnectionCount,
 	"getcurrentnet":          handleGetCurrentNet,
 	"getdifficulty":          handleGetDifficulty,
@@ -157,19 +165,24 @@ var rpcHandlersBeforeInit = map[string]commandHandler{
 	"getinfo":                handleGetInfo,
 	"getmempoolinfo":         handleGetMempoolInfo,
 	"getmininginfo":          handleGetMiningInfo,
+	"getnamehistory":         handleGetNameHistory,
+	"getnameproof":           handleGetNameProof,
 	"getnettotals":           handleGetNetTotals,
 	"getnetworkhashps":       handleGetNetworkHashPS,
 	"getnodeaddresses":       handleGetNodeAddresses,
 	"getpeerinfo":            handleGetPeerInfo,
+	"getpendingactivations":  handleGetPendingActivations,
 	"getrawmempool":          handleGetRawMempool,
 	"getrawtransaction":      handleGetRawTransaction,
 	"gettxout":               handleGetTxOut,
 	"help":                   handleHelp,
+	"listnames":              handleListNames,
 	"node":                   handleNode,
 	"ping":                   handlePing,
 	"searchrawtransactions":  handleSearchRawTransactions,
 	"sendrawtransaction":     handleSendRawTransaction,
 	"setgenerate":            handleSetGenerate,
+	"simulatetakeover":       handleSimulateTakeover,
 	"signmessagewithprivkey": handleSignMessageWithPrivKey,
 	"stop":                   handleStop,
 	"submitblock":            handleSubmitBlock,
@@ -268,17 +281,28 @@ var rpcLimited = map[string]struct{}{
 	"getblockheader":        {},
 	"getcfilter":            {},
 	"getcfilterheader":      {},
+	"getclaimbyid":          {},
+	"getclaimanomalies":     {},
+	"getclaimhistory":       {},
+	"getclaimsforname":      {},
+	"getclaimsexpiring":     {},
+	"getclaimsinchannel":    {},
 	"getcurrentnet":         {},
 	"getdifficulty":         {},
 	"getheaders":            {},
 	"getinfo":               {},
+	"getnamehistory":        {},
+	"getnameproof":          {},
 	"getnettotals":          {},
 	"getnetworkhashps":      {},
+	"getpendingactivations": {},
 	"getrawmempool":         {},
 	"getrawtransaction":     {},
 	"gettxout":              {},
+	"listnames":             {},
 	"searchrawtransactions": {},
 	"sendrawtransaction":    {},
+	"simulatetakeover":      {},
 	"submitblock":           {},
 	"uptime":                {},
 	"validateaddress":       {},
@@ -693,8 +717,10 @@ func createVinList(mtx *wire.MsgTx) []btcjson.Vin {
 }
 
 // createVoutList returns a slice of JSON objects for the outputs of the passed
-// transaction.
-func createVoutList(mtx *wire.MsgTx, chainParams *chaincfg.Params, filterAddrMap map[string]struct{}) []btcjson.Vout {
+// transaction.  The names of the claims made by the outputs are normalized at
+// the passed height.
+func createVoutList(mtx *wire.MsgTx, chainParams *chaincfg.Params, height int32, filterAddrMap map[string]struct{}) []btcjson.Vout {
+	txHash := mtx.TxHash()
 	voutList := make([]btcjson.Vout, 0, len(mtx.TxOut))
 	for i, v := range mtx.TxOut {
 		// The disassembled string will contain [error] inline if the
@@ -737,6 +763,8 @@ func createVoutList(mtx *wire.MsgTx, chainParams *chaincfg.Params, filterAddrMap
 		vout.ScriptPubKey.Hex = hex.EncodeToString(v.PkScript)
 		vout.ScriptPubKey.Type = scriptClass.String()
 		vout.ScriptPubKey.ReqSigs = int32(reqSigs)
+		vout.Claim = claimScriptResult(v.PkScript,
+			wire.OutPoint{Hash: txHash, Index: uint32(i)}, height)
 
 		voutList = append(voutList, vout)
 	}
@@ -745,7 +773,9 @@ func createVoutList(mtx *wire.MsgTx, chainParams *chaincfg.Params, filterAddrMap
 }
 
 // createTxRawResult converts the passed transaction and associated parameters
-// to a raw transaction JSON object.
+// to a raw transaction JSON object.  The names of the claims made by the
+// transaction are normalized at the height of its block, or at the height
+// following the chain height when it isn't in a block.
 func createTxRawResult(chainParams *chaincfg.Params, mtx *wire.MsgTx,
 	txHash string, blkHeader *wire.BlockHeader, blkHash string,
 	blkHeight int32, chainHeight int32) (*btcjson.TxRawResult, error) {
@@ -755,6 +785,11 @@ func createTxRawResult(chainParams *chaincfg.Params, mtx *wire.MsgTx,
 		return nil, err
 	}
 
+	claimHeight := chainHeight + 1
+	if blkHeader != nil {
+		claimHeight = blkHeight
+	}
+
 	txReply := &btcjson.TxRawResult{
 		Hex:      mtxHex,
 		Txid:     txHash,
@@ -763,7 +798,7 @@ func createTxRawResult(chainParams *chaincfg.Params, mtx *wire.MsgTx,
 		Vsize:    int32(mempool.GetTxVirtualSize(btcutil.NewTx(mtx))),
 		Weight:   int32(blockchain.GetTransactionWeight(btcutil.NewTx(mtx))),
 		Vin:      createVinList(mtx),
-		Vout:     createVoutList(mtx, chainParams, nil),
+		Vout:     createVoutList(mtx, chainParams, claimHeight, nil),
 		Version:  uint32(mtx.Version),
 		LockTime: mtx.LockTime,
 	}
@@ -802,13 +837,16 @@ func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	}
 
 	// Create and return the result.
+	claimHeight := s.cfg.Chain.BestSnapshot().Height + 1
 	txReply := btcjson.TxRawDecodeResult{
 		Txid:     mtx.TxHash().String(),
 		Version:  mtx.Version,
 		Locktime: mtx.LockTime,
 		Vin:      createVinList(&mtx),
-		Vout:     createVoutList(&mtx, s.cfg.ChainParams, nil),
+		Vout:     createVoutList(&mtx, s.cfg.ChainParams, claimHeight, nil),
 	}
+	setSpentClaims(txReply.Vin, &mtx, fetchSpentPkScripts(s, &mtx),
+		claimHeight)
 	return txReply, nil
 }
 
@@ -1149,6 +1187,21 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 
 		blockReply.Tx = txNames
 	} else {
+		// Load the outputs spent by the block to mark the inputs which
+		// spend claims.
+		stxos, err := s.cfg.Chain.FetchSpendJournal(blk)
+		if err != nil {
+			context := "Failed to load spent outputs"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		pkScripts := make(map[wire.OutPoint][]byte, len(stxos))
+		for _, tx := range blk.Transactions()[1:] {
+			for _, txIn := range tx.MsgTx().TxIn {
+				pkScripts[txIn.PreviousOutPoint] = stxos[0].PkScript
+				stxos = stxos[1:]
+			}
+		}
+
 		txns := blk.Transactions()
 		rawTxns := make([]btcjson.TxRawResult, len(txns))
 		for i, tx := range txns {
@@ -1158,6 +1211,8 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 			if err != nil {
 				return nil, err
 			}
+			setSpentClaims(rawTxn.Vin, tx.MsgTx(), pkScripts,
+				blockHeight)
 			rawTxns[i] = *rawTxn
 		}
 		blockReply.RawTx = rawTxns
@@ -1259,6 +1314,9 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		case chaincfg.DeploymentTaproot:
 			forkName = "taproot"
 
+		case chaincfg.DeploymentValidClaimUpdates:
+			forkName = "validclaimupdates"
+
 		default:
 			return nil, &btcjson.RPCError{
 				Code: btcjson.ErrRPCInternal.Code,
@@ -1764,6 +1822,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		Mutable:      gbtMutableFields,
 		NonceRange:   gbtNonceRange,
 		Capabilities: gbtCapabilities,
+		ClaimTrie:    header.ClaimTrie.String(),
 	}
 	// If the generated block template includes transactions with witness
 	// data, then include the witness commitment in the GBT result.
@@ -2675,7 +2734,8 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	// The verbose flag is set, so generate the JSON object and return it.
 	var blkHeader *wire.BlockHeader
 	var blkHashStr string
-	var chainHeight int32
+	chainHeight := s.cfg.Chain.BestSnapshot().Height
+	claimHeight := chainHeight + 1
 	if blkHash != nil {
 		// Fetch the header from chain.
 		header, err := s.cfg.Chain.HeaderByHash(blkHash)
@@ -2686,7 +2746,7 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 
 		blkHeader = &header
 		blkHashStr = blkHash.String()
-		chainHeight = s.cfg.Chain.BestSnapshot().Height
+		claimHeight = blkHeight
 	}
 
 	rawTxn, err := createTxRawResult(s.cfg.ChainParams, mtx, txHash.String(),
@@ -2694,6 +2754,8 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	if err != nil {
 		return nil, err
 	}
+	setSpentClaims(rawTxn.Vin, mtx, fetchSpentPkScripts(s, mtx),
+		claimHeight)
 	return *rawTxn, nil
 }
 
@@ -3293,7 +3355,6 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 		if err != nil {
 			return nil, err
 		}
-		result.Vout = createVoutList(mtx, params, filterAddrMap)
 		result.Version = mtx.Version
 		result.LockTime = mtx.LockTime
 
@@ -3304,6 +3365,7 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 		var blkHeader *wire.BlockHeader
 		var blkHashStr string
 		var blkHeight int32
+		claimHeight := best.Height + 1
 		if blkHash := rtx.blkHash; blkHash != nil {
 			// Fetch the header from chain.
 			header, err := s.cfg.Chain.HeaderByHash(blkHash)
@@ -3324,7 +3386,10 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 			blkHeader = &header
 			blkHashStr = blkHash.String()
 			blkHeight = height
+			claimHeight = height
 		}
+		result.Vout = createVoutList(mtx, params, claimHeight,
+			filterAddrMap)
 
 		// Add the block information to the result if there is any.
 		if blkHeader != nil {
@@ -4648,6 +4713,21 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 		// Notify registered websocket clients of incoming block.
 		s.ntfnMgr.NotifyBlockConnected(block)
 
+		// Notify them of the takeovers made by the block, which are
+		// loaded now as the ClaimTrie may have moved on by the time the
+		// notification is processed.  They're read from a view, as the
+		// chain lock isn't held while the notification is handled, which
+		// is skipped when no client would be notified.
+		if s.cfg.Chain.ClaimTrie() != nil && s.ntfnMgr.NumBlockClients() != 0 {
+			takeovers, err := s.blockTakeovers(block)
+			if err != nil {
+				rpcsLog.Errorf("Failed to load the takeovers of block "+
+					"%v: %v", block.Hash(), err)
+			} else if len(takeovers) != 0 {
+				s.ntfnMgr.NotifyNameTakeovers(block, takeovers)
+			}
+		}
+
 	case blockchain.NTBlockDisconnected:
 		block, ok := notification.Data.(*btcutil.Block)
 		if !ok {
@@ -4660,6 +4740,18 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 	}
 }
 
+// blockTakeovers returns the takeovers of the names made by the passed block,
+// which are read from a view of the ClaimTrie at the chain tip.
+func (s *rpcServer) blockTakeovers(block *btcutil.Block) ([]takeover.Takeover, error) {
+	view, err := s.cfg.Chain.ClaimTrieView()
+	if err != nil {
+		return nil, err
+	}
+	defer view.Close()
+
+	return view.Takeovers(block.Height())
+}
+
 func init() {
 	rpcHandlers = rpcHandlersBeforeInit
 	rand.Seed(time.Now().UnixNano())
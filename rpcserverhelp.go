@@ -80,18 +80,27 @@ var helpDescsEnUS = map[string]string{
 	"vin-scriptSig":   "The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)",
 	"vin-txinwitness": "The witness used to redeem the input encoded as a string array of its items",
 	"vin-sequence":    "The script sequence number",
+	"vin-claim":       "The claim, support or update spent by the input, if any and its origin output is available",
 
 	// ScriptPubKeyResult help.
 	"scriptpubkeyresult-asm":       "Disassembly of the script",
 	"scriptpubkeyresult-hex":       "Hex-encoded bytes of the script",
 	"scriptpubkeyresult-reqSigs":   "The number of required signatures",
-	"scriptpubkeyresult-type":      "The type of the script (e.g. 'pubkeyhash')",
-	"scriptpubkeyresult-addresses": "The bitcoin addresses associated with this script",
+	"scriptpubkeyresult-type":      "The type of the script (e.g. 'pubkeyhash', or 'claimname', 'supportclaim' and 'updateclaim' for claim scripts)",
+	"scriptpubkeyresult-addresses": "The bitcoin addresses associated with this script, which are those of the payee for claim scripts",
 
 	// Vout help.
 	"vout-value":        "The amount in BTC",
 	"vout-n":            "The index of this transaction output",
 	"vout-scriptPubKey": "The public key script used to pay coins as a JSON object",
+	"vout-claim":        "The claim, support or update made by the output, if any",
+
+	// ClaimScriptResult help.
+	"claimscriptresult-operation":      "The operation of the claim script (claimname, supportclaim or updateclaim)",
+	"claimscriptresult-name":           "The name of the claim",
+	"claimscriptresult-normalizedname": "The name as normalized in the ClaimTrie at the height of the block, or the next height for unconfirmed transactions",
+	"claimscriptresult-claimid":        "The ID of the claim, which is derived from the outpoint for claimname",
+	"claimscriptresult-value":          "The hex-encoded value of the claim, if any",
 
 	// TxRawDecodeResult help.
 	"txrawdecoderesult-txid":     "The hash of the transaction",
@@ -107,14 +116,26 @@ var helpDescsEnUS = map[string]string{
 	// DecodeScriptResult help.
 	"decodescriptresult-asm":       "Disassembly of the script",
 	"decodescriptresult-reqSigs":   "The number of required signatures",
-	"decodescriptresult-type":      "The type of the script (e.g. 'pubkeyhash')",
-	"decodescriptresult-addresses": "The bitcoin addresses associated with this script",
+	"decodescriptresult-type":      "The type of the script (e.g. 'pubkeyhash', or 'claimname', 'supportclaim' and 'updateclaim' for claim scripts)",
+	"decodescriptresult-addresses": "The bitcoin addresses associated with this script, which are those of the payee for claim scripts",
 	"decodescriptresult-p2sh":      "The script hash for use in pay-to-script-hash transactions (only present if the provided redeem script is not already a pay-to-script-hash script)",
 
 	// DecodeScriptCmd help.
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
 
+	// DumpClaimTrieCmd help.
+	"dumpclaimtrie--synopsis": "Writes a snapshot of the ClaimTrie at the best block to a file on the server.\n" +
+		"The snapshot can be imported into a fresh node with --clmtsnapshot, or the claimtrie snapshot import command.\n" +
+		"Blocks are not processed while the snapshot is written.",
+	"dumpclaimtrie-filename": "The path of the snapshot; relative paths are resolved against the data directory, and existing files are not overwritten",
+
+	// DumpClaimTrieResult help.
+	"dumpclaimtrieresult-filename":  "The absolute path of the snapshot",
+	"dumpclaimtrieresult-height":    "The height of the block the snapshot was taken at",
+	"dumpclaimtrieresult-blockhash": "The hash of the block the snapshot was taken at",
+	"dumpclaimtrieresult-claimtrie": "The ClaimTrie hash of the snapshot, as committed by the block header",
+
 	// EstimateFeeCmd help.
 	"estimatefee--synopsis": "Estimate the fee per kilobyte in satoshis " +
 		"required for a transaction to be mined before a certain number of " +
@@ -337,6 +358,7 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplateresult-reject-reason":              "Reason the proposal was invalid as-is (only applies to proposal responses)",
 	"getblocktemplateresult-default_witness_commitment": "The witness commitment itself. Will be populated if the block has witness data",
 	"getblocktemplateresult-weightlimit":                "The current limit on the max allowed weight of a block",
+	"getblocktemplateresult-claimtrie":                  "Hex-encoded root hash of the ClaimTrie after connecting the block, which must be set in the block header",
 
 	// GetBlockTemplateCmd help.
 	"getblocktemplate--synopsis": "Returns a JSON object with information necessary to construct a block to mine or accepts a proposal to validate.\n" +
@@ -359,6 +381,248 @@ var helpDescsEnUS = map[string]string{
 	"getcfilterheader-hash":       "The hash of the block",
 	"getcfilterheader--result0":   "The block's gcs filter header",
 
+	// GetClaimByIDCmd help.
+	"getclaimbyid--synopsis": "Returns the state of a claim by its claim ID as of a block, by default the best block.\n" +
+		"This command requires the claim ID index to be enabled (--claimidindex).",
+	"getclaimbyid-claimid":   "The claim ID in hex",
+	"getclaimbyid-blockhash": "The hash of a block in the main chain; mutually exclusive with height",
+	"getclaimbyid-height":    "The height of a block in the main chain; mutually exclusive with blockhash",
+
+	// GetClaimByIDResult help.
+	"getclaimbyidresult-claimid":          "The claim ID in hex",
+	"getclaimbyidresult-name":             "The name of the claim",
+	"getclaimbyidresult-normalizedname":   "The name as stored in the ClaimTrie",
+	"getclaimbyidresult-txid":             "The hash of the transaction holding the latest output of the claim as of the block",
+	"getclaimbyidresult-n":                "The output index of the latest output of the claim as of the block",
+	"getclaimbyidresult-lastchangeheight": "The height of the latest change made to the claim as of the block",
+	"getclaimbyidresult-spent":            "Whether or not the claim has been spent",
+	"getclaimbyidresult-isbest":           "Whether or not the claim is the best claim of its name",
+	"getclaimbyidresult-claim":            "The claim as held by its name at the height of the block, if unspent and not yet expired",
+
+	// GetClaimAnomaliesCmd help.
+	"getclaimanomalies--synopsis": "Returns the anomalies found while applying the claim operations of blocks, ordered by height.\n" +
+		"They are inconsistencies tolerated for compatibility, such as spending a claim which doesn't exist.",
+	"getclaimanomalies-fromheight": "The first height to include",
+	"getclaimanomalies-toheight":   "The last height to include (default: the current height)",
+
+	// ClaimAnomalyResult help.
+	"claimanomalyresult-type":    "The type of the anomaly (conflictingoutpoint, missingspentclaim, missingupdatedclaim, missingspentsupport, invalidupdate)",
+	"claimanomalyresult-height":  "The height at which the anomaly was found",
+	"claimanomalyresult-name":    "The name of the claim, as stored in the ClaimTrie",
+	"claimanomalyresult-claimid": "The claim ID in hex",
+	"claimanomalyresult-txid":    "The hash of the transaction holding the output",
+	"claimanomalyresult-n":       "The output index",
+
+	// GetClaimHistoryCmd help.
+	"getclaimhistory--synopsis": "Returns the changes made to a claim, ordered by height.\n" +
+		"This command requires the claim ID index to be enabled (--claimidindex).",
+	"getclaimhistory-claimid": "The claim ID in hex",
+
+	// ClaimHistoryResult help.
+	"claimhistoryresult-height":    "The height at which the change was made",
+	"claimhistoryresult-operation": "The operation made to the claim (claim, update, spend)",
+	"claimhistoryresult-name":      "The name of the claim",
+	"claimhistoryresult-txid":      "The hash of the transaction holding the output",
+	"claimhistoryresult-n":         "The output index",
+	"claimhistoryresult-amount":    "The amount of the claim, if not a spend",
+	"claimhistoryresult-value":     "The value of the claim in hex, if not a spend",
+
+	// GetNameHistoryCmd help.
+	"getnamehistory--synopsis": "Returns the takeovers of a name, which are the changes of the claim controlling it, ordered by height.\n" +
+		"The takeovers are logged from the height at which the node started journaling them.",
+	"getnamehistory-name": "The name, which is normalized if necessary",
+
+	// NameTakeoverResult help.
+	"nametakeoverresult-height":             "The height at which the name was taken over",
+	"nametakeoverresult-name":               "The name, as stored in the ClaimTrie",
+	"nametakeoverresult-oldclaimid":         "The ID of the claim which controlled the name, if any",
+	"nametakeoverresult-newclaimid":         "The ID of the claim which controls the name, if any",
+	"nametakeoverresult-oldeffectiveamount": "The effective amount of the old claim before the takeover",
+	"nametakeoverresult-neweffectiveamount": "The effective amount of the new claim at the height",
+
+	// GetClaimsForNameCmd help.
+	"getclaimsforname--synopsis": "Returns all claims and supports for a name as of a block, by default the best block.",
+	"getclaimsforname-name":      "The name to look up; it is normalized when the name normalization fork is active",
+	"getclaimsforname-blockhash": "The hash of a block in the main chain; mutually exclusive with height",
+	"getclaimsforname-height":    "The height of a block in the main chain; mutually exclusive with blockhash",
+
+	// GetClaimsForNameResult help.
+	"getclaimsfornameresult-name":                 "The requested name",
+	"getclaimsfornameresult-normalizedname":       "The name as stored in the ClaimTrie",
+	"getclaimsfornameresult-height":               "The height of the ClaimTrie the result was computed at",
+	"getclaimsfornameresult-lasttakeoverheight":   "The height at which the best claim took over the name",
+	"getclaimsfornameresult-bestclaimid":          "The claim ID of the best claim, if any",
+	"getclaimsfornameresult-claims":               "The claims of the name, ordered by effective amount",
+	"getclaimsfornameresult-supportswithoutclaim": "The supports of the name that target no existing claim",
+
+	// ClaimResult help.
+	"claimresult-claimid":          "The claim ID in hex",
+	"claimresult-txid":             "The hash of the transaction holding the claim",
+	"claimresult-n":                "The output index of the claim",
+	"claimresult-amount":           "The amount of the claim",
+	"claimresult-effectiveamount":  "The amount of the claim, plus all of its active supports, if it is active",
+	"claimresult-acceptedheight":   "The height at which the claim was accepted",
+	"claimresult-activeheight":     "The height at which the claim becomes active",
+	"claimresult-expirationheight": "The height at which the claim expires",
+	"claimresult-status":           "The status of the claim (accepted, activated, deactivated)",
+	"claimresult-value":            "The value of the claim in hex",
+	"claimresult-signingchannelid": "The claim ID of the channel which signed the value, if signed",
+	"claimresult-signature":        "The signature of the channel in hex, if signed",
+	"claimresult-signaturevalid":   "Whether the signature is valid against the public key of the channel at the height, if signed and the channel index is enabled (--channelindex)",
+	"claimresult-meta":             "The metadata decoded from the value, if in a supported format",
+	"claimresult-supports":         "The supports of the claim",
+
+	// GetClaimsInChannelCmd help.
+	"getclaimsinchannel--synopsis": "Returns the claims signed by a channel as of a block, by default the best block, whether or not their signature is valid.\n" +
+		"This command requires the channel index to be enabled (--channelindex).",
+	"getclaimsinchannel-channelid": "The claim ID of the channel in hex",
+	"getclaimsinchannel-blockhash": "The hash of a block in the main chain; mutually exclusive with height",
+	"getclaimsinchannel-height":    "The height of a block in the main chain; mutually exclusive with blockhash",
+
+	// GetClaimsInChannelResult help.
+	"getclaimsinchannelresult-channelid": "The claim ID of the channel",
+	"getclaimsinchannelresult-height":    "The height of the ClaimTrie the result was computed at",
+	"getclaimsinchannelresult-claims":    "The claims signed by the channel, ordered by the height they were first signed at",
+
+	// ChannelClaimResult help.
+	"channelclaimresult-name":  "The name of the claim, as stored in the ClaimTrie",
+	"channelclaimresult-claim": "The claim",
+
+	// GetClaimsExpiringCmd help.
+	"getclaimsexpiring--synopsis":  "Returns the claims and supports which expire in a range of heights after the best block, ordered by expiration height.",
+	"getclaimsexpiring-fromheight": "The first height of the range",
+	"getclaimsexpiring-toheight":   "The last height of the range, at most 50000 heights after the first one",
+
+	// GetPendingActivationsCmd help.
+	"getpendingactivations--synopsis": "Returns the claims and supports which are accepted but not active yet, and activate in a range of heights after the best block, ordered by activation height.\n" +
+		"An accepted claim may still activate earlier, when the name it claims changes hands.",
+	"getpendingactivations-fromheight": "The first height of the range",
+	"getpendingactivations-toheight":   "The last height of the range, at most 50000 heights after the first one; by default the maximum activation delay after the best block",
+
+	// ClaimScheduleResult help.
+	"claimscheduleresult-height":     "The height of the ClaimTrie the result was computed at",
+	"claimscheduleresult-fromheight": "The first height of the range",
+	"claimscheduleresult-toheight":   "The last height of the range",
+	"claimscheduleresult-claims":     "The claims and supports, ordered by height, then by name",
+
+	// ScheduledClaimResult help.
+	"scheduledclaimresult-name":    "The name of the claim or support, as stored in the ClaimTrie",
+	"scheduledclaimresult-support": "Whether it is a support rather than a claim",
+	"scheduledclaimresult-claim":   "The claim or support",
+
+	// ListNamesCmd help.
+	"listnames--synopsis": "Returns the names starting with a prefix which have a best claim at the best block, in byte order.\n" +
+		"The names are returned in pages; the next page is requested by passing the nextcursor of the result.",
+	"listnames-prefix": "The prefix of the names; it is normalized when the name normalization fork is active",
+	"listnames-limit":  "The maximum number of names to return, up to 1000",
+	"listnames-cursor": "The nextcursor of the previous page",
+
+	// ListNamesResult help.
+	"listnamesresult-height":     "The height of the ClaimTrie the result was computed at",
+	"listnamesresult-names":      "The names, in byte order",
+	"listnamesresult-nextcursor": "The cursor of the next page, if there are more names",
+
+	// NameResult help.
+	"nameresult-name":               "The name, as stored in the ClaimTrie",
+	"nameresult-bestclaimid":        "The claim ID of the best claim",
+	"nameresult-effectiveamount":    "The effective amount of the best claim",
+	"nameresult-lasttakeoverheight": "The height at which the best claim took over the name",
+
+	// SimulateTakeoverCmd help.
+	"simulatetakeover--synopsis": "Simulates a claim on a name, or a support of one of its claims, made in the next block.\n" +
+		"Returns when the claim, or the supported claim, would activate and take over the name, along with the effective amounts at each update of the name.\n" +
+		"The simulation assumes that no other claims or supports are made meanwhile.",
+	"simulatetakeover-name":    "The name to claim; it is normalized when the name normalization fork is active",
+	"simulatetakeover-amount":  "The amount of the claim or support",
+	"simulatetakeover-claimid": "The claim ID in hex of the claim to support, if simulating a support",
+
+	// SimulateTakeoverResult help.
+	"simulatetakeoverresult-name":             "The requested name",
+	"simulatetakeoverresult-normalizedname":   "The name as stored in the ClaimTrie",
+	"simulatetakeoverresult-height":           "The height of the ClaimTrie the simulation started from",
+	"simulatetakeoverresult-claimid":          "The claim ID of the supported claim, or a placeholder for the simulated claim",
+	"simulatetakeoverresult-activationheight": "The height at which the claim or support would activate",
+	"simulatetakeoverresult-takeoverheight":   "The height at which the claim would become the best claim, if it would before expiring",
+	"simulatetakeoverresult-steps":            "The state of the name at each height it would be updated, until the takeover",
+
+	// SimulationStepResult help.
+	"simulationstepresult-height":          "The height of the update",
+	"simulationstepresult-bestclaimid":     "The claim ID of the best claim, if any",
+	"simulationstepresult-bestamount":      "The effective amount of the best claim",
+	"simulationstepresult-effectiveamount": "The effective amount of the simulated or supported claim",
+
+	// ClaimMetaResult help.
+	"claimmetaresult-type":         "The type of the claim (stream, channel, collection, repost)",
+	"claimmetaresult-legacy":       "Whether the value is in the legacy JSON format",
+	"claimmetaresult-title":        "The title of the content",
+	"claimmetaresult-description":  "The description of the content",
+	"claimmetaresult-thumbnailurl": "The URL of the thumbnail",
+	"claimmetaresult-tags":         "The tags of the content",
+	"claimmetaresult-author":       "The author of the stream",
+	"claimmetaresult-license":      "The license of the stream",
+	"claimmetaresult-licenseurl":   "The URL of the license of the stream",
+	"claimmetaresult-releasetime":  "The release time of the stream, in seconds since the UNIX epoch",
+	"claimmetaresult-streamtype":   "The type of the stream (image, video, audio, software), if specified",
+	"claimmetaresult-sourcename":   "The file name of the stream",
+	"claimmetaresult-sourcesize":   "The size of the stream in bytes",
+	"claimmetaresult-mediatype":    "The media type of the stream",
+	"claimmetaresult-sourcehash":   "The hash of the stream data in hex",
+	"claimmetaresult-sdhash":       "The hash of the stream descriptor in hex",
+	"claimmetaresult-feecurrency":  "The currency of the fee of the stream (LBC, BTC, USD)",
+	"claimmetaresult-feeamount":    "The amount of the fee, in dewies, satoshis or cents",
+	"claimmetaresult-feeaddress":   "The address the fee is paid to",
+	"claimmetaresult-publickey":    "The DER encoded public key of the channel in hex",
+	"claimmetaresult-email":        "The email of the channel",
+	"claimmetaresult-websiteurl":   "The website URL of the channel",
+	"claimmetaresult-coverurl":     "The URL of the cover of the channel",
+	"claimmetaresult-claimids":     "The reposted claim, the claims of the collection, or the claims featured by the channel",
+
+	// SupportResult help.
+	"supportresult-txid":             "The hash of the transaction holding the support",
+	"supportresult-n":                "The output index of the support",
+	"supportresult-claimid":          "The claim ID the support targets",
+	"supportresult-amount":           "The amount of the support",
+	"supportresult-acceptedheight":   "The height at which the support was accepted",
+	"supportresult-activeheight":     "The height at which the support becomes active",
+	"supportresult-expirationheight": "The height at which the support expires",
+	"supportresult-status":           "The status of the support (accepted, activated, deactivated)",
+	"supportresult-value":            "The value of the support in hex, if any",
+
+	// GetNameProofCmd help.
+	"getnameproof--synopsis": "Returns a proof of the best claim of a name, or of its absence, against the ClaimTrie hash of a block.",
+	"getnameproof-name":      "The name to prove; it is normalized when the name normalization fork is active",
+	"getnameproof-blockhash": "The hash of a block in the main chain; mutually exclusive with height",
+	"getnameproof-height":    "The height of a block in the main chain; mutually exclusive with blockhash",
+
+	// GetNameProofResult help.
+	"getnameproofresult-name":              "The requested name",
+	"getnameproofresult-normalizedname":    "The name as stored in the ClaimTrie",
+	"getnameproofresult-blockhash":         "The hash of the block the proof is against",
+	"getnameproofresult-height":            "The height of the block the proof is against",
+	"getnameproofresult-claimtrie":         "The ClaimTrie hash committed by the block header",
+	"getnameproofresult-allclaimsinmerkle": "Whether the hashing scheme of the all-claims-in-merkle fork applies",
+	"getnameproofresult-nodes":             "The vertices from the root to the deepest vertex on the path of the name",
+	"getnameproofresult-pairs":             "The merkle path from the best claim hash to the value hash of the name (all-claims-in-merkle fork only)",
+	"getnameproofresult-bestclaim":         "The best claim of the name, if the proof is an inclusion proof",
+
+	// NameProofNode help.
+	"nameproofnode-children":  "The child links of the vertex, except the one on the path of the name",
+	"nameproofnode-valuehash": "The claims hash of the vertex, if it holds a value",
+
+	// NameProofChild help.
+	"nameproofchild-char": "The character of the child link",
+	"nameproofchild-hash": "The hash of the child vertex",
+
+	// NameProofPair help.
+	"nameproofpair-right": "Whether the sibling is on the right side of the path",
+	"nameproofpair-hash":  "The hash of the sibling",
+
+	// NameProofClaimResult help.
+	"nameproofclaimresult-claimid":            "The claim ID of the best claim",
+	"nameproofclaimresult-txid":               "The hash of the transaction holding the best claim",
+	"nameproofclaimresult-n":                  "The output index of the best claim",
+	"nameproofclaimresult-lasttakeoverheight": "The height at which the best claim took over the name",
+
 	// GetConnectionCountCmd help.
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
@@ -717,6 +981,7 @@ var rpcResultTypes = map[string][]interface{}{
 	"debuglevel":             {(*string)(nil), (*string)(nil)},
 	"decoderawtransaction":   {(*btcjson.TxRawDecodeResult)(nil)},
 	"decodescript":           {(*btcjson.DecodeScriptResult)(nil)},
+	"dumpclaimtrie":          {(*btcjson.DumpClaimTrieResult)(nil)},
 	"estimatefee":            {(*float64)(nil)},
 	"generate":               {(*[]string)(nil)},
 	"getaddednodeinfo":       {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
@@ -730,6 +995,12 @@ var rpcResultTypes = map[string][]interface{}{
 	"getblockchaininfo":      {(*btcjson.GetBlockChainInfoResult)(nil)},
 	"getcfilter":             {(*string)(nil)},
 	"getcfilterheader":       {(*string)(nil)},
+	"getclaimbyid":           {(*btcjson.GetClaimByIDResult)(nil)},
+	"getclaimanomalies":      {(*[]btcjson.ClaimAnomalyResult)(nil)},
+	"getclaimhistory":        {(*[]btcjson.ClaimHistoryResult)(nil)},
+	"getclaimsforname":       {(*btcjson.GetClaimsForNameResult)(nil)},
+	"getclaimsexpiring":      {(*btcjson.ClaimScheduleResult)(nil)},
+	"getclaimsinchannel":     {(*btcjson.GetClaimsInChannelResult)(nil)},
 	"getconnectioncount":     {(*int32)(nil)},
 	"getcurrentnet":          {(*uint32)(nil)},
 	"getdifficulty":          {(*float64)(nil)},
@@ -739,19 +1010,24 @@ var rpcResultTypes = map[string][]interface{}{
 	"getinfo":                {(*btcjson.InfoChainResult)(nil)},
 	"getmempoolinfo":         {(*btcjson.GetMempoolInfoResult)(nil)},
 	"getmininginfo":          {(*btcjson.GetMiningInfoResult)(nil)},
+	"getnamehistory":         {(*[]btcjson.NameTakeoverResult)(nil)},
+	"getnameproof":           {(*btcjson.GetNameProofResult)(nil)},
 	"getnettotals":           {(*btcjson.GetNetTotalsResult)(nil)},
 	"getnetworkhashps":       {(*int64)(nil)},
 	"getnodeaddresses":       {(*[]btcjson.GetNodeAddressesResult)(nil)},
 	"getpeerinfo":            {(*[]btcjson.GetPeerInfoResult)(nil)},
+	"getpendingactivations":  {(*btcjson.ClaimScheduleResult)(nil)},
 	"getrawmempool":          {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
 	"getrawtransaction":      {(*string)(nil), (*btcjson.TxRawResult)(nil)},
 	"gettxout":               {(*btcjson.GetTxOutResult)(nil)},
 	"node":                   nil,
 	"help":                   {(*string)(nil), (*string)(nil)},
+	"listnames":              {(*btcjson.ListNamesResult)(nil)},
 	"ping":                   nil,
 	"searchrawtransactions":  {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
 	"sendrawtransaction":     {(*string)(nil)},
 	"setgenerate":            nil,
+	"simulatetakeover":       {(*btcjson.SimulateTakeoverResult)(nil)},
 	"signmessagewithprivkey": {(*string)(nil)},
 	"stop":                   {(*string)(nil)},
 	"submitblock":            {nil, (*string)(nil)},
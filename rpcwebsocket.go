@@ -24,6 +24,7 @@ import (
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/claimtrie/takeover"
 	"github.com/btcsuite/btcd/database"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
@@ -139,6 +140,10 @@ type wsNotificationManager struct {
 	// Access channel for current number of connected clients.
 	numClients chan int
 
+	// Access channel for current number of clients registered for block
+	// updates.
+	numBlockClients chan int
+
 	// Shutdown handling
 	wg   sync.WaitGroup
 	quit chan struct{}
@@ -226,6 +231,27 @@ func (m *wsNotificationManager) NotifyBlockDisconnected(block *btcutil.Block) {
 	}
 }
 
+// NotifyNameTakeovers passes the takeovers of the names made by a block newly
+// connected to the best chain to the notification manager for block
+// notification processing.
+//
+// NOTE: This is a LBRY extension.
+func (m *wsNotificationManager) NotifyNameTakeovers(block *btcutil.Block, takeovers []takeover.Takeover) {
+	n := &notificationNameTakeovers{
+		block:     block,
+		takeovers: takeovers,
+	}
+
+	// As NotifyNameTakeovers will be called by the block manager
+	// and the RPC server may no longer be running, use a select
+	// statement to unblock enqueuing the notification once the RPC
+	// server has begun shutting down.
+	select {
+	case m.queueNotification <- n:
+	case <-m.quit:
+	}
+}
+
 // NotifyMempoolTx passes a transaction accepted by mempool to the
 // notification manager for transaction notification processing.  If
 // isNew is true, the tx is is a new transaction, rather than one
@@ -447,6 +473,10 @@ func (f *wsClientFilter) removeUnspentOutPoint(op *wire.OutPoint) {
 // Notification types
 type notificationBlockConnected btcutil.Block
 type notificationBlockDisconnected btcutil.Block
+type notificationNameTakeovers struct {
+	block     *btcutil.Block
+	takeovers []takeover.Takeover
+}
 type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *btcutil.Tx
@@ -532,6 +562,12 @@ out:
 						block)
 				}
 
+			case *notificationNameTakeovers:
+				if len(blockNotifications) != 0 {
+					m.notifyNameTakeovers(blockNotifications,
+						n.block, n.takeovers)
+				}
+
 			case *notificationTxAcceptedByMempool:
 				if n.isNew && len(txNotifications) != 0 {
 					m.notifyForNewTx(txNotifications, n.tx)
@@ -592,6 +628,8 @@ out:
 
 		case m.numClients <- len(clients):
 
+		case m.numBlockClients <- len(blockNotifications):
+
 		case <-m.quit:
 			// RPC server shutting down.
 			break out
@@ -613,6 +651,17 @@ func (m *wsNotificationManager) NumClients() (n int) {
 	return
 }
 
+// NumBlockClients returns the number of clients registered for block updates.
+//
+// NOTE: This is a LBRY extension.
+func (m *wsNotificationManager) NumBlockClients() (n int) {
+	select {
+	case n = <-m.numBlockClients:
+	case <-m.quit: // Use default n (0) if server has shut down.
+	}
+	return
+}
+
 // RegisterBlockUpdates requests block update notifications to the passed
 // websocket client.
 func (m *wsNotificationManager) RegisterBlockUpdates(wsc *wsClient) {
@@ -730,6 +779,29 @@ func (*wsNotificationManager) notifyBlockDisconnected(clients map[chan struct{}]
 	}
 }
 
+// notifyNameTakeovers notifies websocket clients that have registered for
+// block updates of the takeovers of the names made by a block connected to
+// the main chain.
+//
+// NOTE: This is a LBRY extension.
+func (*wsNotificationManager) notifyNameTakeovers(clients map[chan struct{}]*wsClient,
+	block *btcutil.Block, takeovers []takeover.Takeover) {
+
+	for _, t := range takeovers {
+		ntfn := btcjson.NewNameTakeoverNtfn(block.Hash().String(),
+			toNameTakeoverResult(t))
+		marshalledJSON, err := btcjson.MarshalCmd(btcjson.RpcVersion1, nil, ntfn)
+		if err != nil {
+			rpcsLog.Errorf("Failed to marshal name takeover "+
+				"notification: %v", err)
+			return
+		}
+		for _, wsc := range clients {
+			wsc.QueueNotification(marshalledJSON)
+		}
+	}
+}
+
 // notifyFilteredBlockConnected notifies websocket clients that have registered for
 // block updates when a block is connected to the main chain.
 func (m *wsNotificationManager) notifyFilteredBlockConnected(clients map[chan struct{}]*wsClient,
@@ -847,11 +919,14 @@ func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClie
 			}
 
 			net := m.server.cfg.ChainParams
+			best := m.server.cfg.Chain.BestSnapshot()
 			rawTx, err := createTxRawResult(net, mtx, txHashStr, nil,
-				"", 0, 0)
+				"", 0, best.Height)
 			if err != nil {
 				return
 			}
+			setSpentClaims(rawTx.Vin, mtx,
+				fetchSpentPkScripts(m.server, mtx), best.Height+1)
 
 			verboseNtfn = btcjson.NewTxAcceptedVerboseNtfn(*rawTx)
 			marshalledJSONVerbose, err = btcjson.MarshalCmd(btcjson.RpcVersion1, nil,
@@ -1218,6 +1293,7 @@ func newWsNotificationManager(server *rpcServer) *wsNotificationManager {
 		queueNotification: make(chan interface{}),
 		notificationMsgs:  make(chan interface{}),
 		numClients:        make(chan int),
+		numBlockClients:   make(chan int),
 		quit:              make(chan struct{}),
 	}
 }
@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"os"
 	"path/filepath"
 	"runtime"
 	"sort"
@@ -2620,6 +2621,32 @@ func setupRPCListeners() ([]net.Listener, error) {
 	return listeners, nil
 }
 
+// importClaimTrieSnapshot bootstraps the ClaimTrie from the snapshot file
+// unless it already has blocks, which allows leaving the option in place
+// across restarts.  The root of the snapshot is verified against the block
+// header at its height once the chain is loaded.
+func importClaimTrieSnapshot(ctCfg claimtrieconfig.Config, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	clmtLog.Infof("Importing ClaimTrie snapshot %s", filename)
+	hdr, err := claimtrie.ImportSnapshot(ctCfg, f)
+	if err == claimtrie.ErrNotEmpty {
+		clmtLog.Infof("ClaimTrie is not empty; ignoring the snapshot")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("import ClaimTrie snapshot: %w", err)
+	}
+	clmtLog.Infof("Imported ClaimTrie snapshot at height %d, root %v",
+		hdr.Height, hdr.Root)
+
+	return nil
+}
+
 // newServer returns a new btcd server configured to listen on addr for the
 // bitcoin network type specified by chainParams.  Use start to begin accepting
 // connections from peers.
@@ -2729,6 +2756,11 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 	claimTrieCfg := claimtrieconfig.DefaultConfig
 	claimTrieCfg.DataDir = filepath.Join(cfg.DataDir, "claim_dbs")
 	claimTrieCfg.Record = cfg.ClaimTrieRecord
+	claimTrieCfg.RamTrie = cfg.ClaimTrieRAM
+	claimTrieCfg.NodeCacheSize = cfg.ClaimTrieCacheSize
+	claimTrieCfg.NodePruneDepth = cfg.ClaimTriePruneDepth
+	claimTrieCfg.ClaimIDIndex = cfg.ClaimIDIndex
+	claimTrieCfg.ChannelIndex = cfg.ChannelIndex
 
 	var ct *claimtrie.ClaimTrie
 
@@ -2737,6 +2769,16 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		// Disable ClaimTrie for development purpose.
 		clmtLog.Infof("ClaimTrie is disabled")
 	default:
+		if cfg.ClaimTrieImpl == "memory" {
+			// Keep the ClaimTrie off disk; it is rebuilt from the blocks on startup.
+			claimTrieCfg.Backend = claimtrieconfig.MemoryBackend
+		}
+		if cfg.ClaimTrieSnapshot != "" {
+			err = importClaimTrieSnapshot(claimTrieCfg, cfg.ClaimTrieSnapshot)
+			if err != nil {
+				return nil, err
+			}
+		}
 		ct, err = claimtrie.New(claimTrieCfg)
 		if err != nil {
 			return nil, err
@@ -2822,6 +2864,9 @@ func newServer(listenAddrs, agentBlacklist, agentWhitelist []string,
 		AddrIndex:          s.addrIndex,
 		FeeEstimator:       s.feeEstimator,
 	}
+	if ct != nil {
+		txC.ClaimExists = s.chain.ClaimExists
+	}
 	s.txMemPool = mempool.New(&txC)
 
 	s.syncManager, err = netsync.New(&netsync.Config{
@@ -54,6 +54,9 @@ func UpdateClaimScript(name string, claimID []byte, value string) ([]byte, error
 
 // DecodeClaimScript ...
 func DecodeClaimScript(script []byte) (*ClaimScript, error) {
+	if len(script) == 0 {
+		return nil, ErrNotClaimScript
+	}
 	op := script[0]
 	if op != OP_CLAIMNAME && op != OP_SUPPORTCLAIM && op != OP_UPDATECLAIM {
 		return nil, ErrNotClaimScript
@@ -114,8 +117,31 @@ func (cs *ClaimScript) Size() int {
 	if cs.pops[0].opcode.value == OP_UPDATECLAIM {
 		ops++
 	}
+	return claimPrefixSize(cs.pops[:ops])
+}
+
+// claimPrefixOps returns the number of opcodes in the claim prefix of the
+// script, which precedes the payee script, or 0 if it isn't a claim script.
+func claimPrefixOps(pops []parsedOpcode) int {
+	switch {
+	case isClaimName(pops):
+		return 5
+	case isSupportClaim(pops):
+		if pops[4].opcode.value == OP_2DROP {
+			// The support carries a value.
+			return 6
+		}
+		return 5
+	case isUpdateClaim(pops):
+		return 6
+	}
+	return 0
+}
+
+// claimPrefixSize returns the size in bytes of the opcodes of a claim prefix.
+func claimPrefixSize(pops []parsedOpcode) int {
 	size := 0
-	for _, op := range cs.pops[:ops] {
+	for _, op := range pops {
 		if op.opcode.length > 0 {
 			size += op.opcode.length
 			continue
@@ -125,6 +151,47 @@ func (cs *ClaimScript) Size() int {
 	return size
 }
 
+// typeOfClaimScript returns the class of a claim script which pays to a
+// standard script, or NonStandardTy otherwise.
+func typeOfClaimScript(pops []parsedOpcode) ScriptClass {
+	ops := claimPrefixOps(pops)
+	if ops == 0 {
+		return NonStandardTy
+	}
+
+	// The size is limited the same way as by DecodeClaimScript.
+	cs := &ClaimScript{op: pops[0].opcode.value, pops: pops}
+	if cs.Size() > MaxClaimScriptSize {
+		return NonStandardTy
+	}
+
+	switch typeOfScript(pops[ops:]) {
+	case NonStandardTy, NullDataTy, ClaimNameTy, SupportClaimTy, UpdateClaimTy:
+		return NonStandardTy
+	}
+
+	switch pops[0].opcode.value {
+	case OP_CLAIMNAME:
+		return ClaimNameTy
+	case OP_SUPPORTCLAIM:
+		return SupportClaimTy
+	default:
+		return UpdateClaimTy
+	}
+}
+
+// claimPayeeScript returns the payee script which follows the claim prefix of
+// a claim script, as classified by typeOfClaimScript.  Unlike
+// StripClaimScriptPrefix, it strips the whole prefix of a support carrying a
+// value.
+func claimPayeeScript(script []byte) []byte {
+	pops, err := parseScript(script)
+	if err != nil {
+		return nil
+	}
+	return script[claimPrefixSize(pops[:claimPrefixOps(pops)]):]
+}
+
 // StripClaimScriptPrefix ...
 func StripClaimScriptPrefix(script []byte) []byte {
 	cs, err := DecodeClaimScript(script)
@@ -3,6 +3,9 @@ package txscript
 import (
 	"testing"
 
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+
 	"github.com/stretchr/testify/require"
 )
 
@@ -71,3 +74,100 @@ func TestCreationParseLoopSupport(t *testing.T) {
 	r.Equal(claimID, script.ClaimID())
 	r.Nil(script.Value())
 }
+
+func TestClaimScriptClass(t *testing.T) {
+
+	r := require.New(t)
+
+	params := &chaincfg.MainNetParams
+	claimID := []byte("12345123451234512345")
+	hash := []byte("67890678906789067890")
+
+	p2pkh, err := btcutil.NewAddressPubKeyHash(hash, params)
+	r.NoError(err)
+	p2sh, err := btcutil.NewAddressScriptHashFromHash(hash, params)
+	r.NoError(err)
+	p2wpkh, err := btcutil.NewAddressWitnessPubKeyHash(hash, params)
+	r.NoError(err)
+
+	prefixes := []struct {
+		class  ScriptClass
+		prefix *ScriptBuilder
+	}{
+		{ClaimNameTy, NewScriptBuilder().AddOp(OP_CLAIMNAME).AddData([]byte("tester")).AddData([]byte("value")).
+			AddOp(OP_2DROP).AddOp(OP_DROP)},
+		{SupportClaimTy, NewScriptBuilder().AddOp(OP_SUPPORTCLAIM).AddData([]byte("tester")).AddData(claimID).
+			AddOp(OP_2DROP).AddOp(OP_DROP)},
+		{SupportClaimTy, NewScriptBuilder().AddOp(OP_SUPPORTCLAIM).AddData([]byte("tester")).AddData(claimID).
+			AddData([]byte("value")).AddOp(OP_2DROP).AddOp(OP_2DROP)},
+		{UpdateClaimTy, NewScriptBuilder().AddOp(OP_UPDATECLAIM).AddData([]byte("tester")).AddData(claimID).
+			AddData([]byte("value")).AddOp(OP_2DROP).AddOp(OP_2DROP)},
+	}
+
+	for _, p := range prefixes {
+		prefix, err := p.prefix.Script()
+		r.NoError(err)
+
+		for _, addr := range []btcutil.Address{p2pkh, p2sh, p2wpkh} {
+			payee, err := PayToAddrScript(addr)
+			r.NoError(err)
+			script := append(append([]byte(nil), prefix...), payee...)
+
+			r.Equal(p.class, GetScriptClass(script))
+			r.Equal(payee, claimPayeeScript(script))
+
+			class, addrs, reqSigs, err := ExtractPkScriptAddrs(script, params)
+			r.NoError(err)
+			r.Equal(p.class, class)
+			r.Equal(1, reqSigs)
+			r.Len(addrs, 1)
+			r.Equal(addr.EncodeAddress(), addrs[0].EncodeAddress())
+		}
+
+		// A claim paying to a nonstandard script is nonstandard.
+		script := append(append([]byte(nil), prefix...), OP_TRUE)
+		r.Equal(NonStandardTy, GetScriptClass(script))
+		class, addrs, _, err := ExtractPkScriptAddrs(script, params)
+		r.NoError(err)
+		r.Equal(NonStandardTy, class)
+		r.Empty(addrs)
+	}
+
+	class, err := NewScriptClass("supportclaim")
+	r.NoError(err)
+	r.Equal(SupportClaimTy, *class)
+	r.Equal("claimname", ClaimNameTy.String())
+	r.Equal("updateclaim", UpdateClaimTy.String())
+}
+
+func TestClaimScriptSizeLimit(t *testing.T) {
+
+	r := require.New(t)
+
+	// The size of a support carrying a value, as limited by consensus, is
+	// that of its first five opcodes, excluding the second OP_2DROP:
+	//  OP_SUPPORTCLAIM <7 byte name> <21 byte claimID> <3+len(value) byte value> OP_2DROP
+	claimID := []byte("12345123451234512345")
+	value := make([]byte, MaxClaimScriptSize-33)
+
+	script, err := SupportClaimScript("tester", claimID, value)
+	r.NoError(err)
+	cs, err := DecodeClaimScript(script)
+	r.NoError(err)
+	r.Equal(MaxClaimScriptSize, cs.Size())
+	r.Equal(MaxClaimScriptSize, ClaimScriptSize(script))
+
+	script, err = SupportClaimScript("tester", claimID, append(value, 0))
+	r.NoError(err)
+	_, err = DecodeClaimScript(script)
+	r.Equal(ErrInvalidClaimScript, err)
+
+	// Claim scripts are classified within the same limit.
+	payee, err := NewScriptBuilder().AddOp(OP_DUP).AddOp(OP_HASH160).AddData(claimID).
+		AddOp(OP_EQUALVERIFY).AddOp(OP_CHECKSIG).Script()
+	r.NoError(err)
+	script, err = NewScriptBuilder().AddOp(OP_SUPPORTCLAIM).AddData([]byte("tester")).AddData(claimID).
+		addData(value).AddOp(OP_2DROP).AddOp(OP_2DROP).Script()
+	r.NoError(err)
+	r.Equal(SupportClaimTy, GetScriptClass(append(script, payee...)))
+}
@@ -59,6 +59,9 @@ const (
 	MultiSigTy                               // Multi signature.
 	NullDataTy                               // Empty data-only (provably prunable).
 	WitnessUnknownTy                         // Witness unknown
+	ClaimNameTy                              // Claim a name, paying to a standard script.
+	SupportClaimTy                           // Support a claim, paying to a standard script.
+	UpdateClaimTy                            // Update a claim, paying to a standard script.
 )
 
 // scriptClassToName houses the human-readable strings which describe each
@@ -73,6 +76,9 @@ var scriptClassToName = []string{
 	MultiSigTy:            "multisig",
 	NullDataTy:            "nulldata",
 	WitnessUnknownTy:      "witness_unknown",
+	ClaimNameTy:           "claimname",
+	SupportClaimTy:        "supportclaim",
+	UpdateClaimTy:         "updateclaim",
 }
 
 // String implements the Stringer interface by returning the name of
@@ -176,7 +182,7 @@ func typeOfScript(pops []parsedOpcode) ScriptClass {
 	} else if isNullData(pops) {
 		return NullDataTy
 	}
-	return NonStandardTy
+	return typeOfClaimScript(pops)
 }
 
 // GetScriptClass returns the class of the script passed.
@@ -538,7 +544,9 @@ func PushedData(script []byte) ([][]byte, error) {
 // ExtractPkScriptAddrs returns the type of script, addresses and required
 // signatures associated with the passed PkScript.  Note that it only works for
 // 'standard' transaction script types.  Any data such as public keys which are
-// invalid are omitted from the results.
+// invalid are omitted from the results.  For claim scripts, the class is that of
+// the claim, while the addresses and required signatures are those of the
+// payee script.
 func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (ScriptClass, []btcutil.Address, int, error) {
 	var addrs []btcutil.Address
 	var requiredSigs int
@@ -630,6 +638,14 @@ func ExtractPkScriptAddrs(pkScript []byte, chainParams *chaincfg.Params) (Script
 			}
 		}
 
+	case ClaimNameTy, SupportClaimTy, UpdateClaimTy:
+		// A claim script is of the form:
+		//  <claim prefix> <payee script>
+		// Therefore the addresses and required signatures are those of
+		// the standard script which follows the prefix.
+		_, addrs, requiredSigs, _ = ExtractPkScriptAddrs(
+			claimPayeeScript(pkScript), chainParams)
+
 	case NullDataTy:
 		// Null data transactions have no addresses or required
 		// signatures.